@@ -0,0 +1,406 @@
+// Package ast describes the syntax tree of an expression, like '2 kg + 3 lbs in g', so that it can be inspected;
+// for example, to build linters, pretty-printers or to highlight the parts of an expression.
+package ast
+
+import (
+	"fmt"
+)
+
+// Span The part of the input that a node or token spans. Positions are in bytes and the first byte is at 1,
+// like the positions of errors.
+type Span struct {
+	Start int `json:"start"` // the position of the first byte
+	Width int `json:"width"` // the number of bytes spanned
+}
+
+// End returns the position just after the last byte spanned.
+func (s Span) End() int {
+	return s.Start + s.Width
+}
+
+// spanning returns the span from the start of one span to the end of another.
+func spanning(first, last Span) Span {
+	return Span{Start: first.Start, Width: last.End() - first.Start}
+}
+
+// Token A token from the input; like '2.5', '+' or 'kg'.
+type Token struct {
+	Value string `json:"value"` // the token as written
+	Span  Span   `json:"span"`  // where the token is in the input
+}
+
+// NewToken Creates a token at a position.
+func NewToken(value string, start int) Token {
+	return Token{Value: value, Span: Span{Start: start, Width: len(value)}}
+}
+
+// Kind The kind of a node.
+type Kind int
+
+const (
+	// ValueKind A value like '2 kg'.
+	ValueKind Kind = iota
+	// IntervalKind An interval like '3..5 kg'.
+	IntervalKind
+	// AdditionKind An addition like '2 kg + 3 kg'.
+	AdditionKind
+	// SubtractionKind A subtraction like '2 kg - 3 kg'.
+	SubtractionKind
+	// UnitConversionKind A conversion to other units like '2 kg in lbs'.
+	UnitConversionKind
+	// RadixConversionKind A conversion to a radix like '255 bytes in hex'.
+	RadixConversionKind
+	// MultiplicationKind A multiplication by a number like '2 kg * 3', or by an amount like '2 m * 3 m'.
+	MultiplicationKind
+	// DivisionKind A division by a number like '2 kg / 3', or by an amount like '6 m^2 / 3 m'.
+	DivisionKind
+	// GroupKind A group in parentheses like '(2 ft + 3 in)'.
+	GroupKind
+	// VariableKind A variable like 'rent'.
+	VariableKind
+	// AssignmentKind An assignment to a variable like 'rent = 1200 USD'.
+	AssignmentKind
+)
+
+func (k Kind) String() string {
+	switch k {
+	case ValueKind:
+		return "value"
+	case IntervalKind:
+		return "interval"
+	case AdditionKind:
+		return "addition"
+	case SubtractionKind:
+		return "subtraction"
+	case UnitConversionKind:
+		return "unit conversion"
+	case RadixConversionKind:
+		return "radix conversion"
+	case MultiplicationKind:
+		return "multiplication"
+	case DivisionKind:
+		return "division"
+	case GroupKind:
+		return "group"
+	case VariableKind:
+		return "variable"
+	case AssignmentKind:
+		return "assignment"
+	default:
+		return "unknown"
+	}
+}
+
+// Node A node of the syntax tree.
+type Node interface {
+	// Kind returns the kind of node.
+	Kind() Kind
+	// Span returns the part of the input spanned by the node.
+	Span() Span
+	// Children returns the operands of the node, in order.
+	Children() []Node
+	// Accept calls the method of the visitor for this kind of node.
+	Accept(v Visitor)
+	// String returns the node as an expression.
+	String() string
+}
+
+// Value A value like '2 kg'.
+type Value struct {
+	Number      float64 // the number
+	NumberToken Token   // the number as written; like '2,000'
+	Units       Token   // the units of measure; empty for a number displayed in a radix like '255 in hex'
+}
+
+// Kind returns the kind of node.
+func (v *Value) Kind() Kind {
+	return ValueKind
+}
+
+// Span returns the part of the input spanned by the value.
+func (v *Value) Span() Span {
+	if v.Units.Value == "" {
+		return v.NumberToken.Span
+	}
+	return spanning(v.NumberToken.Span, v.Units.Span)
+}
+
+// Children returns nothing; a value has no operands.
+func (v *Value) Children() []Node {
+	return nil
+}
+
+// Accept calls the visitor for a value.
+func (v *Value) Accept(visitor Visitor) {
+	visitor.VisitValue(v)
+}
+
+func (v *Value) String() string {
+	if v.Units.Value == "" {
+		return v.NumberToken.Value
+	}
+	return fmt.Sprintf("%s %s", v.NumberToken.Value, v.Units.Value)
+}
+
+// Interval An interval like '3..5 kg'.
+type Interval struct {
+	Lower      float64 // the lower bound
+	LowerToken Token   // the lower bound as written
+	Upper      float64 // the upper bound
+	UpperToken Token   // the upper bound as written
+	Units      Token   // the units of measure; empty for numbers displayed in a radix like '1..16 in hex'
+}
+
+// Kind returns the kind of node.
+func (i *Interval) Kind() Kind {
+	return IntervalKind
+}
+
+// Span returns the part of the input spanned by the interval.
+func (i *Interval) Span() Span {
+	if i.Units.Value == "" {
+		return spanning(i.LowerToken.Span, i.UpperToken.Span)
+	}
+	return spanning(i.LowerToken.Span, i.Units.Span)
+}
+
+// Children returns nothing; an interval has no operands.
+func (i *Interval) Children() []Node {
+	return nil
+}
+
+// Accept calls the visitor for an interval.
+func (i *Interval) Accept(visitor Visitor) {
+	visitor.VisitInterval(i)
+}
+
+func (i *Interval) String() string {
+	if i.Units.Value == "" {
+		return fmt.Sprintf("%s..%s", i.LowerToken.Value, i.UpperToken.Value)
+	}
+	return fmt.Sprintf("%s..%s %s", i.LowerToken.Value, i.UpperToken.Value, i.Units.Value)
+}
+
+// Operator An arithmetic operator.
+type Operator int
+
+const (
+	// Add Addition as in '+'.
+	Add Operator = iota
+	// Subtract Subtraction as in '-'.
+	Subtract
+	// Multiply Multiplication as in '*'.
+	Multiply
+	// Divide Division as in '/'.
+	Divide
+)
+
+// Operation An operation on two operands like '2 kg + 3 lbs' or '2 m * 3 m'.
+type Operation struct {
+	Operator      Operator // the operator
+	OperatorToken Token    // the operator as written
+	Left          Node     // the left operand
+	Right         Node     // the right operand
+}
+
+// Kind returns the kind of node; an addition, subtraction, multiplication or division.
+func (o *Operation) Kind() Kind {
+	switch o.Operator {
+	case Subtract:
+		return SubtractionKind
+	case Multiply:
+		return MultiplicationKind
+	case Divide:
+		return DivisionKind
+	default:
+		return AdditionKind
+	}
+}
+
+// Span returns the part of the input spanned by the operation.
+func (o *Operation) Span() Span {
+	return spanning(o.Left.Span(), o.Right.Span())
+}
+
+// Children returns the left and right operands.
+func (o *Operation) Children() []Node {
+	return []Node{o.Left, o.Right}
+}
+
+// Accept calls the visitor for an operation.
+func (o *Operation) Accept(visitor Visitor) {
+	visitor.VisitOperation(o)
+}
+
+func (o *Operation) String() string {
+	return fmt.Sprintf("%s %s %s", o.Left, o.OperatorToken.Value, o.Right)
+}
+
+// Scaling A multiplication or division of an expression by a number like 'rent / 3', or '2 * rent'.
+type Scaling struct {
+	Expr          Node     // the expression that is scaled
+	Operator      Operator // the operator; either multiply or divide
+	OperatorToken Token    // the operator as written
+	Factor        float64  // the number to multiply or divide by
+	FactorToken   Token    // the number as written
+	FactorFirst   bool     // true if the number comes before the expression; like '2 * rent'
+}
+
+// Kind returns the kind of node; either a multiplication or division.
+func (s *Scaling) Kind() Kind {
+	if s.Operator == Divide {
+		return DivisionKind
+	}
+	return MultiplicationKind
+}
+
+// Span returns the part of the input spanned by the scaling.
+func (s *Scaling) Span() Span {
+	if s.FactorFirst {
+		return spanning(s.FactorToken.Span, s.Expr.Span())
+	}
+	return spanning(s.Expr.Span(), s.FactorToken.Span)
+}
+
+// Children returns the expression that is scaled.
+func (s *Scaling) Children() []Node {
+	return []Node{s.Expr}
+}
+
+// Accept calls the visitor for a scaling.
+func (s *Scaling) Accept(visitor Visitor) {
+	visitor.VisitScaling(s)
+}
+
+func (s *Scaling) String() string {
+	if s.FactorFirst {
+		return fmt.Sprintf("%s %s %s", s.FactorToken.Value, s.OperatorToken.Value, s.Expr)
+	}
+	return fmt.Sprintf("%s %s %s", s.Expr, s.OperatorToken.Value, s.FactorToken.Value)
+}
+
+// Group A group in parentheses like '(2 ft + 3 in)'; it is evaluated before the operations around it.
+type Group struct {
+	Open  Token // the opening parenthesis
+	Expr  Node  // the expression in the group
+	Close Token // the closing parenthesis
+}
+
+// Kind returns the kind of node.
+func (g *Group) Kind() Kind {
+	return GroupKind
+}
+
+// Span returns the part of the input spanned by the group, including its parentheses.
+func (g *Group) Span() Span {
+	return spanning(g.Open.Span, g.Close.Span)
+}
+
+// Children returns the expression in the group.
+func (g *Group) Children() []Node {
+	return []Node{g.Expr}
+}
+
+// Accept calls the visitor for a group.
+func (g *Group) Accept(visitor Visitor) {
+	visitor.VisitGroup(g)
+}
+
+func (g *Group) String() string {
+	return fmt.Sprintf("%s%s%s", g.Open.Value, g.Expr, g.Close.Value)
+}
+
+// Conversion A conversion to other units like '2 kg in lbs', or to a radix like '255 bytes in hex'.
+type Conversion struct {
+	Expr    Node  // the expression that is converted
+	Keyword Token // the keyword or symbol of the conversion; like 'in' or '->'
+	Target  Token // the units or radix to convert to
+	Radix   bool  // true if the target is a radix like 'hex'
+}
+
+// Kind returns the kind of node; either a unit or radix conversion.
+func (c *Conversion) Kind() Kind {
+	if c.Radix {
+		return RadixConversionKind
+	}
+	return UnitConversionKind
+}
+
+// Span returns the part of the input spanned by the conversion.
+func (c *Conversion) Span() Span {
+	return spanning(c.Expr.Span(), c.Target.Span)
+}
+
+// Children returns the expression that is converted.
+func (c *Conversion) Children() []Node {
+	return []Node{c.Expr}
+}
+
+// Accept calls the visitor for a conversion.
+func (c *Conversion) Accept(visitor Visitor) {
+	visitor.VisitConversion(c)
+}
+
+func (c *Conversion) String() string {
+	return fmt.Sprintf("%s %s %s", c.Expr, c.Keyword.Value, c.Target.Value)
+}
+
+// Variable A variable like 'rent'; see Assignment.
+type Variable struct {
+	Name Token // the name of the variable
+}
+
+// Kind returns the kind of node.
+func (v *Variable) Kind() Kind {
+	return VariableKind
+}
+
+// Span returns the part of the input spanned by the variable.
+func (v *Variable) Span() Span {
+	return v.Name.Span
+}
+
+// Children returns nothing; a variable has no operands.
+func (v *Variable) Children() []Node {
+	return nil
+}
+
+// Accept calls the visitor for a variable.
+func (v *Variable) Accept(visitor Visitor) {
+	visitor.VisitVariable(v)
+}
+
+func (v *Variable) String() string {
+	return v.Name.Value
+}
+
+// Assignment An assignment of the value of an expression to a variable like 'rent = 1200 USD'.
+type Assignment struct {
+	Name   Token // the name of the variable
+	Assign Token // the symbol of the assignment; '='
+	Expr   Node  // the expression whose value is assigned
+}
+
+// Kind returns the kind of node.
+func (a *Assignment) Kind() Kind {
+	return AssignmentKind
+}
+
+// Span returns the part of the input spanned by the assignment.
+func (a *Assignment) Span() Span {
+	return spanning(a.Name.Span, a.Expr.Span())
+}
+
+// Children returns the expression whose value is assigned.
+func (a *Assignment) Children() []Node {
+	return []Node{a.Expr}
+}
+
+// Accept calls the visitor for an assignment.
+func (a *Assignment) Accept(visitor Visitor) {
+	visitor.VisitAssignment(a)
+}
+
+func (a *Assignment) String() string {
+	return fmt.Sprintf("%s %s %s", a.Name.Value, a.Assign.Value, a.Expr)
+}
@@ -0,0 +1,175 @@
+package ast_test
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var kinds = map[string]ast.Kind{
+	"2 kg":              ast.ValueKind,
+	"3..5 kg":           ast.IntervalKind,
+	"2 kg + 3 lbs":      ast.AdditionKind,
+	"2 kg - 3 lbs":      ast.SubtractionKind,
+	"2 kg in lbs":       ast.UnitConversionKind,
+	"2 kg -> lbs":       ast.UnitConversionKind,
+	"255 bytes in hex":  ast.RadixConversionKind,
+	"255 in hex":        ast.RadixConversionKind,
+	"1..16 in hex":      ast.RadixConversionKind,
+	"2 kg + 3 kg in lb": ast.UnitConversionKind,
+	"2 kg * 3":          ast.MultiplicationKind,
+	"2 kg / 3":          ast.DivisionKind,
+	"2 kg / 3 in lb":    ast.UnitConversionKind,
+	"(2 kg + 3 lbs)":    ast.GroupKind,
+	"2 m * 3 m":         ast.MultiplicationKind,
+	"6 m^2 / (3 m)":     ast.DivisionKind,
+}
+
+func TestKind(t *testing.T) {
+	for input, expected := range kinds {
+		t.Run(input, func(t *testing.T) {
+			node, err := calc.Parse(input)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, node.Kind())
+			assert.Equal(t, input, node.String())
+			assert.Equal(t, ast.Span{Start: 1, Width: len(input)}, node.Span())
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	node, err := calc.Parse("2,000.5 kg")
+	assert.Nil(t, err)
+	expected := &ast.Value{
+		Number:      2000.5,
+		NumberToken: ast.NewToken("2,000.5", 1),
+		Units:       ast.NewToken("kg", 9),
+	}
+	assert.Equal(t, expected, node)
+	assert.Empty(t, node.Children())
+}
+
+func TestInterval(t *testing.T) {
+	node, err := calc.Parse("5..3 kg")
+	assert.Nil(t, err)
+	expected := &ast.Interval{
+		Lower:      5,
+		LowerToken: ast.NewToken("5", 1),
+		Upper:      3,
+		UpperToken: ast.NewToken("3", 4),
+		Units:      ast.NewToken("kg", 6),
+	}
+	assert.Equal(t, expected, node)
+}
+
+func TestOperation(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 lbs - 4 oz")
+	assert.Nil(t, err)
+	subtraction := node.(*ast.Operation)
+	assert.Equal(t, ast.Subtract, subtraction.Operator)
+	assert.Equal(t, ast.NewToken("-", 14), subtraction.OperatorToken)
+	assert.Equal(t, "4 oz", subtraction.Right.String())
+
+	addition := subtraction.Left.(*ast.Operation)
+	assert.Equal(t, ast.Add, addition.Operator)
+	assert.Equal(t, ast.Span{Start: 1, Width: 12}, addition.Span())
+	assert.Equal(t, []ast.Node{addition.Left, addition.Right}, addition.Children())
+}
+
+func TestScaling(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 lbs * 2 / 4")
+	assert.Nil(t, err)
+	addition := node.(*ast.Operation)
+	division := addition.Right.(*ast.Scaling)
+	assert.Equal(t, ast.Divide, division.Operator)
+	assert.Equal(t, ast.NewToken("/", 18), division.OperatorToken)
+	assert.Equal(t, 4.0, division.Factor)
+	assert.Equal(t, ast.Span{Start: 8, Width: 13}, division.Span())
+	assert.Equal(t, "3 lbs * 2 / 4", division.String())
+
+	multiplication := division.Expr.(*ast.Scaling)
+	assert.Equal(t, ast.Multiply, multiplication.Operator)
+	assert.Equal(t, ast.NewToken("2", 16), multiplication.FactorToken)
+	assert.Equal(t, []ast.Node{multiplication.Expr}, multiplication.Children())
+}
+
+func TestScalingFactorFirst(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 * 4 lbs")
+	assert.Nil(t, err)
+	addition := node.(*ast.Operation)
+	scaling := addition.Right.(*ast.Scaling)
+	assert.True(t, scaling.FactorFirst)
+	assert.Equal(t, 3.0, scaling.Factor)
+	assert.Equal(t, ast.Span{Start: 8, Width: 9}, scaling.Span())
+	assert.Equal(t, "3 * 4 lbs", scaling.String())
+}
+
+func TestGroup(t *testing.T) {
+	node, err := calc.Parse("1 m + (2 ft + 3 in) * 2")
+	assert.Nil(t, err)
+	addition := node.(*ast.Operation)
+	scaling := addition.Right.(*ast.Scaling)
+	group := scaling.Expr.(*ast.Group)
+	assert.Equal(t, ast.NewToken("(", 7), group.Open)
+	assert.Equal(t, ast.NewToken(")", 19), group.Close)
+	assert.Equal(t, ast.Span{Start: 7, Width: 13}, group.Span())
+	assert.Equal(t, "2 ft + 3 in", group.Expr.String())
+	assert.Equal(t, []ast.Node{group.Expr}, group.Children())
+}
+
+func TestProduct(t *testing.T) {
+	node, err := calc.Parse("(10..12 m) * (3..4 m) in ft^2")
+	assert.Nil(t, err)
+	conversion := node.(*ast.Conversion)
+	product := conversion.Expr.(*ast.Operation)
+	assert.Equal(t, ast.Multiply, product.Operator)
+	assert.Equal(t, ast.MultiplicationKind, product.Kind())
+	assert.Equal(t, ast.GroupKind, product.Left.Kind())
+	assert.Equal(t, ast.GroupKind, product.Right.Kind())
+	assert.Equal(t, ast.NewToken("ft^2", 26), conversion.Target)
+}
+
+func TestConversion(t *testing.T) {
+	node, err := calc.Parse("2 kg IN lbs")
+	assert.Nil(t, err)
+	conversion := node.(*ast.Conversion)
+	assert.Equal(t, ast.NewToken("IN", 6), conversion.Keyword)
+	assert.Equal(t, ast.NewToken("lbs", 9), conversion.Target)
+	assert.False(t, conversion.Radix)
+	assert.Equal(t, "2 kg", conversion.Expr.String())
+}
+
+func TestParseError(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 punds")
+	assert.Nil(t, node)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "'punds' is not a known measurement unit", err.Error())
+	}
+}
+
+func TestKindString(t *testing.T) {
+	assert.Equal(t, "unit conversion", ast.UnitConversionKind.String())
+	assert.Equal(t, "unknown", ast.Kind(99).String())
+}
+
+func TestVariable(t *testing.T) {
+	vars := map[string]types.Amount{"rent": {Value: 1200, Units: types.Units.Token("kg")}}
+	node, err := calc.Parse("total = rent in g", calc.WithVariables(vars))
+	assert.Nil(t, err)
+	expected := &ast.Assignment{
+		Name:   ast.NewToken("total", 1),
+		Assign: ast.NewToken("=", 7),
+		Expr: &ast.Conversion{
+			Expr:    &ast.Variable{Name: ast.NewToken("rent", 9)},
+			Keyword: ast.NewToken("in", 14),
+			Target:  ast.NewToken("g", 17),
+		},
+	}
+	assert.Equal(t, expected, node)
+	assert.Equal(t, ast.AssignmentKind, node.Kind())
+	assert.Equal(t, ast.Span{Start: 1, Width: 17}, node.Span())
+	assert.Equal(t, "total = rent in g", node.String())
+	assert.Equal(t, ast.VariableKind, node.Children()[0].Children()[0].Kind())
+}
@@ -0,0 +1,23 @@
+package ast
+
+// Visitor Visits each kind of node; see Node.Accept. A visitor decides whether to visit the children of a node.
+type Visitor interface {
+	VisitValue(value *Value)
+	VisitInterval(interval *Interval)
+	VisitOperation(operation *Operation)
+	VisitScaling(scaling *Scaling)
+	VisitConversion(conversion *Conversion)
+	VisitGroup(group *Group)
+	VisitVariable(variable *Variable)
+	VisitAssignment(assignment *Assignment)
+}
+
+// Inspect visits a node and then, depth-first, each of its children while the function returns true.
+func Inspect(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	for _, child := range node.Children() {
+		Inspect(child, fn)
+	}
+}
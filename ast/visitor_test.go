@@ -0,0 +1,78 @@
+package ast_test
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// unitsVisitor collects the units of an expression, in order
+type unitsVisitor struct {
+	units []string
+}
+
+func (v *unitsVisitor) VisitValue(value *ast.Value) {
+	v.units = append(v.units, value.Units.Value)
+}
+
+func (v *unitsVisitor) VisitInterval(interval *ast.Interval) {
+	v.units = append(v.units, interval.Units.Value)
+}
+
+func (v *unitsVisitor) VisitOperation(operation *ast.Operation) {
+	operation.Left.Accept(v)
+	operation.Right.Accept(v)
+}
+
+func (v *unitsVisitor) VisitScaling(scaling *ast.Scaling) {
+	scaling.Expr.Accept(v)
+}
+
+func (v *unitsVisitor) VisitConversion(conversion *ast.Conversion) {
+	conversion.Expr.Accept(v)
+	v.units = append(v.units, conversion.Target.Value)
+}
+
+func (v *unitsVisitor) VisitGroup(group *ast.Group) {
+	group.Expr.Accept(v)
+}
+
+func (v *unitsVisitor) VisitVariable(variable *ast.Variable) {
+	// a variable has no units of its own
+}
+
+func (v *unitsVisitor) VisitAssignment(assignment *ast.Assignment) {
+	assignment.Expr.Accept(v)
+}
+
+func TestVisitor(t *testing.T) {
+	node, err := calc.Parse("2 kg + (3..4 lbs * 2 - 5 oz) in g")
+	assert.Nil(t, err)
+	visitor := &unitsVisitor{}
+	node.Accept(visitor)
+	assert.Equal(t, []string{"kg", "lbs", "oz", "g"}, visitor.units)
+}
+
+func TestInspect(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 lbs in g")
+	assert.Nil(t, err)
+	var kinds []ast.Kind
+	ast.Inspect(node, func(n ast.Node) bool {
+		kinds = append(kinds, n.Kind())
+		return true
+	})
+	expected := []ast.Kind{ast.UnitConversionKind, ast.AdditionKind, ast.ValueKind, ast.ValueKind}
+	assert.Equal(t, expected, kinds)
+}
+
+func TestInspectSkipChildren(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 lbs in g")
+	assert.Nil(t, err)
+	count := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		count++
+		return n.Kind() != ast.AdditionKind
+	})
+	assert.Equal(t, 2, count)
+}
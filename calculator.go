@@ -1,31 +1,138 @@
 package calc
 
 import (
+	"context"
 	"fmt"
-	"github.com/nickwallen/quick-calc/internal/io"
+	"github.com/nickwallen/quick-calc/ast"
 	"github.com/nickwallen/quick-calc/internal/parser"
 	"github.com/nickwallen/quick-calc/internal/tokenizer"
 	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/nickwallen/quick-calc/trace"
+	"math"
+	"math/big"
+	"strings"
 )
 
 // Calculate evaluates an input expression and returns the value as a string.
-func Calculate(input string) (string, types.InputError) {
-	var result string
-	amt, err := CalculateAmount(input)
+func Calculate(input string, opts ...Option) (string, InputError) {
+	return CalculateContext(context.Background(), input, opts...)
+}
+
+// CalculateContext evaluates an input expression, unless the context is done, and returns the value as a string.
+func CalculateContext(ctx context.Context, input string, opts ...Option) (string, InputError) {
+	amt, err := CalculateAmountContext(ctx, input, opts...)
 	if err != nil {
-		return result, err
+		return "", err
+	}
+	return Format(amt, opts...), nil
+}
+
+// Format formats an amount as a string in the locale and precision of the options; an interval is
+// formatted as a range like '3.00..5.00 kg'. An amount without units, like the ratio of two lengths, is
+// formatted as just a number.
+func Format(amt Amount, opts ...Option) string {
+	o := newOptions(opts...)
+	number := formatNumber(amt.Value, amt.Radix, o)
+	if amt.Interval {
+		number = fmt.Sprintf("%s..%s", number, formatNumber(amt.Upper, amt.Radix, o))
+	}
+	if amt.Units.Value == "" {
+		return number
+	}
+	return fmt.Sprintf("%s %s", number, amt.Units.Value)
+}
+
+// the prefixes of numbers displayed in a radix other than decimal
+var radixPrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// formatNumber formats a number in the given radix; like 0xFF for hexadecimal. Only whole numbers can be
+// formatted in a radix other than decimal, so any other number is formatted as a decimal.
+func formatNumber(value float64, radix int, o *options) string {
+	prefix, ok := radixPrefixes[radix]
+	if !ok || value != math.Trunc(value) || math.IsInf(value, 0) {
+		return o.locale.FormatNumber(value, o.precision)
 	}
-	result = fmt.Sprintf("%.2f %s", amt.Value, amt.Units.Value)
-	return result, nil
+	// a whole number may be too large for an int64; like 1e19 bytes
+	number, _ := big.NewFloat(value).Int(nil)
+	sign := ""
+	if number.Sign() < 0 {
+		sign = "-"
+		number.Neg(number)
+	}
+	return sign + prefix + strings.ToUpper(number.Text(radix))
 }
 
 // CalculateAmount evaluates an input expression and returns an Amount object.
-func CalculateAmount(input string) (amt types.Amount, err types.InputError) {
-	tokens := io.NewTokenChannel(input)
-	go tokenizer.Tokenize(input, tokens)
-	expr, err := parser.Parse(tokens)
+func CalculateAmount(input string, opts ...Option) (amt Amount, err InputError) {
+	return CalculateAmountContext(context.Background(), input, opts...)
+}
+
+// CalculateAmountContext evaluates an input expression, unless the context is done, and returns an Amount object.
+func CalculateAmountContext(ctx context.Context, input string, opts ...Option) (amt Amount, err InputError) {
+	_, amt, err = CalculateAssignmentContext(ctx, input, opts...)
+	return amt, err
+}
+
+// CalculateAssignment evaluates an input expression that may assign its value to a variable, like 'rent = 1200 USD',
+// and returns the name of the variable, if any, along with the value. The variable can then be used in other
+// expressions; see WithVariables.
+func CalculateAssignment(input string, opts ...Option) (name string, amt Amount, err InputError) {
+	return CalculateAssignmentContext(context.Background(), input, opts...)
+}
+
+// CalculateAssignmentContext evaluates an input expression that may assign its value to a variable, unless the
+// context is done.
+func CalculateAssignmentContext(ctx context.Context, input string, opts ...Option) (name string, amt Amount, err InputError) {
+	o := newOptions(opts...)
+	if err := o.checkLength(input); err != nil {
+		return name, amt, err
+	}
+	name, expr, err := parser.ParseAssignment(o.lexer(ctx, input), o.parserOptions(ctx)...)
 	if err != nil {
-		return amt, err
+		return name, amt, err
+	}
+	amt, err = expr.EvalContext(ctx, input)
+	return name, amt, err
+}
+
+// Explain evaluates an input expression and returns the value as a string along with each step taken to evaluate
+// it; like each conversion of units. The steps taken before an error are returned along with the error.
+func Explain(input string, opts ...Option) (string, []trace.Step, InputError) {
+	return ExplainContext(context.Background(), input, opts...)
+}
+
+// ExplainContext evaluates and explains an input expression, unless the context is done.
+func ExplainContext(ctx context.Context, input string, opts ...Option) (string, []trace.Step, InputError) {
+	recorder := &trace.Recorder{}
+	result, err := CalculateContext(trace.NewContext(ctx, recorder), input, opts...)
+	return result, recorder.Steps(), err
+}
+
+// Parse parses an input expression and returns its syntax tree, without evaluating it.
+func Parse(input string, opts ...Option) (ast.Node, InputError) {
+	return ParseContext(context.Background(), input, opts...)
+}
+
+// ParseContext parses an input expression, unless the context is done, and returns its syntax tree.
+func ParseContext(ctx context.Context, input string, opts ...Option) (ast.Node, InputError) {
+	o := newOptions(opts...)
+	if err := o.checkLength(input); err != nil {
+		return nil, err
+	}
+	return parser.ParseTree(o.lexer(ctx, input), o.parserOptions(ctx)...)
+}
+
+// Diagnose finds every problem with an input expression, rather than just the first, so that each can be shown
+// at once; for example, underlined in an editor. Nothing is returned when the input is valid.
+func Diagnose(input string, opts ...Option) []InputError {
+	return DiagnoseContext(context.Background(), input, opts...)
+}
+
+// DiagnoseContext finds every problem with an input expression, unless the context is done.
+func DiagnoseContext(ctx context.Context, input string, opts ...Option) []InputError {
+	o := newOptions(opts...)
+	if err := o.checkLength(input); err != nil {
+		return []types.InputError{err}
 	}
-	return expr.Eval(input)
+	return parser.Diagnose(o.lexer(ctx, input, tokenizer.WithRecovery()), o.parserOptions(ctx)...)
 }
@@ -1,10 +1,16 @@
 package calc_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/types"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
 	"testing"
+	"time"
 )
 
 var expressions = map[string]string{
@@ -29,13 +35,129 @@ var expressions = map[string]string{
 	"2 oz + 3 oz + 4 oz - 5 oz":                   "4.00 oz",
 	"2 oz + 3 oz + 4 oz - 5 oz in pounds":         "0.25 pounds",
 	"12 oz + 1.2 lbs + 24 oz - 0.8 lbs in pounds": "2.65 pounds",
+	"3..5 kg":                                     "3.00..5.00 kg",
+	"3..5 kg + 1..2 kg":                           "4.00..7.00 kg",
+	"3..5 kg - 1..2 kg":                           "1.00..4.00 kg",
+	"1..2 stones + 2 pounds in pounds":            "16.00..30.00 pounds",
+	"0x1F bytes + 0b1 bytes":                      "32.00 bytes",
+	"0o17 KB in KB":                               "15.00 KB",
+	"2,200,123 g in kg":                           "2200.12 kg",
+	"2 pounds to ounces":                          "32.00 ounces",
+	"2 pounds as ounces":                          "32.00 ounces",
+	"2 pounds -> ounces":                          "32.00 ounces",
+	"2 pounds => ounces":                          "32.00 ounces",
+	"2pounds->ounces":                             "32.00 ounces",
+	"2 pounds IN\tounces":                         "32.00 ounces",
+	"3 fluid ounces in ml":                        "85.24 ml",
+	"2 nautical miles in km":                      "3.70 km",
+	"100 degrees Celsius in degrees Fahrenheit":   "212.00 degrees Fahrenheit",
+	"100 °C in °F":                                "212.00 °F",
+	"30 m/s in km/h":                              "108.00 km/h",
+	"5 µm in nm":                                  "5000.00 nm",
+	"6' + 12\" in inches":                         "84.00 inches",
+	"2 kg * 3":                                    "6.00 kg",
+	"2 kg + 3 kg / 2":                             "3.50 kg",
+	"10 miles / 4 in km":                          "4.02 km",
+	"1..2 feet * 12 in inches":                    "144.00..288.00 inches",
+	"2 miles / 500 feet":                          "21.12",
+	"1 m + (2 ft + 3 in)":                         "1.69 m",
+	"(1 m + 2 ft) * 3":                            "4.83 m",
+	"(10..12 m) * (3..4 m) in ft^2":               "322.92..516.67 ft^2",
+	"10..12 m * 3..4 m":                           "30.00..48.00 m^2",
+	"2 ft * 3 ft in square inches":                "864.00 square inches",
+	"1 acre / 10 yd in m":                         "442.57 m",
+	"(6 m^2 / 2 m) / 3 m":                         "1.00",
+	"-2..3 m * 4..5 m":                            "-10.00..15.00 m^2",
+	"12..18 m^2 / 2..3 m":                         "4.00..9.00 m",
+}
+
+var radixExpressions = map[string]string{
+	"255 bytes in hex":          "0xFF bytes",
+	"0b1010 B + 0xa B in bin":   "0b10100 B",
+	"1_024 bytes in oct":        "0o2000 bytes",
+	"-255 bits in hex":          "-0xFF bits",
+	"1..16 bytes in hex":        "0x1..0x10 bytes",
+	"255 in hex":                "0xFF",
+	"0xFF to bin":               "0b11111111",
+	"1..16 in hex":              "0x1..0x10",
+	"-10 in oct":                "-0o12",
+	"1e19 bytes in hex":         "0x8AC7230489E80000 bytes",
+	"0x8000000000000000 in hex": "0x8000000000000000",
+	"0xFFFFFFFFFFFFF800 in hex": "0xFFFFFFFFFFFFF800",
 }
 
 var badExpressions = map[string]string{
-	"32 googles":         "'googles' is not a known measurement unit",
-	"2 miles / 500 feet": "got '/', but expected '+', '-', 'in'",
-	"2 miles + 3 pounds": "cannot convert from pounds to miles",
-	"pounds":             "got 'pounds', but expected a number",
+	"32 googles":                "'googles' is not a known measurement unit",
+	"2 kg * 3 kg":               "cannot multiply kg by kg",
+	"2 kg / 3 m":                "cannot divide kg by m",
+	"2 m / (1 m - 1 m)":         "cannot divide 2.00 m by zero",
+	"(2 kg + 3 kg":              "reached end of input, but expected ')'",
+	"2 kg in":                   "reached end of input, but expected a unit",
+	"(2 ft + 3 in) in ":         "reached end of input, but expected a unit",
+	"-(2 kg)":                   "'-' cannot negate a group; multiply it by -1 instead",
+	"2 kg + 3 kg)":              "got ')', but expected '+', '-', '*', '/', 'in', end of input",
+	"2 miles + 3 pounds":        "cannot convert from pounds to miles",
+	"pounds":                    "got 'pounds', but expected a number",
+	"2 kg in hex":               "cannot display kg in hex; only whole numbers of bytes or bits",
+	"2.5 in hex":                "cannot display 2.5 in hex; only whole numbers of bytes or bits",
+	"0x10000000000000000 bytes": "'0x10000000000000000' is not a valid number",
+	"1.000,5 kg":                "'1.000,5' is ambiguous; use '.' to separate decimals and ',' to group thousands",
+	"1,00 kg":                   "'1,00' is ambiguous; use '.' to separate decimals and ',' to group thousands",
+}
+
+var localeExpressions = map[string]map[string]string{
+	"en": {
+		"1,000.5 kg + 500 g":  "1,001.00 kg",
+		"2.5 pounds in grams": "1,133.98 grams",
+	},
+	"de": {
+		"1.000,5 kg + 500 g":     "1.001,00 kg",
+		"2,5..3 kg":              "2,50..3,00 kg",
+		"2 Kilogramm in Pfund":   "4,41 Pfund",
+		"3 Stunden nach Minuten": "180,00 Minuten",
+		"1.500 Gramm + 2 Pfund":  "2.407,18 Gramm",
+	},
+	"es": {
+		"3 metros en pies":     "9,84 pies",
+		"2,5 libras en gramos": "1.133,98 gramos",
+		"3 metros in pies":     "9,84 pies",
+		"2 metros / 3 metros":  "0,67",
+	},
+}
+
+var localeBadExpressions = map[string]map[string]string{
+	"en": {
+		// units are named in the language of the locale only
+		"2 kg in Pfund": "'Pfund' is not a known measurement unit",
+	},
+	"de": {
+		"2 Kilogramm nach Meter": "kann nicht von Kilogramm in Meter umrechnen",
+		"32 Googles":             "'Googles' ist keine bekannte Maßeinheit",
+		"22":                     "Ende der Eingabe erreicht, aber eine Einheit erwartet",
+		"nach = 2 kg":            "'nach' ist eine Einheit oder ein Schlüsselwort und kann keine Variable benennen",
+	},
+	"es": {
+		"2 kg * 3 kg": "no se puede multiplicar kg por kg",
+		"kg = 5 m":    "'kg' es una unidad o una palabra clave, así que no puede nombrar una variable",
+	},
+}
+
+var regionExpressions = map[string]map[string]string{
+	"us": {
+		"2 t in kg":       "1814.37 kg",
+		"3 gal in liters": "11.36 liters",
+		"8 oz in ml":      "236.59 ml",
+		"2 calories in J": "8368.00 J",
+	},
+	"uk": {
+		"2 tons in kg":    "2032.09 kg",
+		"3 gal in liters": "13.64 liters",
+		"8 oz in ml":      "227.30 ml",
+	},
+	"metric": {
+		"2 t in kg":  "2000.00 kg",
+		"2 cal in J": "8.37 J",
+	},
 }
 
 func TestCalculate(t *testing.T) {
@@ -53,12 +175,99 @@ func TestCalculateAmount(t *testing.T) {
 		t.Run(input, func(t *testing.T) {
 			actual, err := calc.CalculateAmount(input)
 			actualStr := fmt.Sprintf("%.2f %s", actual.Value, actual.Units.Value)
+			if actual.Interval {
+				actualStr = fmt.Sprintf("%.2f..%.2f %s", actual.Value, actual.Upper, actual.Units.Value)
+			}
+			assert.Nil(t, err)
+			// an amount without units is just a number
+			assert.Equal(t, expected, strings.TrimSpace(actualStr))
+		})
+	}
+}
+
+func TestCalculateRadix(t *testing.T) {
+	for input, expected := range radixExpressions {
+		t.Run(input, func(t *testing.T) {
+			actual, err := calc.Calculate(input)
+			assert.Nil(t, err, input)
+			assert.Equal(t, expected, actual, input)
+		})
+	}
+}
+
+func TestCalculateWithLocale(t *testing.T) {
+	for name, expressions := range localeExpressions {
+		loc, err := calc.FindLocale(name)
+		assert.Nil(t, err)
+		for input, expected := range expressions {
+			t.Run(name+" "+input, func(t *testing.T) {
+				actual, err := calc.Calculate(input, calc.WithLocale(loc))
+				assert.Nil(t, err, input)
+				assert.Equal(t, expected, actual, input)
+			})
+		}
+	}
+}
+
+func TestCalculateWithPrecision(t *testing.T) {
+	tests := map[int]string{
+		0: "882 g",
+		1: "881.8 g",
+		4: "881.7836 g",
+	}
+	for precision, expected := range tests {
+		t.Run(expected, func(t *testing.T) {
+			actual, err := calc.Calculate("1.944 lbs in g", calc.WithPrecision(precision))
 			assert.Nil(t, err)
-			assert.Equal(t, expected, actualStr)
+			assert.Equal(t, expected, actual)
+		})
+	}
+	// the precision is at most MaxPrecision
+	most, _ := calc.Calculate("1.944 lbs in g", calc.WithPrecision(calc.MaxPrecision))
+	actual, _ := calc.Calculate("1.944 lbs in g", calc.WithPrecision(1000))
+	assert.Equal(t, most, actual)
+}
+
+func TestFormat(t *testing.T) {
+	amt, err := calc.CalculateAmount("3..5 kg")
+	assert.Nil(t, err)
+	assert.Equal(t, "3.00..5.00 kg", calc.Format(amt))
+	assert.Equal(t, "3.0..5.0 kg", calc.Format(amt, calc.WithPrecision(1)))
+}
+
+func TestFormatRadix(t *testing.T) {
+	bytes := types.Units.Token("bytes")
+	tests := map[string]types.Amount{
+		"0xFF bytes":                {Value: 255, Units: bytes, Radix: 16},
+		"-0b101 bytes":              {Value: -5, Units: bytes, Radix: 2},
+		"0x8AC7230489E80000 bytes":  {Value: 1e19, Units: bytes, Radix: 16},
+		"-0x8000000000000000 bytes": {Value: -math.Pow(2, 63), Units: bytes, Radix: 16},
+		// only whole numbers are formatted in a radix other than decimal
+		"2.50 bytes": {Value: 2.5, Units: bytes, Radix: 16},
+		"+Inf bytes": {Value: math.Inf(1), Units: bytes, Radix: 16},
+	}
+	for expected, amt := range tests {
+		t.Run(expected, func(t *testing.T) {
+			assert.Equal(t, expected, calc.Format(amt))
 		})
 	}
 }
 
+func TestCalculateBadExprWithLocale(t *testing.T) {
+	for name, expressions := range localeBadExpressions {
+		loc, err := calc.FindLocale(name)
+		assert.Nil(t, err)
+		for input, expectedErr := range expressions {
+			t.Run(name+" "+input, func(t *testing.T) {
+				_, err := calc.Calculate(input, calc.WithLocale(loc))
+				if assert.NotNil(t, err) {
+					assert.Equal(t, expectedErr, err.Translate(loc))
+				}
+			})
+		}
+	}
+}
+
 func TestCalculateBadExpr(t *testing.T) {
 	for input, expectedErr := range badExpressions {
 		t.Run(input, func(t *testing.T) {
@@ -68,3 +277,296 @@ func TestCalculateBadExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateWithRegion(t *testing.T) {
+	for name, expressions := range regionExpressions {
+		region, err := calc.FindRegion(name)
+		assert.Nil(t, err)
+		for input, expected := range expressions {
+			t.Run(name+" "+input, func(t *testing.T) {
+				actual, err := calc.Calculate(input, calc.WithRegion(region))
+				assert.Nil(t, err, input)
+				assert.Equal(t, expected, actual, input)
+			})
+		}
+	}
+}
+
+func TestCalculateAmbiguousUnits(t *testing.T) {
+	_, err := calc.Calculate("3 gal in liters")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "'gal' is ambiguous; it could be gallon (imperial), fluid gallon (us)", err.Error())
+	}
+	actual, err := calc.Calculate("8 oz in g")
+	assert.Nil(t, err)
+	assert.Equal(t, "226.80 g", actual)
+
+	for _, input := range []string{"2 stones + 0.5 tons in pounds", "2 t in kg"} {
+		_, err = calc.Calculate(input)
+		assert.IsType(t, &types.AmbiguousUnits{}, err, input)
+	}
+}
+
+func BenchmarkCalculateAmount(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := calc.CalculateAmount("12 oz + 1.2 lbs + 24 oz - 0.8 lbs in pounds")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+var limitExpressions = map[string]struct {
+	option   calc.Option
+	expected string
+}{
+	"2 kg + 3 kg + 4 kg":     {calc.WithMaxInputLength(10), "the input is longer than 10 characters"},
+	"2 kg + 3 kg":            {calc.WithMaxTokens(4), "the input has more than 4 tokens"},
+	"1 kg + 2 kg + 3 kg":     {calc.WithMaxDepth(1), "the expression is nested more than 1 levels deep"},
+	"1234567.8901 kg":        {calc.WithMaxDigits(10), "'1234567.8901' has more than 10 significant digits"},
+	"12345678901234567890 g": {calc.WithMaxDigits(calc.DefaultMaxDigits), "'12345678901234567890' has more than 15 significant digits"},
+}
+
+func TestCalculateLimits(t *testing.T) {
+	for input, limit := range limitExpressions {
+		t.Run(input, func(t *testing.T) {
+			_, err := calc.Calculate(input, limit.option)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, limit.expected, err.Error())
+			}
+			// without the limit the input is fine
+			_, err = calc.Calculate(input, limit.option, calc.WithMaxInputLength(0), calc.WithMaxTokens(0), calc.WithMaxDepth(0), calc.WithMaxDigits(0))
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestCalculateDefaultLimits(t *testing.T) {
+	long := strings.Repeat("1 kg + ", 150) + "1 kg"
+	_, err := calc.Calculate(long, calc.WithDefaultLimits())
+	assert.IsType(t, &types.InputTooLong{}, err)
+
+	actual, err := calc.Calculate("1,000,000,000,000.5 g + 1 g", calc.WithDefaultLimits())
+	assert.Nil(t, err)
+	assert.Equal(t, "1000000000001.50 g", actual)
+
+	// the number of digits is not limited by default
+	actual, err = calc.Calculate("1234567890123456 m", calc.WithDefaultLimits())
+	assert.Nil(t, err)
+	assert.Equal(t, "1234567890123456.00 m", actual)
+}
+
+func TestCalculateNoLimits(t *testing.T) {
+	long := strings.Repeat("1 kg + ", 150) + "1 kg"
+	actual, err := calc.Calculate(long)
+	assert.Nil(t, err)
+	assert.Equal(t, "151.00 kg", actual)
+}
+
+func TestCalculateAmountContext(t *testing.T) {
+	actual, err := calc.CalculateAmountContext(context.Background(), "2 kg + 3 kg")
+	assert.Nil(t, err)
+	assert.Equal(t, 5.0, actual.Value)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = calc.CalculateAmountContext(ctx, "2 kg + 3 kg")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "the calculation was cancelled; context canceled", err.Error())
+		assert.True(t, errors.Is(err, context.Canceled))
+	}
+}
+
+func TestCalculateContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err := calc.CalculateContext(ctx, "2 kg + 3 kg")
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	errs := calc.Diagnose("2 kg + 3 punds - 4 grams in lbz")
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "'punds' is not a known measurement unit", errs[0].Error())
+		assert.Equal(t, "'lbz' is not a known measurement unit", errs[1].Error())
+		assert.Equal(t, []string{"pounds", "pints"}, errs[0].(*types.InvalidUnits).Suggestions())
+	}
+	assert.Empty(t, calc.Diagnose("2 kg + 3 pounds - 4 grams in lbs"))
+}
+
+func TestDiagnoseGoodExpr(t *testing.T) {
+	for input := range expressions {
+		t.Run(input, func(t *testing.T) {
+			assert.Empty(t, calc.Diagnose(input))
+		})
+	}
+}
+
+func TestDiagnoseBadExpr(t *testing.T) {
+	for input, expectedErr := range badExpressions {
+		t.Run(input, func(t *testing.T) {
+			// the first problem found is the error from a calculation
+			errs := calc.Diagnose(input)
+			if assert.NotEmpty(t, errs) {
+				assert.Equal(t, expectedErr, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestDiagnoseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs := calc.DiagnoseContext(ctx, "2 kg + 3 kg")
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	}
+	errs = calc.Diagnose("2 kg + 3 kg + 4 kg", calc.WithMaxInputLength(10))
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &types.InputTooLong{}, errs[0])
+	}
+}
+
+func TestDiagnoseMaxDepth(t *testing.T) {
+	// the same limits apply as when calculating
+	errs := calc.Diagnose("1 kgg + 2 kg + 3 kg + 4 kg", calc.WithMaxDepth(2))
+	if assert.Len(t, errs, 2) {
+		assert.IsType(t, &types.InvalidUnits{}, errs[0])
+		assert.IsType(t, &types.TooDeep{}, errs[1])
+	}
+}
+
+func TestParse(t *testing.T) {
+	node, err := calc.Parse("2 kg + 3 lbs in g")
+	assert.Nil(t, err)
+	assert.Equal(t, "2 kg + 3 lbs in g", node.String())
+
+	_, err = calc.Parse("2 kg + 3 kg", calc.WithMaxTokens(3))
+	assert.IsType(t, &types.TooManyTokens{}, err)
+}
+
+func TestParseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := calc.ParseContext(ctx, "2 kg + 3 lbs in g")
+	assert.IsType(t, &types.Cancelled{}, err)
+}
+
+func TestExplain(t *testing.T) {
+	result, steps, err := calc.Explain("2 stones + 0.5 long tons in pounds")
+	assert.Nil(t, err)
+	assert.Equal(t, "1148.00 pounds", result)
+	expected := []string{
+		"stones is used; the units of the left operand 2.00 stones",
+		"0.50 long tons → 80.00 stones (× 160)",
+		"2.00 stones + 80.00 stones = 82.00 stones",
+		"82.00 stones → 1148.00 pounds (× 14)",
+	}
+	var actual []string
+	for _, step := range steps {
+		actual = append(actual, step.String())
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestExplainNested(t *testing.T) {
+	// each operand is evaluated, and explained, once
+	result, steps, err := calc.Explain("1 m + 2 ft * 3")
+	assert.Nil(t, err)
+	assert.Equal(t, "2.83 m", result)
+	expected := []string{
+		"m is used; the units of the left operand 1.00 m",
+		"2.00 ft × 3 = 6.00 ft",
+		"6.00 ft → 1.83 m (× 0.3048)",
+		"1.00 m + 1.83 m = 2.83 m",
+	}
+	var actual []string
+	for _, step := range steps {
+		actual = append(actual, step.String())
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestExplainProduct(t *testing.T) {
+	result, steps, err := calc.Explain("(10..12 m) * (3..4 ft) in ft^2")
+	assert.Nil(t, err)
+	assert.Equal(t, "98.43..157.48 ft^2", result)
+	expected := []string{
+		"3.00..4.00 ft → 0.91..1.22 m (× 0.3048)",
+		"10.00..12.00 m × 0.91..1.22 m = 9.14..14.63 m^2",
+		"9.14..14.63 m^2 → 98.43..157.48 ft^2 (× 10.7639)",
+	}
+	var actual []string
+	for _, step := range steps {
+		actual = append(actual, step.String())
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestExplainError(t *testing.T) {
+	_, steps, err := calc.Explain("2 kg + 3 lbs + 4 miles")
+	assert.NotNil(t, err)
+	assert.Len(t, steps, 4)
+}
+
+func TestCalculateWithVariables(t *testing.T) {
+	name, rent, err := calc.CalculateAssignment("rent = 1200 kg")
+	assert.Nil(t, err)
+	assert.Equal(t, "rent", name)
+
+	vars := map[string]types.Amount{"rent": rent}
+	name, total, err := calc.CalculateAssignment("total = rent + 2000 g", calc.WithVariables(vars))
+	assert.Nil(t, err)
+	assert.Equal(t, "total", name)
+	assert.Equal(t, "1202.00 kg", calc.Format(total))
+
+	_, total, err = calc.CalculateAssignment("total = 2 * rent", calc.WithVariables(vars))
+	assert.Nil(t, err)
+	assert.Equal(t, "2400.00 kg", calc.Format(total))
+
+	actual, err := calc.Calculate("2 kg + rent in lbs", calc.WithVariables(vars))
+	assert.Nil(t, err)
+	assert.Equal(t, "2649.96 lbs", actual)
+}
+
+func TestCalculateUnitlessVariable(t *testing.T) {
+	_, ratio, err := calc.CalculateAssignment("ratio = 10 m / 2 m")
+	assert.Nil(t, err)
+	_, x, err := calc.CalculateAssignment("x = 255 in hex")
+	assert.Nil(t, err)
+	vars := map[string]types.Amount{"ratio": ratio, "x": x}
+	for input, expected := range map[string]string{
+		"ratio + 3 kg":      "'ratio' has no units, so cannot be combined with kg",
+		"3 kg - ratio":      "'ratio' has no units, so cannot be combined with kg",
+		"x + 1 bytes":       "'x' has no units, so cannot be combined with bytes",
+		"x in kg":           "'x' has no units, so cannot be combined with kg",
+		"10 m / 2 m + 3 kg": "a number with no units cannot be combined with kg",
+	} {
+		_, err := calc.Calculate(input, calc.WithVariables(vars))
+		if assert.NotNil(t, err, input) {
+			assert.Equal(t, expected, err.Error())
+			assert.Equal(t, types.CodeIncompatibleUnits, err.Code())
+		}
+		diagnosed := calc.Diagnose(input, calc.WithVariables(vars))
+		if assert.Len(t, diagnosed, 1, input) {
+			assert.Equal(t, expected, diagnosed[0].Error())
+		}
+	}
+}
+
+func TestCalculateUnknownVariable(t *testing.T) {
+	_, err := calc.Calculate("2 kg + rent")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "expected number, but got 'r'", err.Error())
+	}
+}
+
+func TestDiagnoseWithVariables(t *testing.T) {
+	vars := map[string]types.Amount{"rent": {Value: 1200, Units: types.Units.Token("kg")}}
+	assert.Empty(t, calc.Diagnose("total = rent + 2 kg", calc.WithVariables(vars)))
+	assert.Len(t, calc.Diagnose("total = rent + 2 miles", calc.WithVariables(vars)), 1)
+}
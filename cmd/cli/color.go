@@ -0,0 +1,130 @@
+package main
+
+import (
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"os"
+	"strings"
+)
+
+// when output is colored
+const (
+	autoColor   = "auto"   // when the output is a terminal and $NO_COLOR is not set
+	alwaysColor = "always" // even when the output is a pipe or a file
+	neverColor  = "never"  // never
+)
+
+// the ANSI styles used to color output; an empty style is not colored
+type palette struct {
+	number   string // numbers like '2.5'
+	units    string // units like 'kg'
+	operator string // operators like '+' and '='
+	keyword  string // keywords like 'in' and '->'
+	variable string // the names of variables
+	err      string // errors and the spans they underline
+	warning  string // warnings and the spans they underline
+	help     string // suggestions of what was meant
+	gutter   string // the gutter and arrow of a report
+	emphasis string // the message of a report
+	comment  string // comments like '# per month'
+}
+
+// the palette of a terminal that shows colors
+var ansiColors = palette{
+	number:   "36",
+	units:    "32",
+	operator: "33",
+	keyword:  "35",
+	variable: "34",
+	err:      "1;31",
+	warning:  "1;33",
+	help:     "1;32",
+	gutter:   "1;34",
+	emphasis: "1",
+	comment:  "90",
+}
+
+// the palette of output that is not colored
+var noColors = palette{}
+
+// useColor returns true if output should be colored given the color setting; auto colors a terminal unless
+// $NO_COLOR is set or the terminal is dumb.
+func useColor(setting string, terminal bool) bool {
+	switch setting {
+	case alwaysColor:
+		return true
+	case neverColor:
+		return false
+	}
+	return terminal && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// colors returns the palette of output given the color setting.
+func colors(setting string, terminal bool) palette {
+	if useColor(setting, terminal) {
+		return ansiColors
+	}
+	return noColors
+}
+
+// paint returns the text in a style; the text as is if the style is empty.
+func paint(style, text string) string {
+	if style == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + style + "m" + text + "\x1b[0m"
+}
+
+// style returns the style of a token.
+func (p palette) style(tokenType types.TokenType) string {
+	switch tokenType {
+	case types.Number:
+		return p.number
+	case types.Units:
+		return p.units
+	case types.Plus, types.Minus, types.Multiply, types.Divide, types.Range, types.Assign, types.LeftParen, types.RightParen:
+		return p.operator
+	case types.In:
+		return p.keyword
+	case types.Name:
+		return p.variable
+	default:
+		return ""
+	}
+}
+
+// highlight returns an expression with each number, unit, operator and keyword in its style. The input after
+// a token that is not valid is left as is.
+func (p palette) highlight(input string, opts ...tokenizer.Option) string {
+	if p == noColors {
+		return input
+	}
+	var tokens []types.Token
+	lexer := tokenizer.NewLexer(input, opts...)
+	for {
+		token := lexer.Next()
+		tokens = append(tokens, token)
+		if token.TokenType == types.EOF || token.TokenType == types.Error {
+			break
+		}
+	}
+	var b strings.Builder
+	start := 0
+	for i, token := range tokens {
+		// a token spans the input up to the next token, less any spaces between them
+		end := len(input)
+		if i+1 < len(tokens) {
+			end = tokens[i+1].Position - 1
+		}
+		if token.Position-1 < start || end < token.Position-1 || end > len(input) {
+			break
+		}
+		b.WriteString(input[start : token.Position-1])
+		text := input[token.Position-1 : end]
+		trimmed := strings.TrimRight(text, " \t\r\n")
+		b.WriteString(paint(p.style(token.TokenType), trimmed) + text[len(trimmed):])
+		start = end
+	}
+	b.WriteString(input[start:])
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"regexp"
+	"testing"
+)
+
+// matches each ANSI style
+var ansiStyle = regexp.MustCompile("\x1b\\[([0-9;]*)m")
+
+// replaces each ANSI style with a readable tag; like '<36>2<0>'
+func tags(styled string) string {
+	return ansiStyle.ReplaceAllString(styled, "<$1>")
+}
+
+func TestHighlight(t *testing.T) {
+	tests := map[string]string{
+		"2 kg + 3 lbs in g":   "<36>2<0> <32>kg<0> <33>+<0> <36>3<0> <32>lbs<0> <35>in<0> <32>g<0>",
+		"  2..3 fluid ounces": "  <36>2<0><33>..<0><36>3<0> <32>fluid ounces<0>",
+		"5 km -> miles\n":     "<36>5<0> <32>km<0> <35>-><0> <32>miles<0>\n",
+		"rent = 12 kg":        "<34>rent<0> <33>=<0> <36>12<0> <32>kg<0>",
+		"2 kg ? 3 kg":         "<36>2<0> <32>kg<0> ? 3 kg",
+		"":                    "",
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, expected, tags(ansiColors.highlight(input)))
+		})
+	}
+}
+
+func TestHighlightVariables(t *testing.T) {
+	highlighted := ansiColors.highlight("rent + 2 kg", tokenizer.WithVariables([]string{"rent"}))
+	assert.Equal(t, "<34>rent<0> <33>+<0> <36>2<0> <32>kg<0>", tags(highlighted))
+}
+
+func TestHighlightNoColors(t *testing.T) {
+	assert.Equal(t, "2 kg + 3 lbs", noColors.highlight("2 kg + 3 lbs"))
+}
+
+func TestUseColor(t *testing.T) {
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	defer os.Setenv("TERM", os.Getenv("TERM"))
+	os.Setenv("NO_COLOR", "")
+	os.Setenv("TERM", "xterm")
+
+	assert.True(t, useColor(autoColor, true))
+	assert.False(t, useColor(autoColor, false))
+	assert.True(t, useColor(alwaysColor, false))
+	assert.False(t, useColor(neverColor, true))
+
+	os.Setenv("TERM", "dumb")
+	assert.False(t, useColor(autoColor, true))
+
+	os.Setenv("TERM", "xterm")
+	os.Setenv("NO_COLOR", "1")
+	assert.False(t, useColor(autoColor, true))
+	assert.True(t, useColor(alwaysColor, true))
+}
+
+func TestRunColor(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"-color", "always", "2 kg in g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "<36>2000.00<0> <32>g<0> \n", tags(stdout))
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runWith([]string{"-color", "always", "2 kg in punds"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, tags(stderr), "<1;31>error[QC1001]<0><1>: 'punds' is not a known measurement unit<0>")
+	assert.Contains(t, tags(stderr), "<1;31>^^^^^ unknown unit<0>")
+	assert.Contains(t, tags(stderr), "<1>help:<0> <1;32>did you mean 'pounds', 'pints'?<0>")
+
+	// not a terminal
+	_, stdout, _ = runWith([]string{"2 kg in g"}, "", true)
+	assert.Equal(t, "2000.00 g \n", stdout)
+}
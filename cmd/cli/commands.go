@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/service"
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// the input, output and default settings of each command
+type cli struct {
+	stdin       inputReader  // standard input
+	interactive bool         // true if standard input is a terminal
+	edit        bool         // true if the lines typed at a prompt can be edited; both input and output are a terminal
+	terminal    bool         // true if standard output is a terminal
+	errTerminal bool         // true if standard error is a terminal
+	stdout      outputWriter // standard output
+	stderr      outputWriter // standard error
+	settings    settings     // the defaults, from the config file
+}
+
+// a command like 'eval' or 'units'
+type command struct {
+	name    string                          // the name of the command
+	args    string                          // describes the arguments of the command
+	summary string                          // describes what the command does
+	run     func(c *cli, args []string) int // runs the command and returns the exit code
+}
+
+// the commands, in the order they are listed
+var commands []command
+
+func init() {
+	commands = []command{
+		{"eval", "[expression]", "evaluate an expression, each line of a file or each line of standard input", (*cli).eval},
+		{"repl", "", "prompt for expressions to evaluate", (*cli).repl},
+		{"sheet", "file", "evaluate each line of a file with shared variables and show the results in a column", (*cli).sheet},
+		{"tokens", "[expression]", "show the tokens of each expression", (*cli).tokens},
+		{"ast", "[expression]", "show the syntax tree of each expression", (*cli).ast},
+		{"units", "[search]", "list the known units", (*cli).units},
+		{"serve", "", "serve calculations over HTTP as JSON", (*cli).serve},
+		{"lsp", "", "serve editors as a language server, over standard input and output", (*cli).lsp},
+	}
+}
+
+// usage describes how to use the command line interface.
+const usage = `Usage:
+  qcalc [command] [flags] [arguments]
+
+Commands:
+%s
+Without a command, the arguments are evaluated as by 'eval'. The defaults of the flags are read from
+~/.config/qcalc/config, or the file named by $QCALC_CONFIG, with one setting per line; like 'precision = 4'.
+Use 'qcalc <command> -h' to see the flags of a command and '--' before an expression that starts with '-'.
+`
+
+// printUsage prints how to use the command line interface.
+func (c *cli) printUsage() {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(c.stderr, usage, b.String())
+}
+
+// run runs the command named by the first argument; otherwise evaluates the arguments.
+func (c *cli) run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			c.printUsage()
+			return exitOK
+		}
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd.run(c, args[1:])
+			}
+		}
+	}
+	return c.eval(args)
+}
+
+// flags returns the flags of a command, including those shared by each command.
+func (c *cli) flags(name, args string, s *settings) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage:\n  qcalc %s [flags] %s\n\nFlags:\n", name, args)
+		flags.PrintDefaults()
+	}
+	s.addFlags(flags)
+	return flags
+}
+
+// parse parses the flags of a command and returns the options used to calculate expressions, or the exit code
+// if the command should not continue.
+func (c *cli) parse(flags *flag.FlagSet, args []string, s *settings) ([]calc.Option, int, bool) {
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return nil, exitOK, false
+	} else if err != nil {
+		return nil, exitFailed, false
+	}
+	opts, err := s.options()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return nil, exitFailed, false
+	}
+	return opts, exitOK, true
+}
+
+// evaluator returns an evaluator of expressions with the given settings.
+func (c *cli) evaluator(opts []calc.Option, s settings) *evaluator {
+	return &evaluator{
+		opts:      opts,
+		precision: s.precision,
+		output:    s.output,
+		locale:    s.findLocale(),
+		vars:      map[string]types.Amount{},
+		colors:    colors(s.color, c.terminal),
+		errColors: colors(s.color, c.errTerminal),
+		writer:    c.stdout,
+		errWriter: c.stderr,
+	}
+}
+
+// each calls a function with the arguments as an expression, each line of a file, or each line of standard
+// input; returns the exit code.
+func (c *cli) each(args []string, file string, fn func(input string) bool) int {
+	switch {
+	case file != "":
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "error: %s\n", err)
+			return exitFailed
+		}
+		defer f.Close()
+		return batch(bufio.NewReader(f), fn, c.stderr)
+	case len(args) > 0:
+		if !fn(strings.Join(args, " ")) {
+			return exitInvalid
+		}
+		return exitOK
+	default:
+		return batch(c.stdin, fn, c.stderr)
+	}
+}
+
+// eval evaluates an expression, each line of a file or each line of standard input.
+func (c *cli) eval(args []string) int {
+	s := c.settings
+	flags := c.flags("eval", "[expression]", &s)
+	file := flags.String("f", "", "evaluate each line of a `file`")
+	explain := flags.Bool("explain", false, "explain each step taken to evaluate an expression")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	e := c.evaluator(opts, s)
+	if *explain {
+		e.mode = explainMode
+	}
+	if *file == "" && flags.NArg() == 0 && c.interactive {
+		// there is nothing to evaluate, so prompt for it
+		return c.prompt(e, s)
+	}
+	return c.each(flags.Args(), *file, e.evaluate)
+}
+
+// repl prompts for expressions to evaluate.
+func (c *cli) repl(args []string) int {
+	s := c.settings
+	flags := c.flags("repl", "", &s)
+	explain := flags.Bool("explain", false, "explain each step taken to evaluate an expression")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	e := c.evaluator(opts, s)
+	if *explain {
+		e.mode = explainMode
+	}
+	return c.prompt(e, s)
+}
+
+// prompt prompts for expressions to evaluate until there are no more; the lines can be edited when the input is
+// a terminal.
+func (c *cli) prompt(e *evaluator, s settings) int {
+	if !c.edit {
+		e.repl(&plainReader{reader: c.stdin, writer: c.stdout})
+		return exitOK
+	}
+	fmt.Fprintln(c.stdout, "Type an expression like '2 kg in lbs', or :help to see the commands of the prompt.")
+	e.completer = unitCompleter(e.locale)
+	editor := newEditor(e.completer, historyPath())
+	if e.colors != noColors {
+		editor.echo = func(line string) string {
+			return e.colors.highlight(line, e.tokenizerOptions()...)
+		}
+	}
+	defer editor.Close()
+	e.repl(editor)
+	return exitOK
+}
+
+// a token written as JSON
+type jsonToken struct {
+	Type     string `json:"type"`     // the name of the type of token; like 'number'
+	Value    string `json:"value"`    // the token as written
+	Position int    `json:"position"` // the position of the token
+}
+
+// tokens shows the tokens of each expression.
+func (c *cli) tokens(args []string) int {
+	s := c.settings
+	flags := c.flags("tokens", "[expression]", &s)
+	file := flags.String("f", "", "show the tokens of each line of a `file`")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	e := c.evaluator(opts, s)
+	if s.output == plainOutput {
+		e.mode = debugMode
+		return c.each(flags.Args(), *file, e.evaluate)
+	}
+	table := newTable(c.stdout, s.output)
+	if isTable(s.output) {
+		table.Write([]string{"input", "type", "value", "position"})
+	}
+	return c.each(flags.Args(), *file, func(input string) bool {
+		var tokens []jsonToken
+		lexer := tokenizer.NewLexer(input, e.lexerOptions()...)
+		for {
+			token := lexer.Next()
+			tokens = append(tokens, jsonToken{token.TokenType.Name(), token.Value, token.Position})
+			if token.TokenType == types.EOF {
+				break
+			}
+		}
+		if s.output == jsonOutput {
+			json.NewEncoder(c.stdout).Encode(tokens)
+			return true
+		}
+		input = strings.TrimRight(input, "\r\n")
+		for _, token := range tokens {
+			table.Write([]string{input, token.Type, token.Value, strconv.Itoa(token.Position)})
+		}
+		table.Flush()
+		return true
+	})
+}
+
+// a node of a syntax tree written as JSON
+type jsonNode struct {
+	Kind     string     `json:"kind"`               // the kind of node; like 'addition'
+	Span     ast.Span   `json:"span"`               // the part of the input spanned by the node
+	Text     string     `json:"text"`               // the node as an expression
+	Children []jsonNode `json:"children,omitempty"` // the operands of the node
+}
+
+// newJSONNode returns a node, and each of its children, as JSON.
+func newJSONNode(node ast.Node) jsonNode {
+	n := jsonNode{Kind: node.Kind().String(), Span: node.Span(), Text: node.String()}
+	for _, child := range node.Children() {
+		n.Children = append(n.Children, newJSONNode(child))
+	}
+	return n
+}
+
+// printTree prints a node, and each of its children indented below it, with the positions of the first and
+// last bytes spanned; like 'addition 1..12 2 kg + 3 lbs'.
+func printTree(node ast.Node, depth int, writer outputWriter) {
+	span := node.Span()
+	fmt.Fprintf(writer, "%s%s %d..%d %s\n", strings.Repeat("  ", depth), node.Kind(), span.Start, span.End()-1, node)
+	for _, child := range node.Children() {
+		printTree(child, depth+1, writer)
+	}
+}
+
+// writeNodes writes a node, and each of its children after it, as rows of a table with the depth of each node.
+func writeNodes(table *csv.Writer, input string, node ast.Node, depth int) {
+	span := node.Span()
+	table.Write([]string{input, strconv.Itoa(depth), node.Kind().String(), strconv.Itoa(span.Start), strconv.Itoa(span.Width), node.String()})
+	for _, child := range node.Children() {
+		writeNodes(table, input, child, depth+1)
+	}
+}
+
+// ast shows the syntax tree of each expression.
+func (c *cli) ast(args []string) int {
+	s := c.settings
+	flags := c.flags("ast", "[expression]", &s)
+	file := flags.String("f", "", "show the syntax tree of each line of a `file`")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	e := c.evaluator(opts, s)
+	table := newTable(c.stdout, s.output)
+	if isTable(s.output) {
+		table.Write([]string{"input", "depth", "kind", "start", "width", "text"})
+	}
+	return c.each(flags.Args(), *file, func(input string) bool {
+		node, err := calc.Parse(input, opts...)
+		switch {
+		case err != nil && s.output == jsonOutput:
+			json.NewEncoder(c.stdout).Encode(jsonError{Input: strings.TrimRight(input, "\r\n"), Error: diagnostic.Translate(err, e.locale)})
+		case err != nil:
+			e.report(input, err)
+		case s.output == jsonOutput:
+			json.NewEncoder(c.stdout).Encode(newJSONNode(node))
+		case isTable(s.output):
+			writeNodes(table, strings.TrimRight(input, "\r\n"), node, 0)
+			table.Flush()
+		default:
+			printTree(node, 0, c.stdout)
+		}
+		return err == nil
+	})
+}
+
+// units lists the known units, or those whose names contain a search term.
+func (c *cli) units(args []string) int {
+	s := c.settings
+	flags := c.flags("units", "[search]", &s)
+	quantity := flags.String("quantity", "", "list only the units of a `quantity`; like mass or length")
+	if _, code, ok := c.parse(flags, args, &s); !ok {
+		return code
+	}
+	if !listUnits(c.stdout, *quantity, strings.Join(flags.Args(), " "), s.output) {
+		fmt.Fprintln(c.stderr, "error: no units found")
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// listUnits lists the units of a quantity, or of every quantity if none, whose names contain a search term;
+// returns false if there are none.
+func listUnits(writer outputWriter, quantity, search, output string) bool {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	table := newTable(writer, output)
+	found := false
+	for _, unit := range service.Units(quantity, search) {
+		if !found && isTable(output) {
+			table.Write([]string{"name", "symbol", "plural", "quantity", "system", "aliases"})
+		}
+		found = true
+		switch {
+		case output == jsonOutput:
+			json.NewEncoder(writer).Encode(unit)
+		case isTable(output):
+			table.Write([]string{unit.Name, unit.Symbol, unit.Plural, unit.Quantity, unit.System, strings.Join(unit.Aliases, ", ")})
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", unit.Name, unit.Symbol, unit.Quantity, strings.Join(unit.Aliases, ", "))
+		}
+	}
+	w.Flush()
+	table.Flush()
+	return found
+}
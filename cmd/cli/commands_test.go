@@ -0,0 +1,162 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunEval(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-precision", "1", "2 kg in g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2000.0 g \n", stdout)
+}
+
+func TestRunEvalExplain(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-explain", "2 kg + 3 lbs"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "  2. 3.00 lbs → 1.36 kg (× 0.453592)\n")
+}
+
+func TestRunEvalJSON(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-output", "json"}, "2 kg in g\n3 kg in punds\n", false)
+	assert.Equal(t, exitInvalid, code)
+	expected := `{"input":"2 kg in g","result":"2000.00 g","value":2000,"units":"g","unit":"gram","symbol":"g","quantity":"mass"}` + "\n" +
+		`{"input":"3 kg in punds","error":{"code":"QC1001","name":"unknown-unit","severity":"error",` +
+		`"message":"'punds' is not a known measurement unit","start":9,"width":5,"suggestions":["pounds","pints"]}}` + "\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunEvalJSONLocale(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-output", "json", "-locale", "es", "3 kg en punds"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, `"message":"'punds' no es una unidad de medida conocida"`)
+}
+
+func TestRunEvalBadOutput(t *testing.T) {
+	code, _, stderr := runWith([]string{"eval", "-output", "xml", "2 kg"}, "", true)
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, "error: 'xml' is not an output format; expected plain, json, csv or tsv\n", stderr)
+}
+
+func TestRunEvalLocale(t *testing.T) {
+	code, stdout, _ := runWith([]string{"-locale", "de", "2,5 kg + 1 kg"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "3,50 kg \n", stdout)
+}
+
+func TestRunEvalUnits(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "units.txt")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("# a unit of length\nrod (rd) = 5.0292 m\n"), 0600))
+	code, stdout, stderr := runWith([]string{"eval", "-units", file, "2 rods in m"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "10.06 m \n", stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRunEvalConfig(t *testing.T) {
+	defer os.Setenv("QCALC_CONFIG", "")
+	os.Setenv("QCALC_CONFIG", writeConfig(t, "precision = 3\n"))
+
+	// the flags override the config file
+	_, stdout, _ := runWith([]string{"1 lb in g"}, "", true)
+	assert.Equal(t, "453.592 g \n", stdout)
+	_, stdout, _ = runWith([]string{"-precision", "0", "1 lb in g"}, "", true)
+	assert.Equal(t, "454 g \n", stdout)
+}
+
+func TestRunBadConfig(t *testing.T) {
+	defer os.Setenv("QCALC_CONFIG", "")
+	path := writeConfig(t, "colour = blue\n")
+	os.Setenv("QCALC_CONFIG", path)
+
+	code, _, stderr := runWith([]string{"1 lb in g"}, "", true)
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, "error: "+path+":1: 'colour' is not a known setting\n", stderr)
+}
+
+func TestRunRepl(t *testing.T) {
+	code, stdout, _ := runWith([]string{"repl"}, ":debug\n2 + 2\n", false)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "\n > debug is on; the tokens of each expression are shown\n\n > NUM[2]  SYM[+]  NUM[2]  EOF  \n\n > \n", stdout)
+}
+
+func TestRunReplDebugVariable(t *testing.T) {
+	code, stdout, _ := runWith([]string{"repl"}, "x = 2 kg\n:debug\nx + 1 kg\n", false)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, " > VAR[x]  SYM[+]  NUM[1]  UNI[kg]  EOF  \n")
+}
+
+func TestRunTokens(t *testing.T) {
+	code, stdout, _ := runWith([]string{"tokens", "-output", "json", "2 kg"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := `[{"type":"number","value":"2","position":1},{"type":"units","value":"kg","position":3},` +
+		`{"type":"eof","value":"","position":5}]` + "\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunTokensLocale(t *testing.T) {
+	code, stdout, _ := runWith([]string{"tokens", "-output", "json", "-locale", "de", "2,5 kg nach g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := `[{"type":"number","value":"2,5","position":1},{"type":"units","value":"kg","position":5},` +
+		`{"type":"in","value":"nach","position":8},{"type":"units","value":"g","position":13},` +
+		`{"type":"eof","value":"","position":14}]` + "\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunAst(t *testing.T) {
+	code, stdout, _ := runWith([]string{"ast", "2 kg + 3 lbs in g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := "unit conversion 1..17 2 kg + 3 lbs in g\n" +
+		"  addition 1..12 2 kg + 3 lbs\n" +
+		"    value 1..4 2 kg\n" +
+		"    value 8..12 3 lbs\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunAstJSON(t *testing.T) {
+	code, stdout, _ := runWith([]string{"ast", "-output", "json", "2 kg in hex"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := `{"kind":"radix conversion","span":{"start":1,"width":11},"text":"2 kg in hex",` +
+		`"children":[{"kind":"value","span":{"start":1,"width":4},"text":"2 kg"}]}` + "\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunAstInvalid(t *testing.T) {
+	code, _, stderr := runWith([]string{"ast", "2 kg +"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stderr, "error[QC2003]: expected number, but got ''\n --> position 7")
+}
+
+func TestRunAstInvalidJSONLocale(t *testing.T) {
+	code, stdout, _ := runWith([]string{"ast", "-output", "json", "-locale", "de", "2 kg +"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, `"message":"Zahl erwartet, aber '' gefunden"`)
+}
+
+func TestRunUnits(t *testing.T) {
+	code, stdout, _ := runWith([]string{"units", "-quantity", "mass", "kilogram"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "kilogram")
+	assert.NotContains(t, stdout, "kilometer")
+}
+
+func TestRunUnitsJSON(t *testing.T) {
+	code, stdout, _ := runWith([]string{"units", "-output", "json", "kilogram"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `{"name":"kilogram","symbol":"kg","plural":"kilograms","quantity":"mass","system":"metric"}`+"\n", stdout)
+}
+
+func TestRunUnitsNotFound(t *testing.T) {
+	code, _, stderr := runWith([]string{"units", "blargle"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, "error: no units found\n", stderr)
+}
+
+func TestRunHelp(t *testing.T) {
+	code, _, stderr := runWith([]string{"help"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "  units    list the known units\n")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the settings shared by each command; the defaults come from the config file and flags override them
+type settings struct {
+	precision int      // the number of decimal places in a result
+	output    string   // the output format; plain, json, csv or tsv
+	locale    string   // the name of the locale of numbers; like 'de'
+	region    string   // the name of the region preferred for ambiguous units; like 'uk'
+	units     []string // the unit-definition files to load
+	color     string   // when output is colored; auto, always or never
+}
+
+// defaultSettings returns the settings used when there is no config file.
+func defaultSettings() settings {
+	return settings{precision: calc.DefaultPrecision, output: plainOutput, color: autoColor}
+}
+
+// configDir returns the directory of the files of qcalc; $XDG_CONFIG_HOME/qcalc or ~/.config/qcalc.
+func configDir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "qcalc")
+}
+
+// configPath returns the path of the config file; $QCALC_CONFIG if it is set, otherwise the file 'config' in
+// the directory of the files of qcalc. An empty path means there is no config file.
+func configPath() string {
+	if path, ok := os.LookupEnv("QCALC_CONFIG"); ok {
+		return path
+	}
+	if dir := configDir(); dir != "" {
+		return filepath.Join(dir, "config")
+	}
+	return ""
+}
+
+// historyPath returns the path of the file that holds the history of the prompt; $QCALC_HISTORY if it is set,
+// otherwise the file 'history' in the directory of the files of qcalc. An empty path means history is not saved.
+func historyPath() string {
+	if path, ok := os.LookupEnv("QCALC_HISTORY"); ok {
+		return path
+	}
+	if dir := configDir(); dir != "" {
+		return filepath.Join(dir, "history")
+	}
+	return ""
+}
+
+// loadSettings loads the settings of a config file; the default settings if the file does not exist.
+func loadSettings(path string) (settings, error) {
+	s := defaultSettings()
+	if path == "" {
+		return s, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return s, fmt.Errorf("%s:%d: expected a setting like 'precision = 4'", path, line)
+		}
+		if err := s.set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return s, fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+	}
+	return s, scanner.Err()
+}
+
+// set sets a setting by its name, as written in the config file.
+func (s *settings) set(name, value string) error {
+	switch name {
+	case "precision":
+		precision, err := strconv.Atoi(value)
+		if err != nil || precision < 0 {
+			return fmt.Errorf("'%s' is not a valid precision", value)
+		}
+		s.precision = precision
+	case "output":
+		s.output = value
+	case "locale":
+		s.locale = value
+	case "region":
+		s.region = value
+	case "units":
+		s.units = append(s.units, value)
+	case "color":
+		s.color = value
+	default:
+		return fmt.Errorf("'%s' is not a known setting", name)
+	}
+	return nil
+}
+
+// files are the values of a flag that can be repeated; like '-units a.txt -units b.txt'
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds the value of another flag.
+func (f *files) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// addFlags adds the flags shared by each command; the settings are the defaults.
+func (s *settings) addFlags(flags *flag.FlagSet) {
+	flags.IntVar(&s.precision, "precision", s.precision, "the number of decimal `places` in a result")
+	flags.StringVar(&s.output, "output", s.output, "the output `format`; plain, json, csv or tsv")
+	flags.StringVar(&s.locale, "locale", s.locale, "the `locale` of numbers; like en or de")
+	flags.StringVar(&s.region, "region", s.region, "the `region` preferred for ambiguous units; like us or uk")
+	flags.Var((*files)(&s.units), "units", "load the units defined in a `file`; may be repeated")
+	flags.StringVar(&s.color, "color", s.color, "`when` to color output; auto, always or never")
+}
+
+// options returns the options used to calculate expressions, once any unit-definition files are loaded.
+func (s *settings) options() ([]calc.Option, error) {
+	if err := validOutput(s.output); err != nil {
+		return nil, err
+	}
+	if s.color != autoColor && s.color != alwaysColor && s.color != neverColor {
+		return nil, fmt.Errorf("'%s' is not a valid color setting; expected auto, always or never", s.color)
+	}
+	if s.precision < 0 {
+		return nil, fmt.Errorf("'%d' is not a valid precision", s.precision)
+	}
+	opts := []calc.Option{calc.WithDefaultLimits(), calc.WithPrecision(s.precision)}
+	if s.locale != "" {
+		loc, err := calc.FindLocale(s.locale)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calc.WithLocale(loc))
+	}
+	if s.region != "" {
+		region, err := calc.FindRegion(s.region)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calc.WithRegion(region))
+	}
+	for _, path := range s.units {
+		if err := loadUnits(path); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// findLocale returns the locale of the settings; the default locale if there is none.
+func (s *settings) findLocale() locale.Locale {
+	if loc, err := calc.FindLocale(s.locale); err == nil {
+		return loc
+	}
+	return locale.Default
+}
+
+// loadUnits loads the units defined in a file; a path like '~/units.txt' is relative to the home directory.
+func loadUnits(path string) error {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := calc.LoadUnits(f); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writes a config file and returns its path
+func writeConfig(t *testing.T, config string) string {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(config), 0600))
+	return path
+}
+
+func TestLoadSettings(t *testing.T) {
+	path := writeConfig(t, `
+# my defaults
+precision = 4
+output = json # for scripts
+locale = de
+region = uk
+units = a.txt
+units = b.txt
+color = never
+`)
+	s, err := loadSettings(path)
+	assert.Nil(t, err)
+	assert.Equal(t, settings{precision: 4, output: "json", locale: "de", region: "uk", units: []string{"a.txt", "b.txt"}, color: neverColor}, s)
+}
+
+func TestLoadSettingsMissing(t *testing.T) {
+	s, err := loadSettings(filepath.Join(t.TempDir(), "missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, defaultSettings(), s)
+
+	s, err = loadSettings("")
+	assert.Nil(t, err)
+	assert.Equal(t, defaultSettings(), s)
+}
+
+func TestLoadSettingsErrors(t *testing.T) {
+	tests := map[string]struct {
+		line    string
+		message string
+	}{
+		"precision":          {"1", "expected a setting like 'precision = 4'"},
+		"precision = many":   {"1", "'many' is not a valid precision"},
+		"\ncolour = blue":    {"2", "'colour' is not a known setting"},
+		"\n\nprecision = -1": {"3", "'-1' is not a valid precision"},
+	}
+	for config, expected := range tests {
+		t.Run(expected.message, func(t *testing.T) {
+			path := writeConfig(t, config)
+			_, err := loadSettings(path)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, path+":"+expected.line+": "+expected.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	defer os.Setenv("QCALC_CONFIG", os.Getenv("QCALC_CONFIG"))
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+
+	os.Setenv("QCALC_CONFIG", "/tmp/qcalc.conf")
+	assert.Equal(t, "/tmp/qcalc.conf", configPath())
+
+	os.Unsetenv("QCALC_CONFIG")
+	os.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	assert.Equal(t, "/tmp/config/qcalc/config", configPath())
+}
+
+func TestSettingsFlags(t *testing.T) {
+	s := settings{precision: 4, output: "json", locale: "de"}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	s.addFlags(flags)
+	assert.Nil(t, flags.Parse([]string{"-precision", "1", "-units", "a.txt", "-units", "b.txt"}))
+	assert.Equal(t, settings{precision: 1, output: "json", locale: "de", units: []string{"a.txt", "b.txt"}}, s)
+}
+
+func TestSettingsOptions(t *testing.T) {
+	tests := map[string]settings{
+		"'xml' is not an output format; expected plain, json, csv or tsv":     {output: "xml"},
+		"'-1' is not a valid precision":                                       {output: "plain", color: autoColor, precision: -1},
+		"open missing.txt: no such file or directory":                         {output: "plain", color: autoColor, units: []string{"missing.txt"}},
+		"'blue' is not a valid color setting; expected auto, always or never": {output: "plain", color: "blue"},
+	}
+	for expected, s := range tests {
+		t.Run(expected, func(t *testing.T) {
+			_, err := s.options()
+			if assert.NotNil(t, err) {
+				assert.Equal(t, expected, err.Error())
+			}
+		})
+	}
+
+	s := settings{output: "plain", color: neverColor, locale: "de", region: "uk"}
+	opts, err := s.options()
+	assert.Nil(t, err)
+	// the default limits, precision, locale and region
+	assert.Len(t, opts, 4)
+}
+
+func TestLoadUnitsHome(t *testing.T) {
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	home := t.TempDir()
+	os.Setenv("HOME", home)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, "units.txt"), []byte("chain (ch) = 20.1168 m\n"), 0600))
+	assert.Nil(t, loadUnits("~/units.txt"))
+}
+
+func TestHistoryPath(t *testing.T) {
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	assert.Equal(t, "/tmp/config/qcalc/history", historyPath())
+
+	defer os.Unsetenv("QCALC_HISTORY")
+	os.Setenv("QCALC_HISTORY", "")
+	assert.Equal(t, "", historyPath())
+}
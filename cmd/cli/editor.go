@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"github.com/mattn/go-runewidth"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/peterh/liner"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// reads a line of input after a prompt
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// reads lines of input without editing; like from a pipe
+type plainReader struct {
+	reader inputReader
+	writer outputWriter
+}
+
+// readLine writes the prompt and reads the next line of input.
+func (p *plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(p.writer, prompt)
+	return p.reader.ReadString('\n')
+}
+
+// reads lines of input from a terminal with editing, a history that can be searched with ctrl-r, and tab completion
+type editor struct {
+	state   *liner.State
+	history string                   // the path of the history file; empty if the history is not saved
+	echo    func(line string) string // returns a line as it is drawn again once entered, like highlighted; nil if not
+	writer  *os.File                 // the terminal where lines are drawn
+}
+
+// newEditor returns an editor that completes words and keeps its history in a file.
+func newEditor(completer *completer, history string) *editor {
+	e := &editor{state: liner.NewLiner(), history: history, writer: os.Stdout}
+	e.state.SetCtrlCAborts(true)
+	e.state.SetTabCompletionStyle(liner.TabPrints)
+	e.state.SetWordCompleter(completer.complete)
+	if f, err := os.Open(history); err == nil {
+		e.state.ReadHistory(f)
+		f.Close()
+	}
+	return e
+}
+
+// readLine reads the next line of input; ctrl-c abandons the line and ctrl-d ends the input.
+func (e *editor) readLine(prompt string) (string, error) {
+	line, err := e.state.Prompt(prompt)
+	if err == liner.ErrPromptAborted {
+		return "", nil
+	}
+	if strings.TrimSpace(line) != "" {
+		e.state.AppendHistory(line)
+		if err == nil && e.echo != nil {
+			e.redraw(prompt, line)
+		}
+	}
+	return line, err
+}
+
+// redraw draws a line once entered again, as echoed, over the line as it was typed.
+func (e *editor) redraw(prompt, line string) {
+	width := terminalColumns(e.writer)
+	if width <= 0 {
+		return
+	}
+	// the line may wrap over more than one row of the terminal
+	rows := (runewidth.StringWidth(prompt+line)-1)/width + 1
+	fmt.Fprintf(e.writer, "\x1b[%dA\r\x1b[J%s%s\r\n", rows, prompt, e.echo(line))
+}
+
+// Close saves the history and restores the terminal.
+func (e *editor) Close() error {
+	if e.history != "" {
+		if err := os.MkdirAll(filepath.Dir(e.history), 0700); err == nil {
+			if f, err := os.OpenFile(e.history, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600); err == nil {
+				e.state.WriteHistory(f)
+				f.Close()
+			}
+		}
+	}
+	return e.state.Close()
+}
+
+// completes the words of an expression; like the names of units and keywords
+type completer struct {
+	words []string // the words that can be completed, in order
+}
+
+// newCompleter returns a completer of the given words.
+func newCompleter(words ...string) *completer {
+	c := &completer{}
+	c.add(words...)
+	return c
+}
+
+// unitCompleter returns a completer of the names of units, the keywords of a locale and the names of radixes.
+func unitCompleter(loc locale.Locale) *completer {
+	c := newCompleter(registry.Names()...)
+	c.add(loc.Keywords...)
+	c.add(types.RadixNames()...)
+	for name := range loc.Units {
+		c.add(name)
+	}
+	return c
+}
+
+// add adds words that can be completed; like the name of a variable.
+func (c *completer) add(words ...string) {
+	for _, word := range words {
+		i := sort.SearchStrings(c.words, word)
+		if word == "" || i < len(c.words) && c.words[i] == word {
+			continue
+		}
+		c.words = append(c.words, "")
+		copy(c.words[i+1:], c.words[i:])
+		c.words[i] = word
+	}
+}
+
+// complete returns the words that complete the input before the cursor. A name with spaces, like 'long tons', is
+// completed from the earliest word that any name starts with.
+func (c *completer) complete(line string, pos int) (head string, completions []string, tail string) {
+	before, tail := line[:pos], line[pos:]
+	for _, start := range wordStarts(before) {
+		if completions = c.matching(before[start:]); len(completions) > 0 {
+			return before[:start], completions, tail
+		}
+	}
+	return before, nil, tail
+}
+
+// matching returns each word that starts with a prefix, ignoring case.
+func (c *completer) matching(prefix string) (matches []string) {
+	prefix = strings.ToLower(prefix)
+	for _, word := range c.words {
+		if strings.HasPrefix(strings.ToLower(word), prefix) {
+			matches = append(matches, word)
+		}
+	}
+	return matches
+}
+
+// wordStarts returns the position of the start of each word in the input, in order; a word follows a space, an
+// operator or a number, like 'kg' in '2kg'. Nothing is returned if the input ends between words.
+func wordStarts(input string) (starts []int) {
+	prev := ' '
+	for i, r := range input {
+		if isWord(r) && !isWord(prev) {
+			starts = append(starts, i)
+		}
+		prev = r
+	}
+	if !isWord(prev) {
+		return nil
+	}
+	return starts
+}
+
+// isWord returns true if a character can be part of a word being completed.
+func isWord(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+-*/(),.", r)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	c := newCompleter("kg", "kilogram", "kilometer", "long ton", "long tons", "lb", "in", "inch")
+	tests := map[string]struct {
+		head        string
+		completions []string
+	}{
+		"2 ki":         {"2 ", []string{"kilogram", "kilometer"}},
+		"2KI":          {"2", []string{"kilogram", "kilometer"}},
+		"2 kg in":      {"2 kg ", []string{"in", "inch"}},
+		"2 kg + 3 lo":  {"2 kg + 3 ", []string{"long ton", "long tons"}},
+		"2 long ton":   {"2 ", []string{"long ton", "long tons"}},
+		"2 kg + 3 l":   {"2 kg + 3 ", []string{"lb", "long ton", "long tons"}},
+		"2 kg in ":     {"2 kg in ", nil},
+		"2 kg in blar": {"2 kg in blar", nil},
+	}
+	for line, expected := range tests {
+		t.Run(line, func(t *testing.T) {
+			head, completions, tail := c.complete(line+" in g", len(line))
+			assert.Equal(t, expected.head, head)
+			assert.Equal(t, expected.completions, completions)
+			assert.Equal(t, " in g", tail)
+		})
+	}
+}
+
+func TestCompleterAdd(t *testing.T) {
+	c := newCompleter("lb", "kg")
+	c.add("rent", "kg", "")
+	assert.Equal(t, []string{"kg", "lb", "rent"}, c.words)
+}
+
+func TestUnitCompleter(t *testing.T) {
+	c := unitCompleter(locale.Default)
+	assert.Contains(t, c.words, "kilogram")
+	assert.Contains(t, c.words, "to")
+	assert.Contains(t, c.words, "hex")
+}
+
+func TestPlainReader(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	lines := &plainReader{reader: bufio.NewReader(strings.NewReader("2 kg\n")), writer: writer}
+	line, err := lines.readLine(" > ")
+	assert.Nil(t, err)
+	assert.Equal(t, "2 kg\n", line)
+	assert.Equal(t, " > ", writer.String())
+}
+
+func TestPromptContinues(t *testing.T) {
+	code, stdout, _ := runWith([]string{"repl"}, "2 kg \\\n+ 3 kg\n", false)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "\n >  . 5.00 kg \n\n > \n", stdout)
+}
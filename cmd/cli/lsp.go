@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nickwallen/quick-calc/internal/lsp"
+)
+
+// lsp serves an editor as a language server for calculation files, over standard input and output.
+func (c *cli) lsp(args []string) int {
+	s := c.settings
+	flags := c.flags("lsp", "", &s)
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	err := lsp.Serve(c.stdin, c.stdout,
+		lsp.WithOptions(opts...),
+		lsp.WithLocale(s.findLocale()),
+		lsp.WithPrecision(s.precision))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
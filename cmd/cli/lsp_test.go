@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// adds the headers of each message of a language server session
+func frame(messages ...string) string {
+	var b strings.Builder
+	for _, m := range messages {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	return b.String()
+}
+
+func TestRunLSP(t *testing.T) {
+	stdin := frame(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.qc","version":1,"text":"2,5 kg + 3 punds"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`)
+	code, stdout, stderr := runWith([]string{"lsp", "-locale", "es"}, stdin, false)
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	assert.Contains(t, stdout, `"serverInfo":{"name":"qcalc"}`)
+	assert.Contains(t, stdout, `"message":"'punds' no es una unidad de medida conocida"`)
+	assert.Contains(t, stdout, `{"jsonrpc":"2.0","id":2,"result":null}`)
+}
+
+func TestRunLSPWithoutShutdown(t *testing.T) {
+	code, _, stderr := runWith([]string{"lsp"}, frame(`{"jsonrpc":"2.0","method":"exit"}`), false)
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, "error: exited before a shutdown request\n", stderr)
+}
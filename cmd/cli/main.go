@@ -2,21 +2,44 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	calc "github.com/nickwallen/quick-calc"
-	"github.com/nickwallen/quick-calc/internal/io"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/locale"
 	"github.com/nickwallen/quick-calc/internal/tokenizer"
 	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/nickwallen/quick-calc/trace"
+	"io"
 	"os"
 	"strings"
 )
 
+// the modes of an evaluator
 const (
-	debugMode = "debug"
+	debugMode   = "debug"
+	explainMode = "explain"
+)
+
+// the output formats
+const (
+	plainOutput = "plain"
+	jsonOutput  = "json"
+	csvOutput   = "csv"
+	tsvOutput   = "tsv"
+)
+
+// the exit codes
+const (
+	exitOK      = 0 // every expression is valid
+	exitInvalid = 1 // an expression is not valid
+	exitFailed  = 2 // the input could not be read or the arguments are not valid
 )
 
 // used to read input from the user
 type inputReader interface {
+	Read(p []byte) (n int, err error)
 	ReadString(delimiter byte) (string, error)
 }
 
@@ -26,15 +49,10 @@ type outputWriter interface {
 }
 
 // tokenize the input string.
-func tokenize(input string, writer outputWriter) {
-	output := io.NewTokenChannel(input)
-	go tokenizer.Tokenize(input, output)
+func tokenize(input string, writer outputWriter, opts ...tokenizer.Option) {
+	lexer := tokenizer.NewLexer(input, opts...)
 	for {
-		token, err := output.ReadToken()
-		if err != nil {
-			fmt.Printf("%s \n", err)
-			return
-		}
+		token := lexer.Next()
 		fmt.Fprintf(writer, "%v  ", token)
 		if token.TokenType == types.EOF {
 			break
@@ -42,53 +60,217 @@ func tokenize(input string, writer outputWriter) {
 	}
 }
 
-// calculate the value of the input.
-func calculate(input string, writer outputWriter) {
-	result, err := calc.Calculate(input)
+// evaluates expressions and writes the results
+type evaluator struct {
+	opts      []calc.Option           // the options used to calculate each expression
+	precision int                     // the number of decimal places in a result
+	output    string                  // the output format; plain, json, csv or tsv
+	header    string                  // the output format of the table whose columns are written; empty if none
+	mode      string                  // the mode, like debug or explain; empty to just calculate
+	locale    locale.Locale           // governs how numbers are read and written
+	vars      map[string]types.Amount // the value of each variable that is assigned
+	completer *completer              // completes the names of variables once assigned; nil if not completing
+	colors    palette                 // the colors of results
+	errColors palette                 // the colors of errors
+	writer    outputWriter            // where results are written
+	errWriter outputWriter            // where errors are written
+}
+
+// options returns the options used to calculate an expression with the current settings and variables.
+func (e *evaluator) options() []calc.Option {
+	opts := append([]calc.Option(nil), e.opts...)
+	return append(opts, calc.WithPrecision(e.precision), calc.WithVariables(e.vars))
+}
+
+// lexerOptions returns the options used to tokenize an expression with the current locale and variables.
+func (e *evaluator) lexerOptions() []tokenizer.Option {
+	return []tokenizer.Option{tokenizer.WithLocale(e.locale), tokenizer.WithVariables(e.names())}
+}
+
+// tokenizerOptions returns the options used to tokenize an expression to highlight it, which continue after
+// a token that is not valid.
+func (e *evaluator) tokenizerOptions() []tokenizer.Option {
+	return append(e.lexerOptions(), tokenizer.WithRecovery())
+}
+
+// report reports every problem with an expression that is not valid.
+func (e *evaluator) report(input string, err types.InputError) {
+	fmt.Fprint(e.errWriter, report(input, problems(input, err, e.options()...), e.errColors, e.locale, e.tokenizerOptions()...))
+}
+
+// errorf reports an error, like with a command of the prompt.
+func (e *evaluator) errorf(format string, args ...interface{}) {
+	fmt.Fprintf(e.errWriter, "%s: %s\n", paint(e.errColors.err, "error"), fmt.Sprintf(format, args...))
+}
+
+// assign assigns a value to a variable.
+func (e *evaluator) assign(name string, amt types.Amount) {
+	if e.vars == nil {
+		e.vars = map[string]types.Amount{}
+	}
+	e.vars[name] = amt
+	if e.completer != nil {
+		e.completer.add(name)
+	}
+}
+
+// a result written as JSON
+type jsonResult struct {
+	Input    string       `json:"input"`              // the expression
+	Name     string       `json:"name,omitempty"`     // the variable that is assigned the result, if any
+	Result   string       `json:"result"`             // the formatted result; like '2.00 kg'
+	Value    float64      `json:"value"`              // the value or, for an interval, the lower bound
+	Upper    float64      `json:"upper,omitempty"`    // the upper bound of an interval
+	Interval bool         `json:"interval,omitempty"` // true if the result is an interval
+	Units    string       `json:"units"`              // the units of the result as written; like 'kg'
+	Unit     string       `json:"unit,omitempty"`     // the canonical name of the units; like 'kilogram'
+	Symbol   string       `json:"symbol,omitempty"`   // the symbol of the units; like 'kg'
+	Quantity string       `json:"quantity,omitempty"` // the quantity, or dimension, of the units; like 'mass'
+	Steps    []trace.Step `json:"steps,omitempty"`    // each step taken to calculate the result, when explained
+}
+
+// an error written as JSON
+type jsonError struct {
+	Input string                `json:"input"` // the expression
+	Error diagnostic.Diagnostic `json:"error"` // what is wrong with the expression
+}
+
+// calculate the value of the input; returns false if the input is not valid.
+func (e *evaluator) calculate(input string) bool {
+	ctx := context.Background()
+	var recorder *trace.Recorder
+	if e.mode == explainMode {
+		recorder = &trace.Recorder{}
+		ctx = trace.NewContext(ctx, recorder)
+	}
+	name, amt, err := calc.CalculateAssignmentContext(ctx, input, e.options()...)
+	if err == nil && name != "" {
+		e.assign(name, amt)
+	}
+	switch {
+	case e.output == jsonOutput:
+		return e.writeJSON(input, name, amt, recorder.Steps(), err)
+	case isTable(e.output):
+		return e.writeRow(input, name, amt, err)
+	}
+	for i, step := range recorder.Steps() {
+		fmt.Fprintf(e.writer, "  %d. %s\n", i+1, step)
+	}
 	if err != nil {
-		fmt.Fprintf(writer, "%s \n", printError(err))
-		return
+		e.report(input, err)
+		return false
 	}
-	fmt.Fprintf(writer, "%s \n", result)
+	fmt.Fprintf(e.writer, "%s \n", e.colors.highlight(calc.Format(amt, e.options()...), e.tokenizerOptions()...))
+	return true
 }
 
-// prompt the user for input
-func prompt(reader inputReader, writer outputWriter, mode string) {
-	// prompt for input
-	fmt.Fprintf(writer, "\n > ")
-	input, _ := reader.ReadString('\n')
+// writeJSON writes the result, or the error, of an expression as a line of JSON; returns false if there is an error.
+func (e *evaluator) writeJSON(input, name string, amt types.Amount, steps []trace.Step, err types.InputError) bool {
+	input = strings.TrimRight(input, "\r\n")
+	encoder := json.NewEncoder(e.writer)
+	if err != nil {
+		encoder.Encode(jsonError{Input: input, Error: diagnostic.Translate(err, e.locale)})
+		return false
+	}
+	unit := findUnit(amt.Units.Value, e.options()...)
+	encoder.Encode(jsonResult{
+		Input:    input,
+		Name:     name,
+		Result:   calc.Format(amt, e.options()...),
+		Value:    amt.Value,
+		Upper:    amt.Upper,
+		Interval: amt.Interval,
+		Units:    amt.Units.Value,
+		Unit:     unit.Name,
+		Symbol:   unit.Symbol,
+		Quantity: unit.Quantity,
+		Steps:    steps,
+	})
+	return true
+}
 
-	// start-up either the calculator or the tokenizer
-	switch mode {
-	case debugMode:
+// evaluate the input in the mode of the evaluator; returns false if the input is not valid.
+func (e *evaluator) evaluate(input string) bool {
+	if e.mode == debugMode {
 		// output just the tokens
-		tokenize(input, writer)
-	default:
-		// evaluate each expression
-		calculate(input, writer)
+		tokenize(input, e.writer, e.lexerOptions()...)
+		fmt.Fprintln(e.writer)
+		return true
 	}
+	return e.calculate(input)
 }
 
-func printError(error types.InputError) string {
-	template := `
-error: %s at position %d
-  |
-  | %s
-  | %s
-`
-	start, width := error.Position()
-	input := strings.TrimRight(error.Input(), "\n")
-	errorMarker := strings.Repeat(" ", start-1) + strings.Repeat("^", width)
-	return fmt.Sprintf(template, error.Error(), start, input, errorMarker)
+// prompt the user for input; returns an error, like io.EOF, once no more input can be read. A line that ends
+// with '\\' continues on the next line.
+func (e *evaluator) prompt(lines lineReader) error {
+	fmt.Fprintf(e.writer, "\n")
+	input, err := lines.readLine(" > ")
+	for err == nil && strings.HasSuffix(strings.TrimRight(input, "\r\n"), "\\") {
+		var next string
+		next, err = lines.readLine(" . ")
+		input = strings.TrimSuffix(strings.TrimRight(input, "\r\n"), "\\") + " " + next
+	}
+	switch trimmed := strings.TrimSpace(input); {
+	case strings.HasPrefix(trimmed, ":"):
+		e.meta(trimmed)
+	case trimmed != "":
+		e.evaluate(input)
+	}
+	return err
 }
 
-func main() {
-	mode := ""
-	if len(os.Args) > 1 {
-		mode = os.Args[1]
+// repl prompts the user for input until there is no more.
+func (e *evaluator) repl(lines lineReader) {
+	for e.prompt(lines) == nil {
+		// keep prompting
 	}
-	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprintln(e.writer)
+}
+
+// batch calls a function with each non-blank line of input, without prompting, and returns the exit code.
+func batch(reader inputReader, fn func(input string) bool, errWriter outputWriter) int {
+	code := exitOK
 	for {
-		prompt(reader, os.Stdout, mode)
+		line, err := reader.ReadString('\n')
+		if strings.TrimSpace(line) != "" && !fn(line) {
+			code = exitInvalid
+		}
+		if err == io.EOF {
+			return code
+		}
+		if err != nil {
+			fmt.Fprintf(errWriter, "error: %s\n", err)
+			return exitFailed
+		}
 	}
 }
+
+// run runs the command line interface with the settings of the config file and returns the exit code.
+func run(args []string, c *cli) int {
+	settings, err := loadSettings(configPath())
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	c.settings = settings
+	return c.run(args)
+}
+
+// isTerminal returns true if a file is a terminal, rather than a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func main() {
+	interactive := isTerminal(os.Stdin)
+	os.Exit(run(os.Args[1:], &cli{
+		stdin:       bufio.NewReader(os.Stdin),
+		interactive: interactive,
+		edit:        interactive && isTerminal(os.Stdout),
+		terminal:    isTerminal(os.Stdout),
+		errTerminal: isTerminal(os.Stderr),
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}))
+}
@@ -1,15 +1,32 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/locale"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
+// ignore any config file of the user
+func TestMain(m *testing.M) {
+	os.Setenv("QCALC_CONFIG", "")
+	os.Exit(m.Run())
+}
+
+// returns an evaluator that writes both results and errors to a writer
+func newEvaluator(writer outputWriter) *evaluator {
+	return &evaluator{precision: calc.DefaultPrecision, locale: locale.Default, writer: writer, errWriter: writer}
+}
+
 func TestCalculate(t *testing.T) {
 	writer := bytes.NewBufferString("")
-	calculate("23 kg + 23 kg", writer)
+	newEvaluator(writer).calculate("23 kg + 23 kg")
 	assert.Equal(t, "46.00 kg \n", writer.String())
 }
 
@@ -21,34 +38,91 @@ func TestTokenize(t *testing.T) {
 
 var badExpressions = map[string]string{
 	"32 googles": `
-error: 'googles' is not a known measurement unit at position 4
+error[QC1001]: 'googles' is not a known measurement unit
+ --> position 4
   |
   | 32 googles
-  |    ^^^^^^^
+  |    ^^^^^^^ unknown unit
 	`,
-	"2 miles / 500 feet": `
-error: got '/', but expected '+', '-', 'in' at position 9
+	"2 kg * 3 kg": `
+error[QC1005]: cannot multiply kg by kg
+ --> position 10
   |
-  | 2 miles / 500 feet
-  |         ^
+  | 2 kg * 3 kg
+  |          ^^ incompatible factors
 	`,
 	"2 miles + 3 pounds": `
-error: cannot convert from pounds to miles at position 13
+error[QC1003]: cannot convert from pounds to miles
+ --> position 13
   |
   | 2 miles + 3 pounds
-  |             ^^^^^^
+  |             ^^^^^^ incompatible units
 `,
 	"pounds": `
-error: got 'pounds', but expected a number at position 1
+error[QC2001]: got 'pounds', but expected a number
+ --> position 1
   |
   | pounds
-  | ^^^^^^
+  | ^^^^^^ unexpected token
 	`,
+	"2 °F in yds + 2": `
+error[QC2001]: got '+', but expected end of input
+ --> position 13
+  |
+  | 2 °F in yds + 2
+  |             ^ unexpected token
+
+error[QC1003]: cannot convert from °F to yds
+ --> position 3
+  |
+  | 2 °F in yds + 2
+  |   ^^ incompatible units
+
+error: found 2 problems
+`,
+	"2 kilograms in punds": `
+error[QC1001]: 'punds' is not a known measurement unit
+ --> position 16
+  |
+  | 2 kilograms in punds
+  |                ^^^^^ unknown unit
+  |
+  = help: did you mean 'pounds', 'pints'?
+`,
+	"2 t in kg": `
+error[QC1002]: 't' is ambiguous; it could be tonne (metric), short ton (us), ton (imperial)
+ --> position 3
+  |
+  | 2 t in kg
+  |   ^ ambiguous unit
+  |
+  = help: did you mean 'tonne', 'short ton', 'ton'?
+`,
 	"22": `
-error: reached end of input, but expected a unit at position 3
+error[QC2002]: reached end of input, but expected a unit
+ --> position 3
   |
   | 22
-  |   ^
+  |   ^ unexpected end
+`,
+	"2 kgs + 3 punds in mlies": `
+error[QC1001]: 'punds' is not a known measurement unit
+ --> position 11
+  |
+  | 2 kgs + 3 punds in mlies
+  |           ^^^^^ unknown unit
+  |
+  = help: did you mean 'pounds', 'pints'?
+
+error[QC1001]: 'mlies' is not a known measurement unit
+ --> position 20
+  |
+  | 2 kgs + 3 punds in mlies
+  |                    ^^^^^ unknown unit
+  |
+  = help: did you mean 'miles'?
+
+error: found 2 problems
 `,
 }
 
@@ -56,8 +130,129 @@ func TestPrintError(t *testing.T) {
 	for expr, expectedErr := range badExpressions {
 		t.Run(expr, func(t *testing.T) {
 			writer := bytes.NewBufferString("")
-			calculate(expr, writer)
+			newEvaluator(writer).calculate(expr)
 			assert.Equal(t, strings.TrimSpace(expectedErr), strings.TrimSpace(writer.String()))
 		})
 	}
 }
+
+func TestPrintErrorLocale(t *testing.T) {
+	code, _, stderr := runWith([]string{"-locale", "de", "2 kg + 3 punds nach mlies"}, "", false)
+	assert.Equal(t, exitInvalid, code)
+	expected := `
+error[QC1001]: 'punds' ist keine bekannte Maßeinheit
+ --> position 10
+  |
+  | 2 kg + 3 punds nach mlies
+  |          ^^^^^ unknown unit
+  |
+  = Hilfe: meintest du 'pounds', 'pints'?
+
+error[QC1001]: 'mlies' ist keine bekannte Maßeinheit
+ --> position 21
+  |
+  | 2 kg + 3 punds nach mlies
+  |                     ^^^^^ unknown unit
+  |
+  = Hilfe: meintest du 'miles'?
+
+error: 2 Probleme gefunden
+`
+	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(stderr))
+}
+
+func TestExplain(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	e := newEvaluator(writer)
+	e.mode = explainMode
+	e.calculate("2 kg + 3 lbs")
+	expected := "  1. kg is used; the units of the left operand 2.00 kg\n" +
+		"  2. 3.00 lbs → 1.36 kg (× 0.453592)\n" +
+		"  3. 2.00 kg + 1.36 kg = 3.36 kg\n" +
+		"3.36 kg \n"
+	assert.Equal(t, expected, writer.String())
+}
+
+func TestExplainError(t *testing.T) {
+	writer := bytes.NewBufferString("")
+	e := newEvaluator(writer)
+	e.mode = explainMode
+	e.calculate("2 kg + 3 miles")
+	assert.Contains(t, writer.String(), "  1. kg is used; the units of the left operand 2.00 kg\n")
+	assert.Contains(t, writer.String(), "error[QC1003]: cannot convert from miles to kg\n --> position 10")
+}
+
+// runs the command line interface with the given arguments and standard input
+func runWith(args []string, stdin string, interactive bool) (code int, stdout string, stderr string) {
+	out := bytes.NewBufferString("")
+	errOut := bytes.NewBufferString("")
+	code = run(args, &cli{stdin: bufio.NewReader(strings.NewReader(stdin)), interactive: interactive, stdout: out, stderr: errOut})
+	return code, out.String(), errOut.String()
+}
+
+func TestRunArguments(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"2", "kg", "in", "g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2000.00 g \n", stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRunArgumentsInvalid(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"2 kg in punds"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "error[QC1001]: 'punds' is not a known measurement unit")
+}
+
+func TestRunArgumentsWithCommand(t *testing.T) {
+	code, stdout, _ := runWith([]string{"tokens", "2 + 2"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NUM[2]  SYM[+]  NUM[2]  EOF  \n", stdout)
+}
+
+func TestRunNegativeArgument(t *testing.T) {
+	code, stdout, _ := runWith([]string{"--", "-2 kg + 3 kg"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "1.00 kg \n", stdout)
+}
+
+func TestRunBatch(t *testing.T) {
+	code, stdout, stderr := runWith(nil, "2 kg in g\n\n3 kg + 2 kg", false)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2000.00 g \n5.00 kg \n", stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRunBatchInvalid(t *testing.T) {
+	code, stdout, stderr := runWith(nil, "2 kg in g\n2 kg in punds\n3 kg + 2 kg\n", false)
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, "2000.00 g \n5.00 kg \n", stdout)
+	assert.Contains(t, stderr, "'punds' is not a known measurement unit")
+}
+
+func TestRunFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "input.txt")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("2 kg in g\n3 kg + 2 kg\n"), 0600))
+	code, stdout, _ := runWith([]string{"-f", file}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2000.00 g \n5.00 kg \n", stdout)
+}
+
+func TestRunFileMissing(t *testing.T) {
+	code, _, stderr := runWith([]string{"-f", filepath.Join(t.TempDir(), "missing.txt")}, "", true)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "error: open")
+}
+
+func TestRunBadFlag(t *testing.T) {
+	code, _, stderr := runWith([]string{"-x"}, "", true)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "Usage:")
+}
+
+func TestRunInteractive(t *testing.T) {
+	// the prompt ends cleanly at the end of the input
+	code, stdout, _ := runWith(nil, "2 kg in g\n\n3 kg + 2 kg", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "\n > 2000.00 g \n\n > \n > 5.00 kg \n\n", stdout)
+}
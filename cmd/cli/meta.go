@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// a command of the prompt like ':help'
+type metaCommand struct {
+	name    string                            // the name of the command, after the colon
+	args    string                            // describes the arguments of the command
+	summary string                            // describes what the command does
+	run     func(e *evaluator, args []string) // runs the command
+}
+
+// the commands of the prompt, in the order they are listed
+var metaCommands []metaCommand
+
+func init() {
+	metaCommands = []metaCommand{
+		{"help", "", "show the commands of the prompt", (*evaluator).help},
+		{"units", "[quantity] [search]", "list the quantities, or the units of a quantity like mass", (*evaluator).units},
+		{"vars", "[clear]", "show the variables, or clear them", (*evaluator).variables},
+		{"set", "[precision n | format plain|json|csv|tsv]", "show the settings, or change one", (*evaluator).set},
+		{"debug", "", "show the tokens of each expression rather than its value, or stop showing them", (*evaluator).debug},
+		{"save", "file", "save the variables to a file", (*evaluator).save},
+		{"load", "file", "load the variables of a file", (*evaluator).load},
+	}
+}
+
+// meta runs a command of the prompt like ':units mass'.
+func (e *evaluator) meta(input string) {
+	fields := strings.Fields(strings.TrimPrefix(input, ":"))
+	if len(fields) == 0 {
+		e.help(nil)
+		return
+	}
+	for _, cmd := range metaCommands {
+		if cmd.name == fields[0] {
+			cmd.run(e, fields[1:])
+			return
+		}
+	}
+	e.errorf("':%s' is not a command; see :help", fields[0])
+}
+
+// help shows the commands of the prompt.
+func (e *evaluator) help(args []string) {
+	w := tabwriter.NewWriter(e.writer, 0, 0, 3, ' ', 0)
+	for _, cmd := range metaCommands {
+		fmt.Fprintf(w, "  :%s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(e.writer, "Assign the value of an expression to a variable like 'rent = 1200 kg' and use it like 'rent in lbs'.")
+}
+
+// units lists the quantities that units measure or, given a quantity or search term, the units.
+func (e *evaluator) units(args []string) {
+	if len(args) == 0 {
+		e.quantities()
+		return
+	}
+	quantity := ""
+	if isQuantity(args[0]) {
+		quantity, args = args[0], args[1:]
+	}
+	if !listUnits(e.writer, quantity, strings.Join(args, " "), e.output) {
+		e.errorf("no units found")
+	}
+}
+
+// quantities lists each quantity that units measure, and the number of units of each.
+func (e *evaluator) quantities() {
+	var names []string
+	counts := map[string]int{}
+	for _, unit := range calc.Units() {
+		if counts[unit.Quantity] == 0 {
+			names = append(names, unit.Quantity)
+		}
+		counts[unit.Quantity]++
+	}
+	w := tabwriter.NewWriter(e.writer, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%d units\n", name, counts[name])
+	}
+	w.Flush()
+}
+
+// isQuantity returns true if units measure a quantity like 'mass'.
+func isQuantity(name string) bool {
+	for _, unit := range calc.Units() {
+		if unit.Quantity == name {
+			return true
+		}
+	}
+	return false
+}
+
+// variables shows the value of each variable, or clears them.
+func (e *evaluator) variables(args []string) {
+	if len(args) > 0 && args[0] == "clear" {
+		e.vars = map[string]types.Amount{}
+		return
+	}
+	if len(e.vars) == 0 {
+		fmt.Fprintln(e.writer, "there are no variables; assign one like 'rent = 1200 kg'")
+		return
+	}
+	w := tabwriter.NewWriter(e.writer, 0, 0, 1, ' ', 0)
+	for _, name := range e.names() {
+		fmt.Fprintf(w, "  %s\t= %s\n", name, calc.Format(e.vars[name], e.options()...))
+	}
+	w.Flush()
+}
+
+// names returns the names of the variables in order.
+func (e *evaluator) names() []string {
+	names := make([]string, 0, len(e.vars))
+	for name := range e.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// set shows the settings or changes one; like ':set precision 4'.
+func (e *evaluator) set(args []string) {
+	switch {
+	case len(args) == 0:
+		fmt.Fprintf(e.writer, "  precision %d\n  format %s\n", e.precision, e.output)
+	case args[0] == "precision" && len(args) == 2:
+		precision, err := strconv.Atoi(args[1])
+		if err != nil || precision < 0 {
+			e.errorf("'%s' is not a valid precision", args[1])
+			return
+		}
+		e.precision = precision
+	case args[0] == "format" && len(args) == 2:
+		if err := validOutput(args[1]); err != nil {
+			e.errorf("%s", err)
+			return
+		}
+		e.output = args[1]
+	default:
+		e.errorf("expected ':set precision n' or ':set format plain|json|csv|tsv'")
+	}
+}
+
+// debug starts, or stops, showing the tokens of each expression rather than its value.
+func (e *evaluator) debug(args []string) {
+	if e.mode == debugMode {
+		e.mode = ""
+		fmt.Fprintln(e.writer, "debug is off")
+		return
+	}
+	e.mode = debugMode
+	fmt.Fprintln(e.writer, "debug is on; the tokens of each expression are shown")
+}
+
+// the names of the radixes that an amount can be displayed in, as written in a definition
+var radixNames = map[int]string{2: "bin", 8: "oct", 16: "hex"}
+
+// definition returns the definition of a variable, that can be evaluated to assign it again; like 'rent = 1200 kg'.
+// The value is written with as many digits as can be read back.
+func (e *evaluator) definition(name string, amt types.Amount) string {
+	number := func(value float64) string {
+		return strings.Replace(strconv.FormatFloat(value, 'g', calc.DefaultMaxDigits, 64), ".", string(e.locale.Decimal), 1)
+	}
+	value := number(amt.Value)
+	if amt.Interval {
+		value += ".." + number(amt.Upper)
+	}
+	definition := fmt.Sprintf("%s = %s %s", name, value, amt.Units.Value)
+	if radix, ok := radixNames[amt.Radix]; ok {
+		definition += " in " + radix
+	}
+	return definition
+}
+
+// save saves the definition of each variable to a file.
+func (e *evaluator) save(args []string) {
+	if len(args) != 1 {
+		e.errorf("expected ':save file'")
+		return
+	}
+	var b strings.Builder
+	b.WriteString("# variables saved by qcalc; load them with ':load file'\n")
+	for _, name := range e.names() {
+		b.WriteString(e.definition(name, e.vars[name]) + "\n")
+	}
+	if err := ioutil.WriteFile(args[0], []byte(b.String()), 0644); err != nil {
+		e.errorf("%s", err)
+		return
+	}
+	fmt.Fprintf(e.writer, "saved %d variables to %s\n", len(e.vars), args[0])
+}
+
+// load assigns the variables defined in a file, like one saved by ':save'. Blank lines and comments that start
+// with '#' are ignored.
+func (e *evaluator) load(args []string) {
+	if len(args) != 1 {
+		e.errorf("expected ':load file'")
+		return
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		e.errorf("%s", err)
+		return
+	}
+	defer f.Close()
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, amt, err := calc.CalculateAssignment(line, e.options()...)
+		if err != nil {
+			e.report(line, err)
+			continue
+		}
+		if name != "" {
+			e.assign(name, amt)
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		e.errorf("%s", err)
+	}
+	fmt.Fprintf(e.writer, "loaded %d variables from %s\n", count, args[0])
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// runs each line at the prompt and returns what is written
+func runMeta(e *evaluator, lines ...string) string {
+	writer := e.writer.(*bytes.Buffer)
+	writer.Reset()
+	for _, line := range lines {
+		if line[0] == ':' {
+			e.meta(line)
+		} else {
+			e.evaluate(line)
+		}
+	}
+	return writer.String()
+}
+
+func TestMetaHelp(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	output := runMeta(e, ":help")
+	for _, cmd := range metaCommands {
+		assert.Contains(t, output, ":"+cmd.name)
+	}
+}
+
+func TestMetaUnknown(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	assert.Equal(t, "error: ':bogus' is not a command; see :help\n", runMeta(e, ":bogus"))
+}
+
+func TestMetaUnits(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	assert.Regexp(t, `(?m)^  mass +\d+ units$`, runMeta(e, ":units"))
+	assert.Contains(t, runMeta(e, ":units mass pound"), "pound  lb  mass")
+	assert.NotContains(t, runMeta(e, ":units mass"), "meter")
+	assert.Contains(t, runMeta(e, ":units kilogram"), "kilogram")
+	assert.Equal(t, "error: no units found\n", runMeta(e, ":units length pound"))
+}
+
+func TestMetaVars(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	assert.Equal(t, "there are no variables; assign one like 'rent = 1200 kg'\n", runMeta(e, ":vars"))
+	assert.Equal(t, "2.00 kg \n1.00 m \n", runMeta(e, "weight = 2 kg", "height = 1 m"))
+	assert.Equal(t, "  height = 1.00 m\n  weight = 2.00 kg\n", runMeta(e, ":vars"))
+	assert.Equal(t, "4413.65 lb \n", runMeta(e, "weight + 2 tonne in lb"))
+
+	runMeta(e, ":vars clear")
+	assert.Empty(t, e.vars)
+	assert.Contains(t, runMeta(e, "weight in lb"), "error[QC2001]: got 'weight', but expected a number")
+}
+
+func TestMetaSet(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	e.output = plainOutput
+	assert.Equal(t, "  precision 2\n  format plain\n", runMeta(e, ":set"))
+	assert.Equal(t, "1.0000 kg \n", runMeta(e, ":set precision 4", "1 kg"))
+	assert.Equal(t, "{\"input\":\"1 kg\",\"result\":\"1.0000 kg\",\"value\":1,\"units\":\"kg\",\"unit\":\"kilogram\",\"symbol\":\"kg\",\"quantity\":\"mass\"}\n", runMeta(e, ":set format json", "1 kg"))
+}
+
+func TestMetaSetInvalid(t *testing.T) {
+	tests := map[string]string{
+		":set precision -1":  "error: '-1' is not a valid precision\n",
+		":set precision two": "error: 'two' is not a valid precision\n",
+		":set format xml":    "error: 'xml' is not an output format; expected plain, json, csv or tsv\n",
+		":set colour blue":   "error: expected ':set precision n' or ':set format plain|json|csv|tsv'\n",
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			e := newEvaluator(bytes.NewBufferString(""))
+			assert.Equal(t, expected, runMeta(e, input))
+			assert.Equal(t, 2, e.precision)
+		})
+	}
+}
+
+func TestMetaDebug(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	assert.Equal(t, "debug is on; the tokens of each expression are shown\nNUM[2]  UNI[kg]  EOF  \n", runMeta(e, ":debug", "2 kg"))
+	assert.Equal(t, "debug is off\n2.00 kg \n", runMeta(e, ":debug", "2 kg"))
+}
+
+func TestMetaSaveAndLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.txt")
+	e := newEvaluator(bytes.NewBufferString(""))
+	runMeta(e, "weight = 2.125 kg", "span = 2..3 m", "mask = 255 bytes in hex")
+	assert.Equal(t, "saved 3 variables to "+file+"\n", runMeta(e, ":save "+file))
+
+	saved, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Contains(t, string(saved), "weight = 2.125 kg\n")
+	assert.Contains(t, string(saved), "span = 2..3 m\n")
+	assert.Contains(t, string(saved), "mask = 255 bytes in hex\n")
+
+	loaded := newEvaluator(bytes.NewBufferString(""))
+	assert.Equal(t, "loaded 3 variables from "+file+"\n", runMeta(loaded, ":load "+file))
+	assert.Equal(t, e.vars["weight"].Value, loaded.vars["weight"].Value)
+	assert.Equal(t, e.vars["span"].Upper, loaded.vars["span"].Upper)
+	assert.Equal(t, runMeta(e, ":vars"), runMeta(loaded, ":vars"))
+}
+
+func TestMetaLoadInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.txt")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("# a comment\n\nweight = 2 kg\nheight = 2 punds\n"), 0600))
+	e := newEvaluator(bytes.NewBufferString(""))
+	output := runMeta(e, ":load "+file)
+	assert.Contains(t, output, "'punds' is not a known measurement unit")
+	assert.Contains(t, output, "loaded 1 variables from "+file)
+	assert.Contains(t, e.vars, "weight")
+
+	assert.Contains(t, runMeta(e, ":load "+filepath.Join(t.TempDir(), "missing.txt")), "error: open")
+	assert.Equal(t, "error: expected ':load file'\n", runMeta(e, ":load"))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"strconv"
+	"strings"
+)
+
+// the output formats, in the order they are listed
+var outputs = []string{plainOutput, jsonOutput, csvOutput, tsvOutput}
+
+// validOutput returns an error if an output format is not known.
+func validOutput(output string) error {
+	for _, known := range outputs {
+		if output == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not an output format; expected plain, json, csv or tsv", output)
+}
+
+// isTable returns true if an output format is a table with a row for each result; csv or tsv.
+func isTable(output string) bool {
+	return output == csvOutput || output == tsvOutput
+}
+
+// newTable returns a writer of rows of comma separated values or, for tsv, tab separated values.
+func newTable(writer outputWriter, output string) *csv.Writer {
+	table := csv.NewWriter(writer)
+	if output == tsvOutput {
+		table.Comma = '\t'
+	}
+	return table
+}
+
+// findUnit returns the canonical description of the units of a result; empty if the units are not known, like
+// units that are ambiguous.
+func findUnit(units string, opts ...calc.Option) calc.Unit {
+	unit, err := calc.FindUnit(units, opts...)
+	if err != nil {
+		return calc.Unit{}
+	}
+	return unit
+}
+
+// formatValue formats a value with as many digits as needed to read it back exactly.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// the columns of a table of results
+var resultColumns = []string{
+	"input", "name", "result", "value", "upper", "units", "unit", "symbol", "quantity", "code", "error", "position", "width",
+}
+
+// writeRow writes the result, or the error, of an expression as a row of a table; returns false if there is an
+// error. The columns are written as the first row.
+func (e *evaluator) writeRow(input, name string, amt types.Amount, err types.InputError) bool {
+	table := newTable(e.writer, e.output)
+	defer table.Flush()
+	if e.header != e.output {
+		table.Write(resultColumns)
+		e.header = e.output
+	}
+	input = strings.TrimRight(input, "\r\n")
+	if err != nil {
+		d := diagnostic.Translate(err, e.locale)
+		table.Write([]string{input, "", "", "", "", "", "", "", "", string(d.Code), d.Message, strconv.Itoa(d.Start), strconv.Itoa(d.Width)})
+		return false
+	}
+	upper := ""
+	if amt.Interval {
+		upper = formatValue(amt.Upper)
+	}
+	unit := findUnit(amt.Units.Value, e.options()...)
+	table.Write([]string{
+		input, name, calc.Format(amt, e.options()...), formatValue(amt.Value), upper,
+		amt.Units.Value, unit.Name, unit.Symbol, unit.Quantity, "", "", "", "",
+	})
+	return true
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidOutput(t *testing.T) {
+	for _, output := range outputs {
+		assert.Nil(t, validOutput(output))
+	}
+	assert.EqualError(t, validOutput("xml"), "'xml' is not an output format; expected plain, json, csv or tsv")
+}
+
+func TestRunEvalCSV(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"eval", "-output", "csv"}, "1 lb in g\n2..3 m\n3 kg in punds\n", false)
+	assert.Equal(t, exitInvalid, code)
+	expected := "input,name,result,value,upper,units,unit,symbol,quantity,code,error,position,width\n" +
+		"1 lb in g,,453.59 g,453.59237,,g,gram,g,mass,,,,\n" +
+		"2..3 m,,2.00..3.00 m,2,3,m,meter,m,length,,,,\n" +
+		"3 kg in punds,,,,,,,,,QC1001,'punds' is not a known measurement unit,9,5\n"
+	assert.Equal(t, expected, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRunEvalCSVLocale(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-output", "csv", "-locale", "de", "3 kg nach punds"}, "", true)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, ",QC1001,'punds' ist keine bekannte Maßeinheit,11,5\n")
+}
+
+func TestRunEvalTSV(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-output", "tsv", "1 kg + 1 g"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := "input\tname\tresult\tvalue\tupper\tunits\tunit\tsymbol\tquantity\tcode\terror\tposition\twidth\n" +
+		"1 kg + 1 g\t\t1.00 kg\t1.001\t\tkg\tkilogram\tkg\tmass\t\t\t\t\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunEvalJSONFullPrecision(t *testing.T) {
+	code, stdout, _ := runWith([]string{"eval", "-output", "json", "2 kg + 3 lbs in pounds"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := `{"input":"2 kg + 3 lbs in pounds","result":"7.41 pounds","value":7.409245243697551,"units":"pounds",` +
+		`"unit":"pound","symbol":"lb","quantity":"mass"}` + "\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestMetaSetTable(t *testing.T) {
+	e := newEvaluator(bytes.NewBufferString(""))
+	output := runMeta(e, ":set format csv", "rent = 2 kg", "rent in g")
+	expected := "input,name,result,value,upper,units,unit,symbol,quantity,code,error,position,width\n" +
+		"rent = 2 kg,rent,2.00 kg,2,,kg,kilogram,kg,mass,,,,\n" +
+		"rent in g,,2000.00 g,2000,,g,gram,g,mass,,,,\n"
+	assert.Equal(t, expected, output)
+}
+
+func TestRunTokensCSV(t *testing.T) {
+	code, stdout, _ := runWith([]string{"tokens", "-output", "csv", "2 kg"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "input,type,value,position\n2 kg,number,2,1\n2 kg,units,kg,3\n2 kg,eof,,5\n", stdout)
+}
+
+func TestRunAstTSV(t *testing.T) {
+	code, stdout, _ := runWith([]string{"ast", "-output", "tsv", "2 kg + 3 lbs"}, "", true)
+	assert.Equal(t, exitOK, code)
+	expected := "input\tdepth\tkind\tstart\twidth\ttext\n" +
+		"2 kg + 3 lbs\t0\taddition\t1\t12\t2 kg + 3 lbs\n" +
+		"2 kg + 3 lbs\t1\tvalue\t1\t4\t2 kg\n" +
+		"2 kg + 3 lbs\t1\tvalue\t8\t5\t3 lbs\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunUnitsCSV(t *testing.T) {
+	code, stdout, _ := runWith([]string{"units", "-output", "csv", "-quantity", "mass", "kilogram"}, "", true)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "name,symbol,plural,quantity,system,aliases\n")
+	assert.Contains(t, stdout, "kilogram,kg,kilograms,mass,metric,")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"strings"
+	"unicode/utf8"
+)
+
+// problems returns every problem with an expression that is not valid, starting with the error that stopped it.
+func problems(input string, err types.InputError, opts ...calc.Option) []types.InputError {
+	errs := []types.InputError{err}
+	for _, other := range calc.Diagnose(input, opts...) {
+		if !sameProblem(other, err) {
+			errs = append(errs, other)
+		}
+	}
+	return errs
+}
+
+// sameProblem returns true if two errors describe the same problem.
+func sameProblem(a, b types.InputError) bool {
+	aStart, aWidth := a.Position()
+	bStart, bWidth := b.Position()
+	return a.Code() == b.Code() && aStart == bStart && aWidth == bWidth
+}
+
+// report describes each problem with an expression, with the span of the expression that each underlines and
+// any suggestions, in the language of a locale; like
+//
+//	error[QC1001]: 'punds' is not a known measurement unit
+//	 --> position 6
+//	  |
+//	  | 2 kg punds
+//	  |      ^^^^^ unknown unit
+//	  |
+//	  = help: did you mean 'pounds', 'pints'?
+func report(input string, errs []types.InputError, p palette, loc locale.Locale, opts ...tokenizer.Option) string {
+	input = strings.TrimRight(input, "\r\n")
+	highlighted := p.highlight(input, opts...)
+	var b strings.Builder
+	for _, err := range errs {
+		style, severity := p.err, "error"
+		if err.Code().Severity() == types.SeverityWarning {
+			style, severity = p.warning, "warning"
+		}
+		start, width := err.Position()
+		column, span := columns(input, start, width)
+		if span < 1 {
+			span = 1
+		}
+		gutter := paint(p.gutter, "  |")
+		fmt.Fprintf(&b, "\n%s%s\n", paint(style, fmt.Sprintf("%s[%s]", severity, string(err.Code()))), paint(p.emphasis, ": "+err.Translate(loc)))
+		fmt.Fprintf(&b, "%s position %d\n", paint(p.gutter, " -->"), column)
+		fmt.Fprintf(&b, "%s\n%s %s\n", gutter, gutter, highlighted)
+		marker := strings.Repeat("^", span) + " " + strings.ReplaceAll(err.Code().Name(), "-", " ")
+		fmt.Fprintf(&b, "%s %s%s\n", gutter, strings.Repeat(" ", column-1), paint(style, marker))
+		if suggestions := printSuggestions(err, loc); suggestions != "" {
+			fmt.Fprintf(&b, "%s\n%s %s %s\n", gutter, paint(p.gutter, "  ="), paint(p.emphasis, loc.Translate("help:")), paint(p.help, suggestions))
+		}
+	}
+	if len(errs) > 1 {
+		fmt.Fprintf(&b, "\n%s%s\n", paint(p.err, "error"), paint(p.emphasis, ": "+loc.Sprintf("found %d problems", len(errs))))
+	}
+	return b.String()
+}
+
+// printSuggestions prints the suggested alternatives for an error, if there are any, in the language of a locale.
+func printSuggestions(error types.InputError, loc locale.Locale) string {
+	suggestions := error.Suggestions()
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = fmt.Sprintf("'%s'", suggestion)
+	}
+	return loc.Sprintf("did you mean %s?", strings.Join(quoted, ", "))
+}
+
+// columns converts the byte position and width of an error into a column and span of characters.
+func columns(input string, start, width int) (column, span int) {
+	if start < 1 || start-1+width > len(input) {
+		return start, width
+	}
+	column = utf8.RuneCountInString(input[:start-1]) + 1
+	span = utf8.RuneCountInString(input[start-1 : start-1+width])
+	return column, span
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/nickwallen/quick-calc/server"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// how long requests that are in progress are given to finish once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// serve serves calculations over HTTP until interrupted.
+func (c *cli) serve(args []string) int {
+	s := c.settings
+	flags := c.flags("serve", "", &s)
+	addr := flags.String("addr", "localhost:8080", "the `address` to listen on")
+	maxBody := flags.Int64("max-body", server.DefaultMaxBodySize, "the maximum number of `bytes` in a request; 0 if unlimited")
+	maxBatch := flags.Int("max-batch", server.DefaultMaxBatch, "the maximum number of `expressions` in a batch; 0 if unlimited")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	handler := server.New(
+		server.WithOptions(opts...),
+		server.WithLocale(s.findLocale()),
+		server.WithPrecision(s.precision),
+		server.WithMaxBodySize(*maxBody),
+		server.WithMaxBatch(*maxBatch),
+	)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	done := make(chan struct{})
+	go func() {
+		<-interrupt
+		close(done)
+	}()
+	fmt.Fprintf(c.stderr, "serving calculations on http://%s\n", listener.Addr())
+	if err := runServer(handler, listener, done); err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runServer serves requests on a listener until done, and then waits for the requests in progress to finish.
+func runServer(handler http.Handler, listener net.Listener, done <-chan struct{}) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	failed := make(chan error, 1)
+	go func() {
+		failed <- srv.Serve(listener)
+	}()
+	select {
+	case err := <-failed:
+		return err
+	case <-done:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/nickwallen/quick-calc/server"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- runServer(server.New(), listener, done)
+	}()
+
+	resp, err := http.Post("http://"+listener.Addr().String()+"/v1/eval", "application/json", strings.NewReader(`{"expression":"2 kg in g"}`))
+	if assert.Nil(t, err) {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), `"result":"2000.00 g"`)
+	}
+
+	close(done)
+	select {
+	case err := <-stopped:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not stop")
+	}
+}
+
+func TestRunServerFinishesRequests(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	started := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("finished"))
+	})
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- runServer(slow, listener, done)
+	}()
+
+	// the server is asked to stop while a request is in progress
+	go func() {
+		<-started
+		close(done)
+	}()
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	if assert.Nil(t, err) {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "finished", string(body))
+	}
+	assert.Nil(t, <-stopped)
+}
+
+func TestRunServeBadAddress(t *testing.T) {
+	code, _, stderr := runWith([]string{"serve", "-addr", "localhost:-1"}, "", false)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "error: ")
+}
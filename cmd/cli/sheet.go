@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mattn/go-runewidth"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/sheet"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// how often a sheet that is watched is checked for changes
+const watchInterval = 500 * time.Millisecond
+
+// a line of a sheet and the result of its expression
+type sheetLine struct {
+	text    string // the line as written
+	expr    string // the expression on the line, without any comment; empty if there is none
+	comment string // the comment that follows the expression, if any; like '# per month'
+	result  string // the result of the expression, or what is wrong with it
+	failed  bool   // true if the expression is not valid
+}
+
+// sheet evaluates each line of a file, in order, with the variables assigned by the lines above it and shows each
+// result in a column to the right of the lines.
+func (c *cli) sheet(args []string) int {
+	s := c.settings
+	flags := c.flags("sheet", "file", &s)
+	watch := flags.Bool("watch", false, "evaluate the file again each time that it changes, until interrupted")
+	opts, code, ok := c.parse(flags, args, &s)
+	if !ok {
+		return code
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitFailed
+	}
+	file := flags.Arg(0)
+	if !*watch {
+		return c.runSheet(file, opts, s)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	done := make(chan struct{})
+	go func() {
+		<-interrupt
+		close(done)
+	}()
+	return watchFile(file, watchInterval, done, func() int {
+		if c.terminal {
+			// clear the screen so that only the latest results are shown
+			fmt.Fprint(c.stdout, "\x1b[H\x1b[2J")
+		}
+		return c.runSheet(file, opts, s)
+	})
+}
+
+// runSheet evaluates each line of a file and returns the exit code.
+func (c *cli) runSheet(file string, opts []calc.Option, s settings) int {
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	defer f.Close()
+	e := c.evaluator(opts, s)
+	if s.output != plainOutput {
+		// each expression is a result, or a row, like those of 'eval'
+		return batch(bufio.NewReader(f), func(line string) bool {
+			expr, _ := sheet.SplitLine(line)
+			expr = strings.TrimSpace(expr)
+			return expr == "" || e.calculate(expr)
+		}, c.stderr)
+	}
+	lines, err := e.sheet(f)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+		return exitFailed
+	}
+	e.printSheet(lines)
+	for _, line := range lines {
+		if line.failed {
+			return exitInvalid
+		}
+	}
+	return exitOK
+}
+
+// watchFile calls a function, and then calls it again each time that a file is modified, until done; returns the
+// exit code of the last call.
+func watchFile(file string, interval time.Duration, done <-chan struct{}, fn func() int) int {
+	modified := func() (time.Time, int64) {
+		info, err := os.Stat(file)
+		if err != nil {
+			// like while an editor replaces the file
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	lastTime, lastSize := modified()
+	code := fn()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return code
+		case <-ticker.C:
+			modTime, size := modified()
+			if size < 0 || modTime.Equal(lastTime) && size == lastSize {
+				continue
+			}
+			lastTime, lastSize = modTime, size
+			code = fn()
+		}
+	}
+}
+
+// sheet evaluates each line of a sheet in order; blank lines and comments that start with '#' are kept, but not
+// evaluated.
+func (e *evaluator) sheet(reader io.Reader) ([]sheetLine, error) {
+	var lines []sheetLine
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := sheetLine{text: strings.TrimRight(scanner.Text(), " \t\r")}
+		line.expr, line.comment = sheet.SplitLine(line.text)
+		if line.expr == "" {
+			lines = append(lines, line)
+			continue
+		}
+		name, amt, err := calc.CalculateAssignment(line.expr, e.options()...)
+		if err != nil {
+			line.result, line.failed = "error: "+err.Error(), true
+		} else {
+			if name != "" {
+				e.assign(name, amt)
+			}
+			line.result = calc.Format(amt, e.options()...)
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// printSheet prints each line of a sheet with its result, if any, in a column to the right of the longest line. The
+// results are aligned to the right so that the numbers line up.
+func (e *evaluator) printSheet(lines []sheetLine) {
+	width, resultWidth := 0, 0
+	for _, line := range lines {
+		if line.expr == "" {
+			continue
+		}
+		if w := runewidth.StringWidth(line.text); w > width {
+			width = w
+		}
+		if w := runewidth.StringWidth(line.result); !line.failed && w > resultWidth {
+			resultWidth = w
+		}
+	}
+	for _, line := range lines {
+		text := e.colors.highlight(line.expr, e.tokenizerOptions()...) + paint(e.colors.comment, line.comment)
+		switch {
+		case line.expr == "":
+			fmt.Fprintln(e.writer, text)
+		case line.failed:
+			fmt.Fprintf(e.writer, "%s%s%s\n", text, padding(line.text, width), paint(e.colors.err, line.result))
+		default:
+			result := strings.Repeat(" ", resultWidth-runewidth.StringWidth(line.result)) + line.result
+			fmt.Fprintf(e.writer, "%s%s%s\n", text, padding(line.text, width), e.colors.highlight(result, e.tokenizerOptions()...))
+		}
+	}
+}
+
+// padding returns the spaces that separate a line from the column of results.
+func padding(text string, width int) string {
+	pad := width - runewidth.StringWidth(text)
+	if pad < 0 {
+		pad = 0
+	}
+	return strings.Repeat(" ", pad+4)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writes a sheet to a temporary file and returns its path
+func writeSheet(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "costs.qc")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+const testSheet = `# shared costs
+rent = 1200 USD
+per person = rent / 3  # each
+
+per person * 2
+`
+
+func TestRunSheet(t *testing.T) {
+	code, stdout, stderr := runWith([]string{"sheet", writeSheet(t, testSheet)}, "", false)
+	assert.Equal(t, exitOK, code)
+	expected := "# shared costs\n" +
+		"rent = 1200 USD                  1200.00 USD\n" +
+		"per person = rent / 3  # each     400.00 USD\n" +
+		"\n" +
+		"per person * 2                    800.00 USD\n"
+	assert.Equal(t, expected, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestRunSheetInvalid(t *testing.T) {
+	code, stdout, _ := runWith([]string{"sheet", writeSheet(t, "total = 2 kg\ntotal + 3 miles\n")}, "", false)
+	assert.Equal(t, exitInvalid, code)
+	expected := "total = 2 kg       2.00 kg\n" +
+		"total + 3 miles    error: cannot convert from miles to kg\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunSheetCSV(t *testing.T) {
+	code, stdout, _ := runWith([]string{"sheet", "-output", "csv", writeSheet(t, testSheet)}, "", false)
+	assert.Equal(t, exitOK, code)
+	expected := "input,name,result,value,upper,units,unit,symbol,quantity,code,error,position,width\n" +
+		"rent = 1200 USD,rent,1200.00 USD,1200,,USD,us dollar,USD,currency,,,,\n" +
+		"per person = rent / 3,per person,400.00 USD,400,,USD,us dollar,USD,currency,,,,\n" +
+		"per person * 2,,800.00 USD,800,,USD,us dollar,USD,currency,,,,\n"
+	assert.Equal(t, expected, stdout)
+}
+
+func TestRunSheetErrors(t *testing.T) {
+	code, _, stderr := runWith([]string{"sheet"}, "", false)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "qcalc sheet [flags] file")
+
+	code, _, stderr = runWith([]string{"sheet", filepath.Join(t.TempDir(), "missing.qc")}, "", false)
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "no such file or directory")
+}
+
+func TestWatchFile(t *testing.T) {
+	file := writeSheet(t, "1 kg\n")
+	done := make(chan struct{})
+	calls := make(chan int, 10)
+	count := 0
+	go func() {
+		watchFile(file, time.Millisecond, done, func() int {
+			count++
+			calls <- count
+			return exitOK
+		})
+	}()
+	assert.Equal(t, 1, <-calls)
+
+	// a change to the file runs the function again
+	assert.Nil(t, ioutil.WriteFile(file, []byte("1 kg + 2 kg\n"), 0600))
+	select {
+	case call := <-calls:
+		assert.Equal(t, 2, call)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not noticed")
+	}
+	close(done)
+}
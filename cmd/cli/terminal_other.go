@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !openbsd && !netbsd && !dragonfly && !solaris
+// +build !linux,!darwin,!freebsd,!openbsd,!netbsd,!dragonfly,!solaris
+
+package main
+
+import "os"
+
+// terminalColumns returns the number of columns of a terminal; 0 if it is not known.
+func terminalColumns(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd || openbsd || netbsd || dragonfly || solaris
+// +build linux darwin freebsd openbsd netbsd dragonfly solaris
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// terminalColumns returns the number of columns of a terminal; 0 if it is not known.
+func terminalColumns(f *os.File) int {
+	size, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(size.Col)
+}
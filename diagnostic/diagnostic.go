@@ -0,0 +1,112 @@
+// Package diagnostic describes the problems found in an input, like units that are not known, so that
+// clients can act on each kind of problem by its stable code rather than by parsing its message.
+package diagnostic
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/types"
+)
+
+// Code A stable code that identifies a kind of problem; like 'QC1001' for units that are not known.
+type Code = types.Code
+
+// Severity How severe a problem is.
+type Severity = types.Severity
+
+const (
+	// UnknownUnit Units that are not known; like 'punds'.
+	UnknownUnit = types.CodeUnknownUnit
+	// AmbiguousUnit Units that could be more than one unit; like 'gal'.
+	AmbiguousUnit = types.CodeAmbiguousUnit
+	// IncompatibleUnits Units that cannot be converted to one another; like meters to pounds.
+	IncompatibleUnits = types.CodeIncompatibleUnits
+	// InvalidRadix An amount that cannot be displayed in a radix; like kg in hex.
+	InvalidRadix = types.CodeInvalidRadix
+	// IncompatibleFactors Amounts that cannot be multiplied, or divided, by one another; like kg by kg.
+	IncompatibleFactors = types.CodeIncompatibleFactors
+	// UnexpectedToken A token that is not expected; like the second 'kg' in '2 kg kg'.
+	UnexpectedToken = types.CodeUnexpectedToken
+	// UnexpectedEnd An input that ends too soon; like '2 kg +'.
+	UnexpectedEnd = types.CodeUnexpectedEnd
+	// InvalidToken Input that cannot be tokenized; like '?'.
+	InvalidToken = types.CodeInvalidToken
+	// InvalidOperator An operator that is not supported.
+	InvalidOperator = types.CodeInvalidOperator
+	// ReservedName A variable named like units, a keyword or a radix; like 'kg' in 'kg = 5 m'.
+	ReservedName = types.CodeReservedName
+	// InvalidNumber A number that is not valid.
+	InvalidNumber = types.CodeInvalidNumber
+	// AmbiguousNumber A number whose digit grouping is ambiguous; like '1,00'.
+	AmbiguousNumber = types.CodeAmbiguousNumber
+	// TooManyDigits A number with more significant digits than the limit.
+	TooManyDigits = types.CodeTooManyDigits
+	// DivisionByZero An amount that is divided by zero; like '2 m / (1 m - 1 m)'.
+	DivisionByZero = types.CodeDivisionByZero
+	// InputTooLong An input that is longer than the limit.
+	InputTooLong = types.CodeInputTooLong
+	// TooManyTokens An input with more tokens than the limit.
+	TooManyTokens = types.CodeTooManyTokens
+	// TooDeep An expression that is nested deeper than the limit.
+	TooDeep = types.CodeTooDeep
+	// Cancelled A calculation that was cancelled.
+	Cancelled = types.CodeCancelled
+	// ReadFailed Tokens that could not be read.
+	ReadFailed = types.CodeReadFailed
+)
+
+const (
+	// Error A problem that stops the calculation.
+	Error = types.SeverityError
+	// Warning A problem that does not stop the calculation, but may give an unexpected result.
+	Warning = types.SeverityWarning
+)
+
+// Diagnostic Describes a problem found in an input. It can be encoded as JSON.
+type Diagnostic struct {
+	Code        Code     `json:"code"`                  // a stable code like 'QC1001'
+	Name        string   `json:"name"`                  // a readable name for the code like 'unknown-unit'
+	Severity    Severity `json:"severity"`              // how severe the problem is
+	Message     string   `json:"message"`               // describes the problem
+	Start       int      `json:"start"`                 // the byte position where the problem starts; the first is 1
+	Width       int      `json:"width"`                 // the number of bytes spanned by the problem
+	Expected    []string `json:"expected,omitempty"`    // the names of the tokens that were expected; like 'units'
+	Suggestions []string `json:"suggestions,omitempty"` // alternatives to what was found; like similar units
+}
+
+// New Creates a diagnostic from an error returned by the calculator.
+func New(err calc.InputError) Diagnostic {
+	return Translate(err, locale.Default)
+}
+
+// Translate Creates a diagnostic with a message in the language of a locale.
+func Translate(err calc.InputError, loc calc.Locale) Diagnostic {
+	start, width := err.Position()
+	var expected []string
+	for _, tokenType := range err.Expected() {
+		expected = append(expected, tokenType.Name())
+	}
+	return Diagnostic{
+		Code:        err.Code(),
+		Name:        err.Code().Name(),
+		Severity:    err.Code().Severity(),
+		Message:     err.Translate(loc),
+		Start:       start,
+		Width:       width,
+		Expected:    expected,
+		Suggestions: err.Suggestions(),
+	}
+}
+
+// All Creates a diagnostic for each error; like those returned by calc.Diagnose.
+func All(errs []calc.InputError) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		diagnostics = append(diagnostics, New(err))
+	}
+	return diagnostics
+}
+
+func (d Diagnostic) Error() string {
+	return d.Message
+}
@@ -0,0 +1,83 @@
+package diagnostic_test
+
+import (
+	"encoding/json"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var codes = map[string]diagnostic.Code{
+	"2 kg + 3 punds":     diagnostic.UnknownUnit,
+	"3 gal in liters":    diagnostic.AmbiguousUnit,
+	"2 miles + 3 pounds": diagnostic.IncompatibleUnits,
+	"2 kg in hex":        diagnostic.InvalidRadix,
+	"2 kg 3 kg":          diagnostic.UnexpectedToken,
+	"2":                  diagnostic.UnexpectedEnd,
+	"2 $ kg":             diagnostic.InvalidToken,
+	"1,00 kg":            diagnostic.AmbiguousNumber,
+}
+
+func TestCodes(t *testing.T) {
+	for input, expected := range codes {
+		t.Run(input, func(t *testing.T) {
+			_, err := calc.Calculate(input)
+			if assert.NotNil(t, err) {
+				actual := diagnostic.New(err)
+				assert.Equal(t, expected, actual.Code)
+				assert.Equal(t, expected.Name(), actual.Name)
+				assert.Equal(t, diagnostic.Error, actual.Severity)
+				assert.Equal(t, err.Error(), actual.Message)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := calc.Calculate("2 kg + 3 punds")
+	expected := diagnostic.Diagnostic{
+		Code:        diagnostic.UnknownUnit,
+		Name:        "unknown-unit",
+		Severity:    diagnostic.Error,
+		Message:     "'punds' is not a known measurement unit",
+		Start:       10,
+		Width:       5,
+		Suggestions: []string{"pounds", "pints"},
+	}
+	assert.Equal(t, expected, diagnostic.New(err))
+}
+
+func TestNewExpected(t *testing.T) {
+	_, err := calc.Calculate("2 kg 3 kg")
+	actual := diagnostic.New(err)
+	assert.Equal(t, []string{"plus", "minus", "multiply", "divide", "in"}, actual.Expected)
+}
+
+func TestTranslate(t *testing.T) {
+	loc, _ := calc.FindLocale("de")
+	_, err := calc.Calculate("2 kg + 3 punds", calc.WithLocale(loc))
+	assert.Equal(t, err.Translate(loc), diagnostic.Translate(err, loc).Message)
+}
+
+func TestAll(t *testing.T) {
+	actual := diagnostic.All(calc.Diagnose("2 kgg + 3 lbz"))
+	if assert.Len(t, actual, 2) {
+		assert.Equal(t, 3, actual[0].Start)
+		assert.Equal(t, 11, actual[1].Start)
+	}
+	assert.Empty(t, diagnostic.All(calc.Diagnose("2 kg + 3 lbs")))
+}
+
+func TestJSON(t *testing.T) {
+	_, err := calc.Calculate("2 kg 3 kg")
+	encoded, jsonErr := json.Marshal(diagnostic.New(err))
+	assert.Nil(t, jsonErr)
+	expected := `{"code":"QC2001","name":"unexpected-token","severity":"error",` +
+		`"message":"got '3', but expected '+', '-', '*', '/', 'in'","start":6,"width":1,"expected":["plus","minus","multiply","divide","in"]}`
+	assert.JSONEq(t, expected, string(encoded))
+
+	var decoded diagnostic.Diagnostic
+	assert.Nil(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, diagnostic.New(err), decoded)
+}
@@ -1,9 +1,25 @@
 module github.com/nickwallen/quick-calc
 
-go 1.14
+go 1.17
 
 require (
-	github.com/Knetic/govaluate v3.0.0+incompatible // indirect
 	github.com/bcicen/go-units v1.0.0
-	github.com/stretchr/testify v1.5.1
+	github.com/mattn/go-runewidth v0.0.3
+	github.com/peterh/liner v1.2.2
+	github.com/stretchr/testify v1.8.3
+	golang.org/x/sys v0.7.0
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.30.0
+)
+
+require (
+	github.com/Knetic/govaluate v3.0.0+incompatible // indirect
+	github.com/bcicen/bfstree v0.0.0-20180121191807-11ea469698a6 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,44 +1,92 @@
 package io
 
 import (
-	"fmt"
+	"context"
 	"github.com/nickwallen/quick-calc/internal/types"
 )
 
 // TokenChannel Enables tokens to be read from and written to a channel.
 type TokenChannel struct {
-	channel chan types.Token // the channel on which tokens are published
-	input   string           // the original input to be tokenized
+	channel chan types.Token   // the channel on which tokens are published
+	input   string             // the original input to be tokenized
+	done    <-chan struct{}    // closed once the channel is cancelled
+	cancel  context.CancelFunc // cancels the channel
 }
 
 // NewTokenChannel Creates a new token channel.
 func NewTokenChannel(input string) TokenChannel {
+	return NewTokenChannelContext(context.Background(), input)
+}
+
+// NewTokenChannelContext Creates a new token channel that is cancelled along with a context.
+func NewTokenChannelContext(ctx context.Context, input string) TokenChannel {
+	ctx, cancel := context.WithCancel(ctx)
 	return TokenChannel{
 		channel: make(chan types.Token, 2),
 		input:   input,
+		done:    ctx.Done(),
+		cancel:  cancel,
 	}
 }
 
-// ReadToken Reads tokens from a channel.
+// ReadToken Reads tokens from a channel. Once the channel is closed, only EOF is read.
 func (t TokenChannel) ReadToken() (types.Token, error) {
-	var token types.Token
-	token, ok := <-t.channel
+	select {
+	case token, ok := <-t.channel:
+		return t.received(token, ok), nil
+	case <-t.done:
+		// closing the channel also cancels it, so the tokens written before it was closed are still read
+		select {
+		case token, ok := <-t.channel:
+			return t.received(token, ok), nil
+		default:
+			return types.Token{}, context.Canceled
+		}
+	}
+}
+
+// received returns a token received from the channel; EOF once the channel is closed.
+func (t TokenChannel) received(token types.Token, ok bool) types.Token {
 	if !ok {
-		return token, fmt.Errorf("no more tokens; channel is closed")
+		return types.EOF.TokenAt("", len(t.input)+1)
 	}
-	return token, nil
+	return token
 }
 
-// WriteToken Writes tokens to a channel.
+// WriteToken Writes tokens to a channel. An error is returned, rather than blocking, once the channel is cancelled.
 func (t TokenChannel) WriteToken(token types.Token) error {
-	t.channel <- token
-	return nil
+	if t.cancelled() {
+		return context.Canceled
+	}
+	select {
+	case t.channel <- token:
+		return nil
+	case <-t.done:
+		return context.Canceled
+	}
 }
 
-// Close Closes the token channel.
+// Close Closes the token channel once all of the tokens are written. This also cancels the channel,
+// so that its context is released, but the tokens already written can still be read.
 func (t TokenChannel) Close() {
-	t.channel <- types.EOF.TokenAt("", len(t.input))
 	close(t.channel)
+	t.cancel()
+}
+
+// Cancel Cancels the token channel so that neither the writer nor the reader are blocked; for
+// example, when the reader stops reading before all of the tokens are written.
+func (t TokenChannel) Cancel() {
+	t.cancel()
+}
+
+// cancelled returns true if the channel has been cancelled.
+func (t TokenChannel) cancelled() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
 }
 
 // Input returns the original input that was tokenized.
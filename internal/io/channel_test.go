@@ -0,0 +1,60 @@
+package io
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenChannel(t *testing.T) {
+	tokens := NewTokenChannel("2 kg")
+	assert.Nil(t, tokens.WriteToken(types.Number.TokenAt("2", 1)))
+	tokens.Close()
+
+	token, err := tokens.ReadToken()
+	assert.Nil(t, err)
+	assert.Equal(t, types.Number.TokenAt("2", 1), token)
+
+	// only EOF is read once the channel is closed
+	token, err = tokens.ReadToken()
+	assert.Nil(t, err)
+	assert.Equal(t, types.EOF.TokenAt("", 5), token)
+}
+
+func TestTokenChannel_Cancel(t *testing.T) {
+	tokens := NewTokenChannel("2 kg + 3 kg")
+	tokens.Cancel()
+
+	// neither writing nor reading blocks once cancelled
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, context.Canceled, tokens.WriteToken(types.Number.TokenAt("2", 1)))
+	}
+	_, err := tokens.ReadToken()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTokenChannel_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tokens := NewTokenChannelContext(ctx, "2 kg")
+	cancel()
+	assert.Equal(t, context.Canceled, tokens.WriteToken(types.Number.TokenAt("2", 1)))
+}
+
+func TestTokenChannel_CloseReleasesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tokens := NewTokenChannelContext(ctx, "2 kg")
+	assert.Nil(t, tokens.WriteToken(types.Number.TokenAt("2", 1)))
+	assert.Nil(t, tokens.WriteToken(types.Units.TokenAt("kg", 3)))
+	tokens.Close()
+
+	// the context of the channel is done once closed, but the tokens written can still be read
+	assert.Equal(t, context.Canceled, tokens.WriteToken(types.Number.TokenAt("3", 6)))
+	expected := []types.Token{types.Number.TokenAt("2", 1), types.Units.TokenAt("kg", 3), types.EOF.TokenAt("", 5)}
+	for _, token := range expected {
+		actual, err := tokens.ReadToken()
+		assert.Nil(t, err)
+		assert.Equal(t, token, actual)
+	}
+}
@@ -0,0 +1,74 @@
+package locale
+
+// German reads and writes numbers like '1.000,5'.
+var German = Locale{
+	Name:        "de",
+	Decimal:     ',',
+	Group:       '.',
+	GroupOutput: true,
+	Keywords:    []string{"in", "nach"},
+	Units: map[string]string{
+		"gramm":       "gram",
+		"kilogramm":   "kilogram",
+		"milligramm":  "milligram",
+		"pfund":       "pound",
+		"unze":        "ounce",
+		"unzen":       "ounce",
+		"zentimeter":  "centimeter",
+		"fuß":         "foot",
+		"fuss":        "foot",
+		"zoll":        "inch",
+		"meile":       "mile",
+		"meilen":      "mile",
+		"gallone":     "gallon",
+		"gallonen":    "gallon",
+		"sekunde":     "second",
+		"sekunden":    "second",
+		"minuten":     "minute",
+		"stunde":      "hour",
+		"stunden":     "hour",
+		"tag":         "day",
+		"tage":        "day",
+		"monat":       "month",
+		"monate":      "month",
+		"jahr":        "year",
+		"jahre":       "year",
+		"jahrzehnt":   "decade",
+		"jahrhundert": "century",
+	},
+	Messages: map[string]string{
+		"a number":                 "eine Zahl",
+		"a unit":                   "eine Einheit",
+		"end of input":             "Ende der Eingabe",
+		"cannot divide %s by zero": "kann %s nicht durch null teilen",
+		"cannot divide %s by %s":   "kann %s nicht durch %s teilen",
+		"cannot multiply %s by %s": "kann %s nicht mit %s multiplizieren",
+		"help:":                    "Hilfe:",
+		"did you mean %s?":         "meintest du %s?",
+		"found %d problems":        "%d Probleme gefunden",
+		"'%s' has no units, so cannot be combined with %s":                             "'%s' hat keine Einheit und kann daher nicht mit %s verrechnet werden",
+		"a number with no units cannot be combined with %s":                            "eine Zahl ohne Einheit kann nicht mit %s verrechnet werden",
+		"'%s' is a unit or keyword, so cannot name a variable":                         "'%s' ist eine Einheit oder ein Schlüsselwort und kann keine Variable benennen",
+		"cannot convert from %s to %s":                                                 "kann nicht von %s in %s umrechnen",
+		"cannot display %s in %s; only whole numbers of bytes or bits":                 "kann %s nicht als %s darstellen; nur ganze Zahlen von Bytes oder Bits",
+		"got '%s', but expected %s":                                                    "'%s' gefunden, aber %s erwartet",
+		"reached end of input, but expected %s":                                        "Ende der Eingabe erreicht, aber %s erwartet",
+		"'%s' is not a known measurement unit":                                         "'%s' ist keine bekannte Maßeinheit",
+		"found invalid operator %s":                                                    "ungültiger Operator %s gefunden",
+		"'%s' is not a valid number":                                                   "'%s' ist keine gültige Zahl",
+		"'%s' is ambiguous; use '%c' to separate decimals and '%c' to group thousands": "'%s' ist mehrdeutig; verwende '%c' als Dezimaltrennzeichen und '%c' zur Tausendergruppierung",
+		"'%s' is ambiguous; it could be %s":                                            "'%s' ist mehrdeutig; gemeint sein kann %s",
+		"the input is longer than %d characters":                                       "die Eingabe ist länger als %d Zeichen",
+		"the input has more than %d tokens":                                            "die Eingabe hat mehr als %d Tokens",
+		"the expression is nested more than %d levels deep":                            "der Ausdruck ist tiefer als %d Ebenen verschachtelt",
+		"'%s' has more than %d significant digits":                                     "'%s' hat mehr als %d signifikante Stellen",
+		"the calculation was cancelled; %s":                                            "die Berechnung wurde abgebrochen; %s",
+		"'-' cannot negate a group; multiply it by -1 instead":                         "'-' kann keine Gruppe negieren; multipliziere sie stattdessen mit -1",
+		"expected number, but got '%s'":                                                "Zahl erwartet, aber '%s' gefunden",
+		"expected symbol, but got '%s'":                                                "Symbol erwartet, aber '%s' gefunden",
+		"expected units, but got '%s'":                                                 "Einheit erwartet, aber '%s' gefunden",
+		"expected EOF, but got '%s'":                                                   "Ende der Eingabe erwartet, aber '%s' gefunden",
+		"expected '..', but got '%s'":                                                  "'..' erwartet, aber '%s' gefunden",
+		"expected 'in' keyword, but got '%s'":                                          "Schlüsselwort 'in' erwartet, aber '%s' gefunden",
+	},
+}
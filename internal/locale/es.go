@@ -0,0 +1,101 @@
+package locale
+
+// Spanish reads and writes numbers like '1.000,5'.
+var Spanish = Locale{
+	Name:        "es",
+	Decimal:     ',',
+	Group:       '.',
+	GroupOutput: true,
+	Keywords:    []string{"en", "in"},
+	Units: map[string]string{
+		"gramo":       "gram",
+		"gramos":      "gram",
+		"kilogramo":   "kilogram",
+		"kilogramos":  "kilogram",
+		"miligramo":   "milligram",
+		"miligramos":  "milligram",
+		"libra":       "pound",
+		"libras":      "pound",
+		"onza":        "ounce",
+		"onzas":       "ounce",
+		"metro":       "meter",
+		"metros":      "meter",
+		"kilómetro":   "kilometer",
+		"kilómetros":  "kilometer",
+		"kilometro":   "kilometer",
+		"kilometros":  "kilometer",
+		"centímetro":  "centimeter",
+		"centímetros": "centimeter",
+		"centimetro":  "centimeter",
+		"centimetros": "centimeter",
+		"milímetro":   "millimeter",
+		"milímetros":  "millimeter",
+		"milimetro":   "millimeter",
+		"milimetros":  "millimeter",
+		"pie":         "foot",
+		"pies":        "foot",
+		"pulgada":     "inch",
+		"pulgadas":    "inch",
+		"milla":       "mile",
+		"millas":      "mile",
+		"yarda":       "yard",
+		"yardas":      "yard",
+		"litro":       "liter",
+		"litros":      "liter",
+		"mililitro":   "milliliter",
+		"mililitros":  "milliliter",
+		"galón":       "gallon",
+		"galon":       "gallon",
+		"galones":     "gallon",
+		"segundo":     "second",
+		"segundos":    "second",
+		"minuto":      "minute",
+		"minutos":     "minute",
+		"hora":        "hour",
+		"horas":       "hour",
+		"día":         "day",
+		"días":        "day",
+		"dia":         "day",
+		"dias":        "day",
+		"mes":         "month",
+		"meses":       "month",
+		"año":         "year",
+		"años":        "year",
+	},
+	Messages: map[string]string{
+		"a number":                 "un número",
+		"a unit":                   "una unidad",
+		"end of input":             "el final de la entrada",
+		"'in'":                     "'en'",
+		"cannot divide %s by zero": "no se puede dividir %s entre cero",
+		"cannot divide %s by %s":   "no se puede dividir %s entre %s",
+		"cannot multiply %s by %s": "no se puede multiplicar %s por %s",
+		"help:":                    "ayuda:",
+		"did you mean %s?":         "¿quiso decir %s?",
+		"found %d problems":        "se encontraron %d problemas",
+		"'%s' has no units, so cannot be combined with %s":                             "'%s' no tiene unidades, así que no se puede combinar con %s",
+		"a number with no units cannot be combined with %s":                            "un número sin unidades no se puede combinar con %s",
+		"'%s' is a unit or keyword, so cannot name a variable":                         "'%s' es una unidad o una palabra clave, así que no puede nombrar una variable",
+		"cannot convert from %s to %s":                                                 "no se puede convertir de %s a %s",
+		"cannot display %s in %s; only whole numbers of bytes or bits":                 "no se puede mostrar %s en %s; solo números enteros de bytes o bits",
+		"got '%s', but expected %s":                                                    "se encontró '%s', pero se esperaba %s",
+		"reached end of input, but expected %s":                                        "se alcanzó el final de la entrada, pero se esperaba %s",
+		"'%s' is not a known measurement unit":                                         "'%s' no es una unidad de medida conocida",
+		"found invalid operator %s":                                                    "se encontró un operador no válido %s",
+		"'%s' is not a valid number":                                                   "'%s' no es un número válido",
+		"'%s' is ambiguous; use '%c' to separate decimals and '%c' to group thousands": "'%s' es ambiguo; use '%c' para separar decimales y '%c' para agrupar miles",
+		"'%s' is ambiguous; it could be %s":                                            "'%s' es ambiguo; puede ser %s",
+		"the input is longer than %d characters":                                       "la entrada tiene más de %d caracteres",
+		"the input has more than %d tokens":                                            "la entrada tiene más de %d tokens",
+		"the expression is nested more than %d levels deep":                            "la expresión está anidada a más de %d niveles",
+		"'%s' has more than %d significant digits":                                     "'%s' tiene más de %d dígitos significativos",
+		"the calculation was cancelled; %s":                                            "el cálculo fue cancelado; %s",
+		"'-' cannot negate a group; multiply it by -1 instead":                         "'-' no puede negar un grupo; multiplíquelo por -1",
+		"expected number, but got '%s'":                                                "se esperaba un número, pero se encontró '%s'",
+		"expected symbol, but got '%s'":                                                "se esperaba un símbolo, pero se encontró '%s'",
+		"expected units, but got '%s'":                                                 "se esperaba una unidad, pero se encontró '%s'",
+		"expected EOF, but got '%s'":                                                   "se esperaba el final de la entrada, pero se encontró '%s'",
+		"expected '..', but got '%s'":                                                  "se esperaba '..', pero se encontró '%s'",
+		"expected 'in' keyword, but got '%s'":                                          "se esperaba la palabra clave 'en', pero se encontró '%s'",
+	},
+}
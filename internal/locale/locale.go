@@ -0,0 +1,124 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrAmbiguousGrouping indicates that the digit grouping of a number is ambiguous; like '1,00' or '1.000,5'.
+var ErrAmbiguousGrouping = errors.New("ambiguous digit grouping")
+
+// Locale governs how numbers, units and messages are read and written.
+type Locale struct {
+	Name        string            // the name of the locale like 'de'
+	Decimal     rune              // the decimal separator like '.' in 2.5
+	Group       rune              // the digit grouping separator like ',' in 1,000
+	GroupOutput bool              // true if the digits of output numbers are grouped
+	Keywords    []string          // the keywords for a unit conversion like 'in'
+	Units       map[string]string // the lower case names of units in this language and their canonical names
+	Messages    map[string]string // messages in English and their translations
+}
+
+var (
+	// Default reads numbers like '1,000.5' and writes them like '1000.50'.
+	Default = Locale{Name: "default", Decimal: '.', Group: ',', Keywords: []string{"in", "to", "as"}}
+	// English reads and writes numbers like '1,000.5'.
+	English = Locale{Name: "en", Decimal: '.', Group: ',', GroupOutput: true, Keywords: []string{"in", "to", "as"}}
+)
+
+// all of the known locales
+var locales = []Locale{Default, English, German, Spanish}
+
+// All returns all of the known locales.
+func All() []Locale {
+	return locales
+}
+
+// Find returns the locale with the given name.
+func Find(name string) (Locale, error) {
+	for _, loc := range locales {
+		if strings.EqualFold(loc.Name, name) {
+			return loc, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("unknown locale '%s'", name)
+}
+
+// Translate returns the translation of an English message.
+func (l Locale) Translate(message string) string {
+	if translated, ok := l.Messages[message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf formats a message after translating it from English.
+func (l Locale) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(l.Translate(format), args...)
+}
+
+// CanonicalUnit returns the canonical name of a unit that is named in this language; like 'pound' for 'Pfund'.
+func (l Locale) CanonicalUnit(name string) (string, bool) {
+	canonical, ok := l.Units[strings.ToLower(name)]
+	return canonical, ok
+}
+
+// ParseNumber parses a decimal number written in the locale. The digits of the
+// integer part may be grouped in threes, but the fractional part cannot be grouped.
+func (l Locale) ParseNumber(value string) (float64, error) {
+	integer, fraction := value, ""
+	if i := strings.IndexRune(value, l.Decimal); i >= 0 {
+		integer, fraction = value[:i], value[i+len(string(l.Decimal)):]
+	}
+	if strings.ContainsRune(fraction, l.Group) || strings.ContainsRune(fraction, l.Decimal) {
+		return 0, ErrAmbiguousGrouping
+	}
+	if strings.ContainsRune(integer, l.Group) {
+		groups := strings.Split(strings.TrimLeft(integer, "+-"), string(l.Group))
+		for i, group := range groups {
+			digits := len(strings.ReplaceAll(group, "_", ""))
+			if (i == 0 && (digits < 1 || digits > 3)) || (i > 0 && digits != 3) {
+				return 0, ErrAmbiguousGrouping
+			}
+		}
+		integer = strings.ReplaceAll(integer, string(l.Group), "")
+	}
+	if fraction != "" {
+		return strconv.ParseFloat(integer+"."+fraction, 64)
+	}
+	return strconv.ParseFloat(integer, 64)
+}
+
+// FormatNumber formats a number with a fixed number of decimal places.
+func (l Locale) FormatNumber(value float64, precision int) string {
+	formatted := strconv.FormatFloat(value, 'f', precision, 64)
+	integer, fraction := formatted, ""
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		integer, fraction = formatted[:i], formatted[i+1:]
+	}
+	if l.GroupOutput {
+		integer = group(integer, l.Group)
+	}
+	if fraction == "" {
+		return integer
+	}
+	return integer + string(l.Decimal) + fraction
+}
+
+// group separates the digits of an integer into groups of three.
+func group(integer string, separator rune) string {
+	sign := ""
+	if strings.HasPrefix(integer, "-") {
+		sign, integer = "-", integer[1:]
+	}
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteRune(separator)
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String()
+}
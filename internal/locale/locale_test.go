@@ -0,0 +1,73 @@
+package locale
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	loc, err := Find("DE")
+	assert.Nil(t, err)
+	assert.Equal(t, German, loc)
+}
+
+func TestFind_Unknown(t *testing.T) {
+	_, err := Find("xx")
+	assert.NotNil(t, err)
+}
+
+func TestParseNumber(t *testing.T) {
+	testCases := map[string]map[string]float64{
+		"default": {"1000": 1000, "1,000": 1000, "1,000.5": 1000.5, "-12,345,678.25": -12345678.25, "2.5e3": 2500},
+		"de":      {"1000": 1000, "1.000": 1000, "1.000,5": 1000.5, "2,5": 2.5, "-12.345.678,25": -12345678.25},
+	}
+	for name, numbers := range testCases {
+		loc, _ := Find(name)
+		for input, expected := range numbers {
+			t.Run(loc.Name+" "+input, func(t *testing.T) {
+				actual, err := loc.ParseNumber(input)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, actual)
+			})
+		}
+	}
+}
+
+func TestParseNumber_Ambiguous(t *testing.T) {
+	testCases := map[string][]string{
+		"default": {"1,00", "1.000,5", "1,0000", "1000,000.5"},
+		"de":      {"1.00", "1,000.5", "1.0000"},
+	}
+	for name, numbers := range testCases {
+		loc, _ := Find(name)
+		for _, input := range numbers {
+			t.Run(loc.Name+" "+input, func(t *testing.T) {
+				_, err := loc.ParseNumber(input)
+				assert.Equal(t, ErrAmbiguousGrouping, err)
+			})
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "1234567.50", Default.FormatNumber(1234567.5, 2))
+	assert.Equal(t, "1,234,567.50", English.FormatNumber(1234567.5, 2))
+	assert.Equal(t, "1.234.567,50", German.FormatNumber(1234567.5, 2))
+	assert.Equal(t, "-123,50", German.FormatNumber(-123.5, 2))
+	assert.Equal(t, "-123,456", English.FormatNumber(-123456, 0))
+	assert.Equal(t, "0,00", Spanish.FormatNumber(0, 2))
+}
+
+func TestSprintf(t *testing.T) {
+	assert.Equal(t, "'googles' ist keine bekannte Maßeinheit", German.Sprintf("'%s' is not a known measurement unit", "googles"))
+	assert.Equal(t, "'googles' is not a known measurement unit", English.Sprintf("'%s' is not a known measurement unit", "googles"))
+	assert.Equal(t, "untranslated %s", Spanish.Translate("untranslated %s"))
+}
+
+func TestCanonicalUnit(t *testing.T) {
+	canonical, ok := German.CanonicalUnit("Pfund")
+	assert.True(t, ok)
+	assert.Equal(t, "pound", canonical)
+	_, ok = English.CanonicalUnit("Pfund")
+	assert.False(t, ok)
+}
@@ -0,0 +1,171 @@
+package lsp
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/sheet"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"strings"
+	"unicode/utf8"
+)
+
+// a calculation file; each line is evaluated in order, with the variables assigned by the lines above it
+type document struct {
+	uri     string  // identifies the document
+	version int     // increases with each change
+	lines   []*line // each line of the document
+}
+
+// a line of a document and the result of its expression
+type line struct {
+	text        string                  // the line as written
+	expr        string                  // the expression on the line, without any comment; empty if there is none
+	name        string                  // the variable that is assigned the result, if any
+	nameSpan    ast.Span                // where the variable is named by the assignment
+	result      string                  // the formatted result, if the expression is valid
+	quantity    string                  // the quantity, or dimension, of the result; like 'mass'
+	diagnostics []diagnostic.Diagnostic // what is wrong with the expression, if anything
+	refs        []reference             // the variables that the expression refers to
+}
+
+// a variable that an expression refers to
+type reference struct {
+	name string   // the name of the variable
+	span ast.Span // where the variable is in the line
+	line int      // the line whose assignment gives the variable its value
+}
+
+// analyze evaluates each line of a document; blank lines and comments that start with '#' are not evaluated.
+func analyze(uri string, version int, text string, opts []calc.Option, loc locale.Locale) *document {
+	doc := &document{uri: uri, version: version}
+	vars := map[string]types.Amount{}
+	defs := map[string]int{}
+	for i, text := range strings.Split(text, "\n") {
+		l := &line{text: strings.TrimSuffix(text, "\r")}
+		doc.lines = append(doc.lines, l)
+		expr, _ := sheet.SplitLine(l.text)
+		if expr == "" {
+			continue
+		}
+		l.expr = expr
+		opts := append(append([]calc.Option(nil), opts...), calc.WithVariables(vars))
+		if node, err := calc.Parse(l.expr, opts...); err == nil {
+			ast.Inspect(node, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.Assignment:
+					l.name, l.nameSpan = node.Name.Value, node.Name.Span
+				case *ast.Variable:
+					l.refs = append(l.refs, reference{name: node.Name.Value, span: node.Name.Span, line: defs[node.Name.Value]})
+				}
+				return true
+			})
+		}
+		name, amt, err := calc.CalculateAssignment(l.expr, opts...)
+		if err != nil {
+			errs := calc.Diagnose(l.expr, opts...)
+			if len(errs) == 0 {
+				// a problem found only by evaluating the expression; like units that cannot be converted
+				errs = []types.InputError{err}
+			}
+			for _, err := range errs {
+				l.diagnostics = append(l.diagnostics, diagnostic.Translate(err, loc))
+			}
+			continue
+		}
+		if name != "" {
+			vars[name], defs[name] = amt, i
+		}
+		l.result = calc.Format(amt, opts...)
+		if unit, err := calc.FindUnit(amt.Units.Value, opts...); err == nil {
+			l.quantity = unit.Quantity
+		}
+	}
+	return doc
+}
+
+// at returns a line of the document, or nil if there is no such line.
+func (d *document) at(n int) *line {
+	if n < 0 || n >= len(d.lines) {
+		return nil
+	}
+	return d.lines[n]
+}
+
+// reference returns the variable at a byte of a line, if any; the first byte is at 1, like the positions of spans.
+func (l *line) reference(pos int) (reference, bool) {
+	for _, ref := range l.refs {
+		if contains(ref.span, pos) {
+			return ref, true
+		}
+	}
+	return reference{}, false
+}
+
+// contains returns true if a span contains a byte, or ends just before it; like the cursor after a name.
+func contains(span ast.Span, pos int) bool {
+	return span.Width > 0 && span.Start <= pos && pos <= span.End()
+}
+
+// describe describes a result; like '7.41 pounds' and its quantity.
+func describe(result, quantity string) string {
+	if quantity == "" {
+		return result
+	}
+	return result + "\n\n" + quantity
+}
+
+// exprRange returns the range of the expression of a line, without the spaces around it.
+func (l *line) exprRange(n int) textRange {
+	start := len(l.expr) - len(strings.TrimLeft(l.expr, " \t"))
+	return l.toRange(n, ast.Span{Start: start + 1, Width: len(strings.TrimSpace(l.expr))})
+}
+
+// spanOf returns the span of a line that a diagnostic covers.
+func spanOf(d diagnostic.Diagnostic) ast.Span {
+	return ast.Span{Start: d.Start, Width: d.Width}
+}
+
+// toRange returns the range of a line that a span covers.
+func (l *line) toRange(n int, span ast.Span) textRange {
+	return textRange{
+		Start: position{Line: n, Character: character(l.text, span.Start-1)},
+		End:   position{Line: n, Character: character(l.text, span.End()-1)},
+	}
+}
+
+// character returns the position of a byte of a text in UTF-16 code units, as positions are counted by the protocol.
+func character(text string, offset int) int {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	n := 0
+	for _, r := range text[:offset] {
+		n += utf16Len(r)
+	}
+	return n
+}
+
+// offset returns the byte of a text at a position in UTF-16 code units.
+func offset(text string, character int) int {
+	n := 0
+	for i, r := range text {
+		if n >= character {
+			return i
+		}
+		n += utf16Len(r)
+	}
+	return len(text)
+}
+
+// utf16Len returns the number of UTF-16 code units that encode a rune.
+func utf16Len(r rune) int {
+	if r > 0xFFFF && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
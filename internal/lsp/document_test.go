@@ -0,0 +1,78 @@
+package lsp
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testSheet = `# rent
+rent = 1200 USD # per month
+  per person = rent / 3
+rent + 3 kg
+2 kg + 3 punds in mlies`
+
+func TestAnalyze(t *testing.T) {
+	doc := analyze("file:///rent.qc", 1, testSheet, nil, locale.Default)
+	if !assert.Len(t, doc.lines, 5) {
+		return
+	}
+
+	comment := doc.lines[0]
+	assert.Empty(t, comment.expr)
+	assert.Empty(t, comment.result)
+
+	rent := doc.lines[1]
+	assert.Equal(t, "rent = 1200 USD ", rent.expr)
+	assert.Equal(t, "rent", rent.name)
+	assert.Equal(t, ast.Span{Start: 1, Width: 4}, rent.nameSpan)
+	assert.Equal(t, "1200.00 USD", rent.result)
+	assert.Equal(t, "currency", rent.quantity)
+
+	perPerson := doc.lines[2]
+	assert.Equal(t, "per person", perPerson.name)
+	assert.Equal(t, "400.00 USD", perPerson.result)
+	assert.Equal(t, []reference{{name: "rent", span: ast.Span{Start: 16, Width: 4}, line: 1}}, perPerson.refs)
+
+	incompatible := doc.lines[3]
+	assert.Empty(t, incompatible.result)
+	if assert.Len(t, incompatible.diagnostics, 1) {
+		assert.Equal(t, "cannot convert from kg to USD", incompatible.diagnostics[0].Message)
+	}
+
+	unknown := doc.lines[4]
+	if assert.Len(t, unknown.diagnostics, 2) {
+		assert.Equal(t, "'punds' is not a known measurement unit", unknown.diagnostics[0].Message)
+		assert.Equal(t, "'mlies' is not a known measurement unit", unknown.diagnostics[1].Message)
+	}
+}
+
+func TestAnalyzeReassignment(t *testing.T) {
+	doc := analyze("file:///a.qc", 1, "a = 2 kg\nx = a * 2\na = 3 kg\na + x", []calc.Option{calc.WithPrecision(0)}, locale.Default)
+	assert.Equal(t, 0, doc.lines[1].refs[0].line)
+	assert.Equal(t, 2, doc.lines[3].refs[0].line)
+	assert.Equal(t, "7 kg", doc.lines[3].result)
+}
+
+func TestCharacter(t *testing.T) {
+	tests := map[string]struct {
+		text      string
+		offset    int
+		character int
+	}{
+		"ascii":   {"2 kg", 2, 2},
+		"accents": {"ñ = 2 kg", 2, 1},
+		"emoji":   {"😀 = 2 kg", 4, 2},
+		"end":     {"2 kg", 10, 4},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.character, character(test.text, test.offset))
+			if test.offset <= len(test.text) {
+				assert.Equal(t, test.offset, offset(test.text, test.character))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadMessage(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("Content-Length: 2\r\nContent-Type: application/vscode-jsonrpc\r\n\r\n{}Content-Length: 4\r\n\r\nnull"))
+	content, err := readMessage(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", string(content))
+
+	content, err = readMessage(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "null", string(content))
+
+	_, err = readMessage(reader)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadMessageInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no length": "Content-Type: application/json\r\n\r\n{}",
+		"short":     "Content-Length: 10\r\n\r\n{}",
+		"headers":   "Content-Length: 2",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := readMessage(bufio.NewReader(strings.NewReader(input)))
+			assert.NotNil(t, err)
+			assert.NotEqual(t, io.EOF, err)
+		})
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, writeMessage(&b, position{Line: 1, Character: 2}))
+	assert.Equal(t, "Content-Length: 24\r\n\r\n{\"line\":1,\"character\":2}", b.String())
+}
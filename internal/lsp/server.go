@@ -0,0 +1,329 @@
+// Package lsp is a language server for calculation files, like those of 'qcalc sheet', so that an editor can show
+// what is wrong with each line, the result of each line, and the value of each variable. It speaks JSON-RPC, as
+// described by the Language Server Protocol, over a reader and a writer; like standard input and output.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"io"
+	"sort"
+)
+
+// the name of the server, and the source of its diagnostics
+const serverName = "qcalc"
+
+// ErrNoShutdown The client asked the server to exit without first asking it to shut down.
+var ErrNoShutdown = errors.New("exited before a shutdown request")
+
+// server Serves the requests of an editor, one at a time.
+type server struct {
+	opts      []calc.Option        // the options used to calculate every expression
+	locale    locale.Locale        // governs how numbers are read and written
+	precision int                  // the number of decimal places in a result
+	reader    *bufio.Reader        // the messages from the client
+	writer    io.Writer            // the messages to the client
+	documents map[string]*document // the open documents, by their uri
+	shutdown  bool                 // true once the client has asked the server to shut down
+}
+
+// Option configures the server.
+type Option func(*server)
+
+// WithOptions sets the options used to calculate every expression.
+func WithOptions(opts ...calc.Option) Option {
+	return func(s *server) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithLocale sets the locale that governs how numbers are read and written.
+func WithLocale(loc locale.Locale) Option {
+	return func(s *server) {
+		s.locale = loc
+	}
+}
+
+// WithPrecision sets the number of decimal places in a result.
+func WithPrecision(digits int) Option {
+	return func(s *server) {
+		s.precision = digits
+	}
+}
+
+// Serve serves the requests read from a reader, and writes the responses to a writer, until the client asks the
+// server to exit or there are no more requests.
+func Serve(reader io.Reader, writer io.Writer, opts ...Option) error {
+	s := &server{
+		locale:    locale.Default,
+		precision: calc.DefaultPrecision,
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: map[string]*document{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.opts = append(s.opts, calc.WithLocale(s.locale), calc.WithPrecision(s.precision))
+	for {
+		content, err := readMessage(s.reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		result, err := s.handle(req)
+		var failure *responseError
+		if err != nil && !errors.As(err, &failure) {
+			// the client cannot be written to
+			return err
+		}
+		if req.ID == nil {
+			// a notification has no response
+			continue
+		}
+		if err := s.reply(req.ID, result, failure); err != nil {
+			return err
+		}
+	}
+}
+
+// reply writes the result of a request, or why it failed.
+func (s *server) reply(id *json.RawMessage, result interface{}, failure *responseError) error {
+	if failure != nil {
+		return writeMessage(s.writer, errorResponse{JSONRPC: "2.0", ID: id, Error: *failure})
+	}
+	return writeMessage(s.writer, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// notify writes a notification to the client.
+func (s *server) notify(method string, params interface{}) error {
+	return writeMessage(s.writer, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle handles a request or notification and returns its result, or a *responseError if it failed.
+func (s *server) handle(req request) (interface{}, error) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "the server is shut down"}
+	}
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if len(params.Changes) == 0 {
+			return nil, nil
+		}
+		// the whole document is sent with each change
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Version, params.Changes[len(params.Changes)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publish(publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []lspDiagnostic{}})
+	case "textDocument/hover":
+		var params positionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/definition":
+		var params positionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params), nil
+	case "textDocument/completion":
+		return completions(), nil
+	case "textDocument/inlayHint":
+		var params inlayHintParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.inlayHints(params), nil
+	}
+	if req.ID == nil {
+		// notifications that are not known, like '$/cancelRequest', are ignored
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("'%s' is not a known method", req.Method)}
+}
+
+// decode decodes the parameters of a request.
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// initialize describes what the server can do.
+func (s *server) initialize() initializeResult {
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:   textDocumentSyncOptions{OpenClose: true, Change: syncFull},
+			HoverProvider:      true,
+			DefinitionProvider: true,
+			InlayHintProvider:  true,
+		},
+		ServerInfo: serverInfo{Name: serverName},
+	}
+}
+
+// open evaluates each line of a document that is opened or changed, and publishes what is wrong with it.
+func (s *server) open(uri string, version int, text string) error {
+	doc := analyze(uri, version, text, s.opts, s.locale)
+	s.documents[uri] = doc
+	params := publishDiagnosticsParams{URI: uri, Version: &doc.version, Diagnostics: []lspDiagnostic{}}
+	for n, l := range doc.lines {
+		for _, d := range l.diagnostics {
+			severity := severityError
+			if d.Severity == diagnostic.Warning {
+				severity = severityWarning
+			}
+			params.Diagnostics = append(params.Diagnostics, lspDiagnostic{
+				Range:    l.toRange(n, spanOf(d)),
+				Severity: severity,
+				Code:     string(d.Code),
+				Source:   serverName,
+				Message:  d.Message,
+			})
+		}
+	}
+	return s.publish(params)
+}
+
+// publish publishes the diagnostics of a document.
+func (s *server) publish(params publishDiagnosticsParams) error {
+	return s.notify("textDocument/publishDiagnostics", params)
+}
+
+// find returns the line of a document at a position and the byte of the line at that position; the first byte is
+// at 1, like the positions of spans.
+func (s *server) find(params positionParams) (*document, *line, int) {
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil, 0
+	}
+	l := doc.at(params.Position.Line)
+	if l == nil {
+		return nil, nil, 0
+	}
+	return doc, l, offset(l.text, params.Position.Character) + 1
+}
+
+// hover shows the value of the variable at a position or, elsewhere on a line, the result of the line; along with
+// the quantity of each.
+func (s *server) hover(params positionParams) *hover {
+	doc, l, pos := s.find(params)
+	if l == nil {
+		return nil
+	}
+	n := params.Position.Line
+	if ref, ok := l.reference(pos); ok {
+		def := doc.lines[ref.line]
+		return &hover{Contents: markdown(describe(fmt.Sprintf("%s = %s", ref.name, def.result), def.quantity)), Range: l.toRange(n, ref.span)}
+	}
+	if l.result == "" || pos > len(l.expr)+1 {
+		// the line is not valid, or the position is in a comment
+		return nil
+	}
+	if l.name != "" && contains(l.nameSpan, pos) {
+		return &hover{Contents: markdown(describe(fmt.Sprintf("%s = %s", l.name, l.result), l.quantity)), Range: l.toRange(n, l.nameSpan)}
+	}
+	return &hover{Contents: markdown(describe(l.result, l.quantity)), Range: l.exprRange(n)}
+}
+
+// markdown returns content that is shown as markdown.
+func markdown(value string) markupContent {
+	return markupContent{Kind: "markdown", Value: value}
+}
+
+// definition returns the assignment that gives the variable at a position its value.
+func (s *server) definition(params positionParams) *location {
+	doc, l, pos := s.find(params)
+	if l == nil {
+		return nil
+	}
+	if ref, ok := l.reference(pos); ok {
+		def := doc.lines[ref.line]
+		return &location{URI: doc.uri, Range: def.toRange(ref.line, def.nameSpan)}
+	}
+	if l.name != "" && contains(l.nameSpan, pos) {
+		return &location{URI: doc.uri, Range: l.toRange(params.Position.Line, l.nameSpan)}
+	}
+	return nil
+}
+
+// inlayHints shows the result of each line in a range after its expression.
+func (s *server) inlayHints(params inlayHintParams) []inlayHint {
+	hints := []inlayHint{}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return hints
+	}
+	for n := params.Range.Start.Line; n <= params.Range.End.Line; n++ {
+		l := doc.at(n)
+		if l == nil || l.result == "" {
+			continue
+		}
+		hints = append(hints, inlayHint{Position: l.exprRange(n).End, Label: "= " + l.result, PaddingLeft: true})
+	}
+	return hints
+}
+
+// completions returns the names of the known units; the client chooses those that match what is typed.
+func completions() []completionItem {
+	items := []completionItem{}
+	seen := map[string]bool{}
+	for _, unit := range calc.Units() {
+		detail := unit.Name
+		if unit.Quantity != "" {
+			detail = fmt.Sprintf("%s (%s)", unit.Name, unit.Quantity)
+		}
+		for _, label := range append([]string{unit.Name, unit.Plural, unit.Symbol}, unit.Aliases...) {
+			if label == "" || seen[label] {
+				continue
+			}
+			seen[label] = true
+			items = append(items, completionItem{Label: label, Kind: completionKindUnit, Detail: detail})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
@@ -0,0 +1,208 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+// a message written by the server
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// serves a session of messages, that each have their headers added, and returns the messages written by the server
+func session(t *testing.T, messages ...string) ([]message, error) {
+	var in, out bytes.Buffer
+	for _, m := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	err := Serve(&in, &out)
+	var written []message
+	reader := bufio.NewReader(&out)
+	for {
+		content, err := readMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		if !assert.Nil(t, err) {
+			break
+		}
+		var m message
+		assert.Nil(t, json.Unmarshal(content, &m))
+		written = append(written, m)
+	}
+	return written, err
+}
+
+// returns the response to a request
+func responseTo(messages []message, id int) *message {
+	for i := range messages {
+		if messages[i].ID != nil && *messages[i].ID == id {
+			return &messages[i]
+		}
+	}
+	return nil
+}
+
+// returns a request to open a document
+func open(text string) string {
+	item, _ := json.Marshal(textDocumentItem{URI: "file:///rent.qc", Version: 1, Text: text})
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":%s}}`, item)
+}
+
+// returns a request about a position of the document
+func at(id int, method string, line, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"%s","params":{"textDocument":{"uri":"file:///rent.qc"},"position":{"line":%d,"character":%d}}}`,
+		id, method, line, character)
+}
+
+const (
+	initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`
+	shutdown   = `{"jsonrpc":"2.0","id":99,"method":"shutdown"}`
+	exit       = `{"jsonrpc":"2.0","method":"exit"}`
+)
+
+func TestInitialize(t *testing.T) {
+	messages, err := session(t, initialize, `{"jsonrpc":"2.0","method":"initialized","params":{}}`, shutdown, exit)
+	assert.Nil(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.JSONEq(t, `{"capabilities":{"textDocumentSync":{"openClose":true,"change":1},"hoverProvider":true,"completionProvider":{},`+
+			`"definitionProvider":true,"inlayHintProvider":true},"serverInfo":{"name":"qcalc"}}`, string(messages[0].Result))
+		assert.Equal(t, "null", string(messages[1].Result))
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	_, err := session(t, initialize, exit)
+	assert.Equal(t, ErrNoShutdown, err)
+}
+
+func TestAfterShutdown(t *testing.T) {
+	messages, err := session(t, shutdown, at(2, "textDocument/hover", 0, 0), exit)
+	assert.Nil(t, err)
+	assert.Equal(t, codeInvalidRequest, responseTo(messages, 2).Error.Code)
+}
+
+func TestUnknownMethod(t *testing.T) {
+	messages, err := session(t,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/formatting","params":{}}`,
+		`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":2}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":[]}`,
+		`{"jsonrpc":`)
+	assert.Nil(t, err)
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, codeMethodNotFound, messages[0].Error.Code)
+		assert.Equal(t, codeInvalidParams, messages[1].Error.Code)
+		assert.Nil(t, messages[2].ID)
+		assert.Equal(t, codeParseError, messages[2].Error.Code)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	change := `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///rent.qc","version":2},` +
+		`"contentChanges":[{"text":"rent = 1200 USD"}]}}`
+	close := `{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"file:///rent.qc"}}}`
+	messages, err := session(t, open(testSheet), change, close)
+	assert.Nil(t, err)
+	if !assert.Len(t, messages, 3) {
+		return
+	}
+	for _, m := range messages {
+		assert.Equal(t, "textDocument/publishDiagnostics", m.Method)
+	}
+	assert.JSONEq(t, `{"uri":"file:///rent.qc","version":1,"diagnostics":[`+
+		`{"range":{"start":{"line":3,"character":9},"end":{"line":3,"character":11}},"severity":1,"code":"QC1003","source":"qcalc","message":"cannot convert from kg to USD"},`+
+		`{"range":{"start":{"line":4,"character":9},"end":{"line":4,"character":14}},"severity":1,"code":"QC1001","source":"qcalc","message":"'punds' is not a known measurement unit"},`+
+		`{"range":{"start":{"line":4,"character":18},"end":{"line":4,"character":23}},"severity":1,"code":"QC1001","source":"qcalc","message":"'mlies' is not a known measurement unit"}]}`,
+		string(messages[0].Params))
+	assert.JSONEq(t, `{"uri":"file:///rent.qc","version":2,"diagnostics":[]}`, string(messages[1].Params))
+	assert.JSONEq(t, `{"uri":"file:///rent.qc","diagnostics":[]}`, string(messages[2].Params))
+}
+
+func TestHover(t *testing.T) {
+	tests := map[string]struct {
+		line      int
+		character int
+		expected  string
+	}{
+		"variable": {
+			2, 17,
+			`{"contents":{"kind":"markdown","value":"rent = 1200.00 USD\n\ncurrency"},"range":{"start":{"line":2,"character":15},"end":{"line":2,"character":19}}}`,
+		},
+		"assignment": {
+			2, 3,
+			`{"contents":{"kind":"markdown","value":"per person = 400.00 USD\n\ncurrency"},"range":{"start":{"line":2,"character":2},"end":{"line":2,"character":12}}}`,
+		},
+		"line": {
+			5, 3,
+			`{"contents":{"kind":"markdown","value":"4.41 lb\n\nmass"},"range":{"start":{"line":5,"character":0},"end":{"line":5,"character":10}}}`,
+		},
+		"comment":      {1, 20, `null`},
+		"invalid":      {4, 2, `null`},
+		"missing line": {20, 0, `null`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			messages, err := session(t, open(testSheet+"\n2 kg in lb"), at(2, "textDocument/hover", test.line, test.character))
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.expected, string(responseTo(messages, 2).Result))
+		})
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	tests := map[string]struct {
+		line      int
+		character int
+		expected  string
+	}{
+		"reference":  {2, 15, `{"uri":"file:///rent.qc","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":4}}}`},
+		"invalid":    {3, 0, `{"uri":"file:///rent.qc","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":4}}}`},
+		"assignment": {1, 2, `{"uri":"file:///rent.qc","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":4}}}`},
+		"value":      {1, 10, `null`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			messages, err := session(t, open(testSheet), at(2, "textDocument/definition", test.line, test.character))
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.expected, string(responseTo(messages, 2).Result))
+		})
+	}
+}
+
+func TestInlayHints(t *testing.T) {
+	hints := `{"jsonrpc":"2.0","id":2,"method":"textDocument/inlayHint","params":{"textDocument":{"uri":"file:///rent.qc"},` +
+		`"range":{"start":{"line":0,"character":0},"end":{"line":4,"character":0}}}}`
+	messages, err := session(t, open(testSheet), hints)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[`+
+		`{"position":{"line":1,"character":15},"label":"= 1200.00 USD","paddingLeft":true},`+
+		`{"position":{"line":2,"character":23},"label":"= 400.00 USD","paddingLeft":true}]`,
+		string(responseTo(messages, 2).Result))
+}
+
+func TestCompletion(t *testing.T) {
+	messages, err := session(t, open(testSheet), at(2, "textDocument/completion", 0, 0))
+	assert.Nil(t, err)
+	var items []completionItem
+	assert.Nil(t, json.Unmarshal(responseTo(messages, 2).Result, &items))
+	labels := map[string]string{}
+	for _, item := range items {
+		assert.Equal(t, completionKindUnit, item.Kind)
+		labels[item.Label] = item.Detail
+	}
+	assert.Equal(t, "kilogram (mass)", labels["kg"])
+	assert.Equal(t, "kilogram (mass)", labels["kilograms"])
+	assert.Equal(t, "pound (mass)", labels["pounds"])
+	assert.True(t, strings.HasSuffix(labels["USD"], "(currency)"))
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"github.com/nickwallen/quick-calc/internal/types"
+	"sort"
+)
+
+// Diagnose parses a series of tokens and returns every problem found, rather than just the first, so
+// that each can be shown at once; for example, underlined in an editor. After each problem, the parser
+// recovers by assuming missing units or by skipping ahead to the next operator. Nothing is returned
+// when the input is valid. Read the tokens from a tokenizer with recovery so that it does not stop at
+// the first bad token.
+func Diagnose(reader tokenReader, opts ...Option) []types.InputError {
+	p := newParser(reader, opts...)
+	p.recovery = true
+	if _, err := p.parse(); err != nil {
+		// not expected while recovering, but reported all the same
+		p.report(err)
+	}
+	p.checkConversions()
+	sort.SliceStable(p.errors, func(i, j int) bool {
+		left, _ := p.errors[i].Position()
+		right, _ := p.errors[j].Position()
+		return left < right
+	})
+	return p.errors
+}
+
+// fail returns a problem, unless recovering; then the problem is reported and nil is returned so that
+// the caller can recover from it.
+func (p *parser) fail(err types.InputError) types.InputError {
+	if err == nil || !p.recovery {
+		return err
+	}
+	p.report(err)
+	return nil
+}
+
+// report keeps track of a problem; only the first problem at each position is reported.
+func (p *parser) report(err types.InputError) {
+	if err == nil {
+		return
+	}
+	p.problems++
+	if p.stopped {
+		return
+	}
+	start, _ := err.Position()
+	for _, prev := range p.errors {
+		if prevStart, _ := prev.Position(); prevStart == start {
+			return
+		}
+	}
+	p.errors = append(p.errors, err)
+}
+
+// stop reports a problem after which nothing more is read; like when there are too many tokens.
+func (p *parser) stop(err types.InputError) {
+	p.report(err)
+	p.stopped = true
+}
+
+// skip skips over tokens until the next operator, conversion or the end of the input. A group that follows,
+// like the '(2 kg)' in '-(2 kg)', is skipped as a whole.
+func (p *parser) skip() {
+	groups := 0
+	for {
+		token, _ := p.readToken()
+		switch token.TokenType {
+		case types.LeftParen:
+			groups++
+		case types.RightParen:
+			if groups == 0 {
+				p.unreadToken(token)
+				return
+			}
+			groups--
+		case types.Plus, types.Minus, types.Multiply, types.Divide, types.In:
+			if groups == 0 {
+				p.unreadToken(token)
+				return
+			}
+		case types.EOF:
+			p.unreadToken(token)
+			return
+		case types.Error:
+			p.report(types.ErrorTokenizerError(p.input(), token))
+		}
+	}
+}
+
+// checkConversions reports each operand of the outermost sum that cannot be evaluated, or converted to the units of
+// the first operand, and then the first operand that cannot be converted to the target units or radix; as an
+// evaluation would.
+func (p *parser) checkConversions() {
+	var first types.Expression
+	for _, operand := range p.operands {
+		expr := p.expression(operand)
+		if first != nil {
+			// as a sum would, so that an operand with no units is reported like 'ratio' in 'ratio + 3 kg'
+			p.checkConversion(types.AdditionExpr(first, expr).InRegion(p.region))
+			continue
+		}
+		if _, err := expr.EvalContext(p.ctx, p.input()); err != nil {
+			p.report(err)
+			continue
+		}
+		first = expr
+	}
+	if first == nil {
+		return
+	}
+	if p.target != nil {
+		p.checkConversion(types.UnitConversionExpr(first, *p.target).InRegion(p.region))
+	}
+	if p.radix != nil {
+		p.checkConversion(types.RadixConversionExpr(first, *p.radix))
+	}
+}
+
+// checkConversion reports a conversion that cannot be evaluated.
+func (p *parser) checkConversion(conversion types.Expression) {
+	_, err := conversion.EvalContext(p.ctx, p.input())
+	p.report(err)
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// a problem that should be found; the position of the problem and its message
+type problem struct {
+	position int
+	message  string
+}
+
+var diagnoseExpressions = map[string][]problem{
+	"2 kg + 3 lbs":         nil,
+	"10..12 m in feet":     nil,
+	"5 bytes in hex":       nil,
+	"255 in hex":           nil,
+	"2.5 in hex":           {{8, "cannot display 2.5 in hex; only whole numbers of bytes or bits"}},
+	"5 kg in hex":          {{9, "cannot display kg in hex; only whole numbers of bytes or bits"}},
+	"2 kg + 3 pd + 4 gmz":  {{10, "'pd' is not a known measurement unit"}, {17, "'gmz' is not a known measurement unit"}},
+	"2 + 3 kg":             {{3, "got '+', but expected a unit"}},
+	"kg + 2 kg":            {{1, "got 'kg', but expected a number"}},
+	"2 kg 3 lbs + 4 ozz":   {{6, "got '3', but expected '+', '-', '*', '/', 'in'"}, {16, "'ozz' is not a known measurement unit"}},
+	"2 kg * 3 kg - 4 ozz":  {{10, "cannot multiply kg by kg"}, {17, "'ozz' is not a known measurement unit"}},
+	"(2 kg + 3 m) + 4 ozz": {{11, "cannot convert from m to kg"}, {18, "'ozz' is not a known measurement unit"}},
+	"(2 kg + 3 lbz + 1 g":  {{11, "'lbz' is not a known measurement unit"}, {20, "reached end of input, but expected ')'"}},
+	"2 m * 3 m + 4 kg":     {{15, "cannot convert from kg to m^2"}},
+	"2 kg * 3 / 4 + 1 lb":  nil,
+	"2 kg / kg + 3 gz":     {{8, "expected number, but got 'k'"}, {15, "'gz' is not a known measurement unit"}},
+	"0x + 2 kgg":           {{1, "expected number, but got '0x'"}, {8, "'kgg' is not a known measurement unit"}},
+	"2 kg + 3 ? kg + 4 lb": {{10, "expected symbol, but got '?'"}},
+	"2 kg in mts foo":      {{9, "'mts' is not a known measurement unit"}, {13, "got 'foo', but expected end of input"}},
+	"2 kg +":               {{7, "expected number, but got ''"}},
+	"2 kg in":              {{8, "reached end of input, but expected a unit"}},
+	"-(2 kg) + 3 lbz":      {{1, "'-' cannot negate a group; multiply it by -1 instead"}, {13, "'lbz' is not a known measurement unit"}},
+	"3..x kg + 2 lbz":      {{4, "expected number, but got 'x'"}, {13, "'lbz' is not a known measurement unit"}},
+	"2 miles + 3 lb + 4 l in kg": {
+		{3, "cannot convert from miles to kg"},
+		{13, "cannot convert from lb to miles"},
+		{20, "cannot convert from l to miles"},
+	},
+}
+
+func TestDiagnoseWithVariables(t *testing.T) {
+	tests := map[string][]problem{
+		"total = rent + 2 kg":     nil,
+		"total = rent + 2 miles":  {{18, "cannot convert from miles to kg"}},
+		"per person in g":         nil,
+		"per person + 2 kgg":      {{16, "'kgg' is not a known measurement unit"}},
+		"2 kg + rent in miles":    {{3, "cannot convert from kg to miles"}},
+		"kg = 2 kg + 3 lbz":       {{1, "'kg' is a unit or keyword, so cannot name a variable"}, {15, "'lbz' is not a known measurement unit"}},
+		"total = 2 kg + rent = 2": {{21, "expected symbol, but got '='"}, {23, "got '2', but expected '+', '-', '*', '/', 'in', end of input"}},
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			lexer := tokenizer.NewLexer(input, tokenizer.WithRecovery(), tokenizer.WithVariables([]string{"rent", "per person"}))
+			var actual []problem
+			for _, err := range Diagnose(lexer, WithVariables(testVars)) {
+				start, _ := err.Position()
+				actual = append(actual, problem{start, err.Error()})
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	for input, expected := range diagnoseExpressions {
+		t.Run(input, func(t *testing.T) {
+			errs := Diagnose(tokenizer.NewLexer(input, tokenizer.WithRecovery()))
+			var actual []problem
+			for _, err := range errs {
+				position, _ := err.Position()
+				actual = append(actual, problem{position, err.Error()})
+				assert.Equal(t, input, err.Input())
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestDiagnoseWithMaxTokens(t *testing.T) {
+	input := "2 kgg + 3 kg + 4 kg"
+	errs := Diagnose(tokenizer.NewLexer(input, tokenizer.WithRecovery()), WithMaxTokens(4))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "'kgg' is not a known measurement unit", errs[0].Error())
+	assert.Equal(t, "the input has more than 4 tokens", errs[1].Error())
+}
+
+func TestDiagnoseWithMaxDepth(t *testing.T) {
+	input := "2 kgg + 3 kg * 2 + 4 lbz"
+	errs := Diagnose(tokenizer.NewLexer(input, tokenizer.WithRecovery()), WithMaxDepth(1))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "'kgg' is not a known measurement unit", errs[0].Error())
+	assert.IsType(t, &types.TooDeep{}, errs[1])
+}
+
+func TestDiagnoseWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	input := "2 kgg + 3 lbz"
+	errs := Diagnose(tokenizer.NewLexer(input, tokenizer.WithRecovery()), WithContext(ctx))
+	assert.Len(t, errs, 1)
+	assert.IsType(t, &types.Cancelled{}, errs[0])
+}
@@ -1,9 +1,15 @@
 package parser
 
 import (
-	u "github.com/bcicen/go-units"
+	"context"
+	"errors"
+	"github.com/nickwallen/quick-calc/ast"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/registry"
 	"github.com/nickwallen/quick-calc/internal/types"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 // the interface used by the parser to read tokens
@@ -15,129 +21,806 @@ type tokenReader interface {
 	Input() string
 }
 
+// a reader of tokens that is cancelled once the parser stops reading; like a token channel, whose writer
+// would otherwise be blocked writing tokens that are never read
+type cancellableReader interface {
+	tokenReader
+	Cancel()
+}
+
+// parser A parser builds an expression from a series of tokens.
+type parser struct {
+	reader    tokenReader             // the reader of tokens
+	locale    locale.Locale           // governs the decimal and grouping separators of numbers
+	region    registry.Region         // the preferred units when units are ambiguous like 'gal'
+	ctx       context.Context         // stops the parser once done
+	maxTokens int                     // the maximum number of tokens; 0 if unlimited
+	maxDepth  int                     // the maximum number of nested operations; 0 if unlimited
+	maxDigits int                     // the maximum number of significant digits in a number; 0 if unlimited
+	vars      map[string]types.Amount // the value of each variable by name
+	tokens    int                     // the number of tokens read
+	depth     int                     // the number of nested operations
+	unread    []types.Token           // the tokens to read again, last first
+	recovery  bool                    // keeps going after a problem, rather than stopping; see Diagnose
+	errors    []types.InputError      // the problems found while recovering
+	stopped   bool                    // true once the input cannot be read any further while recovering
+	groups    int                     // the number of groups, like '(2 ft + 3 in)', that are open
+	problems  int                     // the number of problems found while recovering, including those not kept
+	operands  []ast.Node              // the operands of the outermost sum without problems, found while recovering
+	target    *types.Token            // the valid units that the expression is converted to, if any
+	radix     *types.Token            // the radix that the expression is converted to, if any
+}
+
+// Option configures the parser.
+type Option func(*parser)
+
+// WithLocale sets the locale that governs how numbers are written.
+func WithLocale(loc locale.Locale) Option {
+	return func(p *parser) {
+		p.locale = loc
+	}
+}
+
+// WithRegion sets the region whose units are preferred when units are ambiguous like 'gal'.
+func WithRegion(region registry.Region) Option {
+	return func(p *parser) {
+		p.region = region
+	}
+}
+
+// WithContext sets a context that stops the parser once done.
+func WithContext(ctx context.Context) Option {
+	return func(p *parser) {
+		p.ctx = ctx
+	}
+}
+
+// WithMaxTokens limits the number of tokens in the input, excluding EOF.
+func WithMaxTokens(limit int) Option {
+	return func(p *parser) {
+		p.maxTokens = limit
+	}
+}
+
+// WithMaxDepth limits the number of nested operations; '1 kg + 2 kg + 3 kg' has two.
+func WithMaxDepth(limit int) Option {
+	return func(p *parser) {
+		p.maxDepth = limit
+	}
+}
+
+// WithMaxDigits limits the number of significant digits in a decimal number.
+func WithMaxDigits(limit int) Option {
+	return func(p *parser) {
+		p.maxDigits = limit
+	}
+}
+
+// WithVariables sets the value of each variable that can be used in place of a value; like 'rent'.
+func WithVariables(vars map[string]types.Amount) Option {
+	return func(p *parser) {
+		p.vars = vars
+	}
+}
+
 // Parse a series of tokens and returns an expression.
-func Parse(reader tokenReader) (types.Expression, types.InputError) {
-	var expr types.Expression
+func Parse(reader tokenReader, opts ...Option) (types.Expression, types.InputError) {
+	p := newParser(reader, opts...)
+	node, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return p.expression(node), nil
+}
 
-	// an expression should start with a value like '23 pounds'
-	value1, err := expectValue(reader)
+// ParseAssignment parses a series of tokens and returns an expression along with the name of the variable that its
+// value is assigned to, like 'rent' in 'rent = 1200 USD'; the name is empty unless the input is an assignment.
+func ParseAssignment(reader tokenReader, opts ...Option) (string, types.Expression, types.InputError) {
+	p := newParser(reader, opts...)
+	node, err := p.parse()
 	if err != nil {
-		return expr, err
+		return "", nil, err
 	}
-	nextToken, readErr := reader.ReadToken()
-	if readErr != nil {
-		return expr, types.ErrorReadFailed(reader.Input(), readErr)
-	}
-	switch nextToken.TokenType {
-	case types.Plus, types.Minus:
-		return expectOperation(reader, value1, nextToken)
-	case types.In:
-		return expectConversion(reader, value1)
-	case types.EOF:
-		return value1, nil
-	default:
-		return expr, types.ErrorUnexpectedToken(reader.Input(), nextToken, types.Plus, types.Minus, types.In)
+	name := ""
+	if assignment, ok := node.(*ast.Assignment); ok {
+		name = assignment.Name.Value
 	}
+	return name, p.expression(node), nil
 }
 
-func expectConversion(reader tokenReader, from types.Expression) (expr types.Expression, err types.InputError) {
-	// expect the units to convert to
-	units, err := expectUnits(reader)
+// ParseTree parses a series of tokens and returns the syntax tree of the expression.
+func ParseTree(reader tokenReader, opts ...Option) (ast.Node, types.InputError) {
+	node, err := newParser(reader, opts...).parse()
 	if err != nil {
-		return expr, err
+		return nil, err
+	}
+	return node, nil
+}
+
+func newParser(reader tokenReader, opts ...Option) *parser {
+	p := &parser{
+		reader: reader,
+		locale: locale.Default,
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// parse parses the input and then cancels the reader, if it can be cancelled, as no more tokens are read.
+func (p *parser) parse() (node ast.Node, err types.InputError) {
+	if reader, ok := p.reader.(cancellableReader); ok {
+		defer reader.Cancel()
 	}
-	// expect EOF
-	_, err = nextToken(reader, types.EOF)
+	// an assignment like 'rent = 1200 USD' starts with the name of a variable
+	token, err := p.readToken()
 	if err != nil {
-		return expr, err
+		return node, err
+	}
+	if token.TokenType == types.Name {
+		next, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		if next.TokenType == types.Assign {
+			if p.reserved(token.Value) {
+				if err := p.fail(types.ErrorReservedName(p.input(), token)); err != nil {
+					return node, err
+				}
+			}
+			expr, err := p.expectExpression()
+			if err != nil {
+				return node, err
+			}
+			return &ast.Assignment{Name: astToken(token), Assign: astToken(next), Expr: expr}, nil
+		}
+		// otherwise the expression starts with a variable
+		p.unreadToken(next)
+	}
+	p.unreadToken(token)
+	return p.expectExpression()
+}
+
+// reserved returns true if a variable cannot have a name since the variable would hide units, like 'kg', a
+// keyword for a conversion, like 'in', or a radix like 'hex'.
+func (p *parser) reserved(name string) bool {
+	if _, err := registry.FindIn(name, p.locale); err == nil {
+		return true
+	}
+	for _, loc := range locale.All() {
+		for _, keyword := range loc.Keywords {
+			if strings.EqualFold(name, keyword) {
+				return true
+			}
+		}
 	}
-	return types.UnitConversionExpr(from, units), nil
+	return types.IsRadix(name)
 }
 
-func expectOperation(reader tokenReader, prevValue types.Expression, operator types.Token) (expr types.Expression, err types.InputError) {
-	// expect the next operand in the expression
-	nextValue, err := expectValue(reader)
+// expectExpression expects an expression like '2 kg + 3 lbs in g'.
+func (p *parser) expectExpression() (node ast.Node, err types.InputError) {
+	node, err = p.expectSum()
 	if err != nil {
-		return expr, err
+		return node, err
 	}
-	// we have an expression like prevValue + nextValue
-	expr, err = operationExpr(operator, prevValue, nextValue, reader.Input())
+	// the sum ends with a conversion or the end of the input
+	token, err := p.readToken()
 	if err != nil {
-		return expr, err
+		return node, err
 	}
-	token, readErr := reader.ReadToken()
-	if readErr != nil {
-		return expr, types.ErrorReadFailed(reader.Input(), readErr)
+	if token.TokenType == types.In {
+		return p.expectConversion(node, token)
+	}
+	return node, nil
+}
+
+// expectSum expects values that are added or subtracted, like '2 kg + 3 lbs - 4 oz', up to a conversion or
+// the end of the input. While recovering, an unexpected token is skipped along with the rest of its value.
+func (p *parser) expectSum() (node ast.Node, err types.InputError) {
+	node, err = p.expectTerm()
+	if err != nil {
+		return node, err
+	}
+	operations := 0
+	for {
+		token, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		switch token.TokenType {
+		case types.Plus, types.Minus:
+			if err := p.deeper(token); err != nil {
+				return node, err
+			}
+			right, err := p.expectTerm()
+			if err != nil {
+				return node, err
+			}
+			node, err = operationNode(token, node, right, p.input())
+			if err != nil {
+				return node, err
+			}
+			operations++
+		case types.Multiply, types.Divide:
+			// only once recovering has skipped to a factor; like '* 2' in '2 kg 3 lbs * 2'
+			p.unreadToken(token)
+			if node, err = p.expectFactors(node); err != nil {
+				return node, err
+			}
+		case types.In, types.EOF:
+			p.unreadToken(token)
+			return node, nil
+		case types.RightParen:
+			if p.groups > 0 {
+				p.unreadToken(token)
+				return node, nil
+			}
+			// a group that is not open; like the ')' in '2 kg) + 3 kg'
+			if err := p.fail(types.ErrorUnexpectedToken(p.input(), token, p.expectedAfter(operations)...)); err != nil {
+				return node, err
+			}
+		case types.Error:
+			if p.recovery {
+				p.report(types.ErrorTokenizerError(p.input(), token))
+				continue
+			}
+			fallthrough
+		default:
+			// like '3 lbs' in '2 kg 3 lbs + 4 oz'
+			if err := p.fail(types.ErrorUnexpectedToken(p.input(), token, p.expectedAfter(operations)...)); err != nil {
+				return node, err
+			}
+			p.skip()
+		}
+	}
+}
+
+// expectedAfter returns the tokens that are expected after an operand of a sum with a number of operations.
+func (p *parser) expectedAfter(operations int) []types.TokenType {
+	expected := []types.TokenType{types.Plus, types.Minus, types.Multiply, types.Divide, types.In}
+	if p.groups > 0 {
+		return append(expected, types.RightParen)
+	}
+	if operations > 0 {
+		return append(expected, types.EOF)
+	}
+	return expected
+}
+
+// expectTerm expects an operand of a sum. While recovering, the operands of the outermost sum that have no
+// problems are kept so that their units can be checked; see checkConversions.
+func (p *parser) expectTerm() (node ast.Node, err types.InputError) {
+	problems := p.problems
+	node, err = p.expectValue()
+	if err == nil && p.recovery && p.groups == 0 && p.problems == problems {
+		p.operands = append(p.operands, node)
+	}
+	return node, err
+}
+
+func (p *parser) expectConversion(from ast.Node, keyword types.Token) (node ast.Node, err types.InputError) {
+	token, err := p.readToken()
+	if err != nil {
+		return node, err
+	}
+	// expect the units to convert to or a radix like 'hex'
+	radix := token.TokenType == types.Units && types.IsRadix(token.Value)
+	if radix {
+		p.radix = &token
+	} else if units, err := p.checkUnits(token); err != nil {
+		if err := p.fail(err); err != nil {
+			return node, err
+		}
+	} else {
+		p.target = &units
+	}
+	node = &ast.Conversion{Expr: from, Keyword: astToken(keyword), Target: astToken(token), Radix: radix}
+	return node, p.expectEOF()
+}
+
+// expectEOF expects the end of the input. While recovering, only the first unexpected token is reported.
+func (p *parser) expectEOF() types.InputError {
+	unexpected := false
+	for {
+		token, err := p.readToken()
+		if err != nil {
+			return err
+		}
+		switch {
+		case token.TokenType == types.EOF:
+			return nil
+		case token.TokenType == types.Error:
+			if err := p.fail(types.ErrorTokenizerError(p.input(), token)); err != nil {
+				return err
+			}
+		case !unexpected:
+			if err := p.fail(types.ErrorUnexpectedToken(p.input(), token, types.EOF)); err != nil {
+				return err
+			}
+			unexpected = true
+		}
+	}
+}
+
+// expectValue expects a value, interval or variable that may be multiplied or divided; like '3 lbs * 2'.
+func (p *parser) expectValue() (node ast.Node, err types.InputError) {
+	node, err = p.expectOperand()
+	if err != nil {
+		return node, err
+	}
+	return p.expectFactors(node)
+}
+
+// expectFactors expects any number of factors that multiply or divide an operand; like '* 2 / 3'. A factor
+// may also be an amount like the '3 m' in '2 m * 3 m'.
+func (p *parser) expectFactors(operand ast.Node) (node ast.Node, err types.InputError) {
+	for {
+		token, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		if token.TokenType != types.Multiply && token.TokenType != types.Divide {
+			p.unreadToken(token)
+			return operand, nil
+		}
+		if err := p.deeper(token); err != nil {
+			return node, err
+		}
+		operator := ast.Multiply
+		if token.TokenType == types.Divide {
+			operator = ast.Divide
+		}
+		factor, err := p.expectFactor()
+		if err != nil {
+			return node, err
+		}
+		if factor != nil {
+			operand = &ast.Operation{Operator: operator, OperatorToken: astToken(token), Left: operand, Right: factor}
+			continue
+		}
+		factorToken, number, err := p.expectNumber()
+		if err := p.fail(err); err != nil {
+			return node, err
+		}
+		operand = &ast.Scaling{
+			Expr:          operand,
+			Operator:      operator,
+			OperatorToken: astToken(token),
+			Factor:        number,
+			FactorToken:   astToken(factorToken),
+		}
+	}
+}
+
+// expectFactor expects an amount, variable or group that multiplies or divides an operand; nil is returned,
+// and nothing is read, if the factor is just a number like the '2' in '3 lbs * 2'.
+func (p *parser) expectFactor() (node ast.Node, err types.InputError) {
+	token, err := p.readToken()
+	if err != nil {
+		return node, err
 	}
 	switch token.TokenType {
-	case types.Plus, types.Minus:
-		// the operation has more operands; prevValue + nextValue + ...
-		return expectOperation(reader, expr, token)
-	case types.In:
-		return expectConversion(reader, expr)
-	case types.EOF:
-		return expr, nil
+	case types.Name, types.LeftParen:
+		p.unreadToken(token)
+		return p.expectOperand()
+	case types.Number:
+		next, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		if next.TokenType == types.Units || next.TokenType == types.Range {
+			p.unreadToken(next)
+			return p.expectAmount(token)
+		}
+		p.unreadToken(next)
+	}
+	p.unreadToken(token)
+	return nil, nil
+}
+
+// expectGroup expects the rest of a group like '(2 ft + 3 in)' after its opening parenthesis. While
+// recovering, a missing closing parenthesis is assumed so that parsing can go on.
+func (p *parser) expectGroup(open types.Token) (node ast.Node, err types.InputError) {
+	if err := p.deeper(open); err != nil {
+		return node, err
+	}
+	p.groups++
+	expr, err := p.expectSum()
+	p.groups--
+	if err != nil {
+		return node, err
+	}
+	token, err := p.readToken()
+	if err != nil {
+		return node, err
+	}
+	closed, err := p.checkToken(token, types.RightParen)
+	if err := p.fail(err); err != nil {
+		return node, err
+	}
+	if closed.TokenType != types.RightParen {
+		p.unreadToken(token)
+		closed = types.RightParen.TokenAt("", token.Position)
+	}
+	return &ast.Group{Open: astToken(open), Expr: expr, Close: astToken(closed)}, nil
+}
+
+// expectOperand expects a value like '2 kg', an interval like '3..5 kg' or a variable like 'rent'. While
+// recovering, anything else is skipped; like the second '+' in '2 kg + + 3 kg'.
+func (p *parser) expectOperand() (node ast.Node, err types.InputError) {
+	for {
+		token, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		switch token.TokenType {
+		case types.Name:
+			// a variable can be used in place of a value
+			node, err := p.checkVariable(token)
+			return node, p.fail(err)
+		case types.LeftParen:
+			return p.expectGroup(token)
+		case types.Number:
+			return p.expectAmount(token)
+		case types.Units:
+			// the number is missing; like 'kg + 2 kg'
+			if err := p.fail(types.ErrorUnexpectedToken(p.input(), token, types.Number)); err != nil {
+				return node, err
+			}
+			_, err := p.expectUnits(token)
+			return node, err
+		case types.Error:
+			// the value is not valid; like '0x' in '0x + 2 kg'
+			if err := p.fail(types.ErrorTokenizerError(p.input(), token)); err != nil {
+				return node, err
+			}
+			p.skip()
+			return node, nil
+		case types.EOF:
+			return node, p.fail(types.ErrorUnexpectedEOF(p.input(), token, types.Number))
+		default:
+			if err := p.fail(types.ErrorUnexpectedToken(p.input(), token, types.Number)); err != nil {
+				return node, err
+			}
+		}
+	}
+}
+
+// expectAmount expects the rest of a value like '2 kg', or of an interval like '3..5 kg', after its number.
+func (p *parser) expectAmount(numberToken types.Token) (node ast.Node, err types.InputError) {
+	number, err := p.checkNumber(numberToken)
+	if err := p.fail(err); err != nil {
+		return node, err
+	}
+	token, err := p.readToken()
+	if err != nil {
+		return node, err
+	}
+	// a number may multiply what follows it; like '2 * rent'
+	if token.TokenType == types.Multiply {
+		return p.expectScaled(numberToken, number, token)
+	}
+	// an interval like '3..5 kg' has an upper bound
+	if token.TokenType == types.Range {
+		upperToken, upper, err := p.expectNumber()
+		if err := p.fail(err); err != nil {
+			return node, err
+		}
+		token, err := p.readToken()
+		if err != nil {
+			return node, err
+		}
+		units, err := p.expectAmountUnits(token)
+		if err != nil {
+			return node, err
+		}
+		return &ast.Interval{
+			Lower:      number,
+			LowerToken: astToken(numberToken),
+			Upper:      upper,
+			UpperToken: astToken(upperToken),
+			Units:      astToken(units),
+		}, nil
+	}
+	units, err := p.expectAmountUnits(token)
+	if err != nil {
+		return node, err
+	}
+	return &ast.Value{Number: number, NumberToken: astToken(numberToken), Units: astToken(units)}, nil
+}
+
+// expectScaled expects the operand that a number multiplies; like 'rent' in '2 * rent'.
+func (p *parser) expectScaled(numberToken types.Token, number float64, operator types.Token) (node ast.Node, err types.InputError) {
+	if err := p.deeper(operator); err != nil {
+		return node, err
+	}
+	expr, err := p.expectOperand()
+	if err != nil {
+		return node, err
+	}
+	return &ast.Scaling{
+		Expr:          expr,
+		Operator:      ast.Multiply,
+		OperatorToken: astToken(operator),
+		Factor:        number,
+		FactorToken:   astToken(numberToken),
+		FactorFirst:   true,
+	}, nil
+}
+
+// expectAmountUnits expects the units of a value or interval. A number that is displayed in a radix, like
+// '255 in hex', has no units.
+func (p *parser) expectAmountUnits(token types.Token) (units types.Token, err types.InputError) {
+	if token.TokenType == types.In {
+		next, err := p.readToken()
+		if err != nil {
+			return units, err
+		}
+		p.unreadToken(next)
+		if next.TokenType == types.Units && types.IsRadix(next.Value) {
+			p.unreadToken(token)
+			return types.Units.TokenAt("", token.Position), nil
+		}
+	}
+	return p.expectUnits(token)
+}
+
+// expectUnits expects the units of a value. While recovering, missing units are assumed so that parsing can go on.
+func (p *parser) expectUnits(token types.Token) (units types.Token, err types.InputError) {
+	units, err = p.checkUnits(token)
+	if err == nil || !p.recovery {
+		return units, err
+	}
+	p.report(err)
+	switch token.TokenType {
+	case types.Error:
+		// skip what remains of the value; like '? kg' in '2 kg + 3 ? kg'
+		p.skip()
+	case types.Units:
+		// the units are not known; like 'kgg'
 	default:
-		return expr, types.ErrorUnexpectedToken(reader.Input(), token, types.Plus, types.Minus, types.In, types.EOF)
+		// the units are missing; like '2 + 3 kg'
+		p.unreadToken(token)
 	}
+	return token, nil
 }
 
-func expectValue(reader tokenReader) (expr types.Expression, err types.InputError) {
-	token, err := nextToken(reader, types.Number)
+// expectNumber expects a number. While recovering, a missing number is assumed so that parsing can go on.
+func (p *parser) expectNumber() (token types.Token, number float64, err types.InputError) {
+	token, err = p.nextToken(types.Number)
 	if err != nil {
-		return expr, err
+		if p.recovery && token.TokenType != types.Number {
+			p.unreadToken(token)
+		}
+		return token, number, err
+	}
+	number, err = p.checkNumber(token)
+	return token, number, err
+}
+
+// checkNumber ensures that a number token contains a valid number.
+func (p *parser) checkNumber(token types.Token) (number float64, err types.InputError) {
+	number, parseErr := p.parseNumber(token.Value)
+	if errors.Is(parseErr, locale.ErrAmbiguousGrouping) {
+		return number, types.ErrorAmbiguousNumber(p.input(), token, p.locale.Decimal, p.locale.Group)
 	}
-	// TODO where to handle hexadecimal vs decimal?
-	number, parseErr := strconv.ParseFloat(token.Value, 64)
 	if parseErr != nil {
-		return expr, types.ErrorInvalidNumber(reader.Input(), token)
+		return number, types.ErrorInvalidNumber(p.input(), token)
 	}
-	units, err := expectUnits(reader)
-	if err != nil {
-		return expr, err
+	if p.maxDigits > 0 && !isPrefixed(token.Value) && significantDigits(token.Value) > p.maxDigits {
+		return number, types.ErrorTooManyDigits(p.input(), token, p.maxDigits)
+	}
+	return number, nil
+}
+
+// parseNumber parses a decimal number or an integer with a prefix like '0x', '0o' or '0b'. A prefixed integer
+// may be as large as an unsigned 64-bit integer, like '0xFFFFFFFFFFFFFFFF'.
+func (p *parser) parseNumber(value string) (float64, error) {
+	if isPrefixed(value) {
+		digits := strings.TrimLeft(value, "+-")
+		number, err := strconv.ParseUint(digits, 0, 64)
+		if strings.HasPrefix(value, "-") {
+			return -float64(number), err
+		}
+		return float64(number), err
+	}
+	return p.locale.ParseNumber(value)
+}
+
+// isPrefixed returns true if a number has a prefix like '0x' for hexadecimal.
+func isPrefixed(value string) bool {
+	digits := strings.TrimLeft(value, "+-")
+	return len(digits) > 1 && digits[0] == '0' && strings.ContainsAny(digits[1:2], "xXoObB")
+}
+
+// significantDigits counts the digits of a decimal number, ignoring leading and trailing zeros and the exponent.
+func significantDigits(value string) int {
+	mantissa := value
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		mantissa = value[:i]
 	}
-	expr = types.NewValue(number, units)
-	return expr, nil
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, mantissa)
+	return len(strings.Trim(digits, "0"))
 }
 
-func expectUnits(reader tokenReader) (units types.Token, err types.InputError) {
-	token, err := nextToken(reader, types.Units)
+// checkUnits ensures that a token contains valid units.
+func (p *parser) checkUnits(token types.Token) (units types.Token, err types.InputError) {
+	token, err = p.checkToken(token, types.Units)
 	if err != nil {
 		return units, err
 	}
-	// ensure that the units are valid
-	_, unitErr := u.Find(token.Value)
+	// ensure that the units are valid; units named in the language of another locale are not
+	_, unitErr := registry.FindIn(token.Value, p.locale)
 	if unitErr != nil {
-		return units, types.ErrorInvalidUnits(reader.Input(), token)
+		return units, types.ErrorInvalidUnits(p.input(), token, registry.Suggest(token.Value, p.locale)...)
 	}
 	return token, nil
 }
 
-func nextToken(reader tokenReader, expected types.TokenType) (nextToken types.Token, err types.InputError) {
-	nextToken, readErr := reader.ReadToken()
+// checkVariable ensures that a variable is known.
+func (p *parser) checkVariable(token types.Token) (node ast.Node, err types.InputError) {
+	if _, ok := p.vars[token.Value]; !ok {
+		return node, types.ErrorUnexpectedToken(p.input(), token, types.Number)
+	}
+	return &ast.Variable{Name: astToken(token)}, nil
+}
+
+// readToken reads the next token of any type. While recovering, a problem reading the input is kept and then
+// only EOF is read.
+func (p *parser) readToken() (types.Token, types.InputError) {
+	if n := len(p.unread); n > 0 {
+		token := p.unread[n-1]
+		p.unread = p.unread[:n-1]
+		return token, nil
+	}
+	eof := types.EOF.TokenAt("", len(p.input())+1)
+	if p.stopped {
+		return eof, nil
+	}
+	token, err := p.nextInput()
+	if err != nil && p.recovery {
+		// like when there are too many tokens
+		p.stop(err)
+		return eof, nil
+	}
+	return token, err
+}
+
+// nextInput reads the next token from the reader.
+func (p *parser) nextInput() (types.Token, types.InputError) {
+	if err := p.ctx.Err(); err != nil {
+		return types.Token{}, types.ErrorCancelled(p.input(), err)
+	}
+	token, readErr := p.reader.ReadToken()
 	if readErr != nil {
-		return nextToken, types.ErrorReadFailed(reader.Input(), readErr)
+		return token, types.ErrorReadFailed(p.input(), readErr)
 	}
-	if nextToken.TokenType == types.Error {
-		return nextToken, types.ErrorTokenizerError(reader.Input(), nextToken)
+	if token.TokenType != types.EOF {
+		p.tokens++
 	}
-	if expected != nextToken.TokenType {
-		if nextToken.TokenType == types.EOF {
-			return nextToken, types.ErrorUnexpectedEOF(reader.Input(), nextToken, expected)
+	if p.maxTokens > 0 && p.tokens > p.maxTokens {
+		return token, types.ErrorTooManyTokens(p.input(), token, p.maxTokens)
+	}
+	return token, nil
+}
+
+// unreadToken returns a token so that it is read again.
+func (p *parser) unreadToken(token types.Token) {
+	p.unread = append(p.unread, token)
+}
+
+// deeper counts an operation and ensures that the expression is not nested too deeply. While recovering,
+// nothing more is read once it is.
+func (p *parser) deeper(operator types.Token) types.InputError {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		err := types.ErrorTooDeep(p.input(), operator, p.maxDepth)
+		if p.recovery {
+			p.stop(err)
+			return nil
 		}
-		return nextToken, types.ErrorUnexpectedToken(reader.Input(), nextToken, expected)
+		return err
 	}
-	return nextToken, nil
+	return nil
 }
 
-// operationExpr Create an expression where two values are acted on by an operator.
-func operationExpr(operator types.Token, left types.Expression, right types.Expression, input string) (expr types.Expression, err types.InputError) {
+func (p *parser) nextToken(expected types.TokenType) (nextToken types.Token, err types.InputError) {
+	nextToken, err = p.readToken()
+	if err != nil {
+		return nextToken, err
+	}
+	return p.checkToken(nextToken, expected)
+}
+
+// checkToken ensures that a token is of the expected type.
+func (p *parser) checkToken(token types.Token, expected types.TokenType) (types.Token, types.InputError) {
+	if token.TokenType == types.Error {
+		return token, types.ErrorTokenizerError(p.input(), token)
+	}
+	if expected != token.TokenType {
+		if token.TokenType == types.EOF {
+			return token, types.ErrorUnexpectedEOF(p.input(), token, expected)
+		}
+		return token, types.ErrorUnexpectedToken(p.input(), token, expected)
+	}
+	return token, nil
+}
+
+// input returns the original input that was tokenized.
+func (p *parser) input() string {
+	return p.reader.Input()
+}
+
+// operationNode Create a node where two operands are acted on by an operator.
+func operationNode(operator types.Token, left ast.Node, right ast.Node, input string) (node ast.Node, err types.InputError) {
 	switch operator.TokenType {
 	case types.Plus:
-		return types.AdditionExpr(left, right), nil
+		return &ast.Operation{Operator: ast.Add, OperatorToken: astToken(operator), Left: left, Right: right}, nil
 	case types.Minus:
-		return types.SubtractionExpr(left, right), nil
+		return &ast.Operation{Operator: ast.Subtract, OperatorToken: astToken(operator), Left: left, Right: right}, nil
+	default:
+		return node, types.ErrorInvalidOperator(input, operator)
+	}
+}
+
+// astToken converts a token for the syntax tree.
+func astToken(token types.Token) ast.Token {
+	return ast.NewToken(token.Value, token.Position)
+}
+
+// unitsToken converts the units of the syntax tree back to a token.
+func unitsToken(token ast.Token) types.Token {
+	return types.Units.TokenAt(token.Value, token.Span.Start)
+}
+
+// expression builds an expression, that can be evaluated, from a syntax tree.
+func (p *parser) expression(node ast.Node) types.Expression {
+	switch n := node.(type) {
+	case *ast.Value:
+		return types.NewValue(n.Number, unitsToken(n.Units))
+	case *ast.Interval:
+		return types.NewInterval(n.Lower, n.Upper, unitsToken(n.Units))
+	case *ast.Operation:
+		left, right := p.expression(n.Left), p.expression(n.Right)
+		switch n.Operator {
+		case ast.Subtract:
+			return types.SubtractionExpr(left, right).InRegion(p.region)
+		case ast.Multiply:
+			operator := types.Multiply.TokenAt(n.OperatorToken.Value, n.OperatorToken.Span.Start)
+			return types.ProductExpr(left, operator, right).InRegion(p.region)
+		case ast.Divide:
+			operator := types.Divide.TokenAt(n.OperatorToken.Value, n.OperatorToken.Span.Start)
+			return types.ProductExpr(left, operator, right).InRegion(p.region)
+		default:
+			return types.AdditionExpr(left, right).InRegion(p.region)
+		}
+	case *ast.Group:
+		return p.expression(n.Expr)
+	case *ast.Variable:
+		return types.NewVariable(types.Name.TokenAt(n.Name.Value, n.Name.Span.Start), p.vars[n.Name.Value])
+	case *ast.Scaling:
+		operator := types.Multiply.TokenAt(n.OperatorToken.Value, n.OperatorToken.Span.Start)
+		if n.Operator == ast.Divide {
+			operator = types.Divide.TokenAt(n.OperatorToken.Value, n.OperatorToken.Span.Start)
+		}
+		number := types.Number.TokenAt(n.FactorToken.Value, n.FactorToken.Span.Start)
+		return types.ScalingExpr(p.expression(n.Expr), operator, n.Factor, number)
+	case *ast.Assignment:
+		return p.expression(n.Expr)
+	case *ast.Conversion:
+		expr := p.expression(n.Expr)
+		if n.Radix {
+			return types.RadixConversionExpr(expr, unitsToken(n.Target))
+		}
+		return types.UnitConversionExpr(expr, unitsToken(n.Target)).InRegion(p.region)
 	default:
-		return expr, types.ErrorInvalidOperator(input, operator)
+		return nil
 	}
 }
@@ -1,10 +1,19 @@
 package parser
 
 import (
+	"context"
+	"github.com/nickwallen/quick-calc/ast"
 	"github.com/nickwallen/quick-calc/internal/io"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
 	"github.com/nickwallen/quick-calc/internal/types"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseValue(t *testing.T) {
@@ -15,7 +24,7 @@ func TestParseValue(t *testing.T) {
 		input.WriteToken(types.Units.Token("pounds"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.NewValue(23, types.Units.Token("pounds"))
 	assert.Equal(t, expected, actual)
 	assert.Nil(t, err)
@@ -28,7 +37,7 @@ func TestParseValueNoUnits(t *testing.T) {
 		input.WriteToken(types.Number.TokenAt("23", 1))
 		input.WriteToken(types.EOF.TokenAt("", 2))
 	}()
-	_, err := Parse(&input)
+	_, err := Parse(input)
 	assert.NotNil(t, err)
 	assert.Equal(t, "reached end of input, but expected a unit", err.Error())
 }
@@ -39,7 +48,7 @@ func TestParseValueNoNumber(t *testing.T) {
 		input.WriteToken(types.Units.TokenAt("pounds", 1))
 		input.WriteToken(types.EOF.TokenAt("", 2))
 	}()
-	_, err := Parse(&input)
+	_, err := Parse(input)
 	assert.NotNil(t, err)
 	assert.Equal(t, "got 'pounds', but expected a number", err.Error())
 }
@@ -55,7 +64,7 @@ func TestParseBinaryAdd(t *testing.T) {
 		input.WriteToken(types.Units.Token("pounds"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.AdditionExpr(
 		types.NewValue(23, types.Units.Token("kg")),
 		types.NewValue(23, types.Units.Token("pounds")))
@@ -74,7 +83,7 @@ func TestParseBinarySubtract(t *testing.T) {
 		input.WriteToken(types.Units.Token("pounds"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.SubtractionExpr(
 		types.NewValue(23, types.Units.Token("kg")),
 		types.NewValue(23, types.Units.Token("pounds")))
@@ -92,7 +101,7 @@ func TestParseConversion(t *testing.T) {
 		input.WriteToken(types.Units.Token("ounces"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.UnitConversionExpr(
 		types.NewValue(2, types.Units.Token("pounds")),
 		types.Units.Token("ounces"))
@@ -113,7 +122,7 @@ func TestParseBinarySumAndConvert(t *testing.T) {
 		input.WriteToken(types.Units.Token("pounds"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.UnitConversionExpr(
 		types.AdditionExpr(
 			types.NewValue(2, types.Units.Token("ounces")),
@@ -136,7 +145,7 @@ func TestParseBinarySubtractAndConvert(t *testing.T) {
 		input.WriteToken(types.Units.Token("ounces"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.UnitConversionExpr(
 		types.SubtractionExpr(
 			types.NewValue(2, types.Units.Token("pounds")),
@@ -160,7 +169,7 @@ func TestParseSum(t *testing.T) {
 		input.WriteToken(types.Units.Token("ounces"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.AdditionExpr(
 		types.AdditionExpr(
 			types.NewValue(2, types.Units.Token("ounces")),
@@ -187,7 +196,7 @@ func TestParseSum2(t *testing.T) {
 		input.WriteToken(types.Units.Token("ounces"))
 		input.WriteToken(types.EOF.Token(""))
 	}()
-	actual, err := Parse(&input)
+	actual, err := Parse(input)
 	expected := types.AdditionExpr(
 		types.SubtractionExpr(
 			types.AdditionExpr(
@@ -198,3 +207,482 @@ func TestParseSum2(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.Nil(t, err)
 }
+
+func TestParseInterval(t *testing.T) {
+	expr := "3..5 kg"
+	input := io.NewTokenChannel(expr)
+	go func() {
+		input.WriteToken(types.Number.Token("3"))
+		input.WriteToken(types.Range.Token(".."))
+		input.WriteToken(types.Number.Token("5"))
+		input.WriteToken(types.Units.Token("kg"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(input)
+	expected := types.NewInterval(3, 5, types.Units.Token("kg"))
+	assert.Equal(t, expected, actual)
+	assert.Nil(t, err)
+}
+
+func TestParseIntervalNoUpperBound(t *testing.T) {
+	input := io.NewTokenChannel("3.. kg")
+	go func() {
+		input.WriteToken(types.Number.TokenAt("3", 1))
+		input.WriteToken(types.Range.TokenAt("..", 2))
+		input.WriteToken(types.Units.TokenAt("kg", 5))
+		input.WriteToken(types.EOF.TokenAt("", 7))
+	}()
+	_, err := Parse(input)
+	assert.NotNil(t, err)
+	assert.Equal(t, "got 'kg', but expected a number", err.Error())
+}
+
+func TestParsePrefixedNumbers(t *testing.T) {
+	numbers := map[string]float64{
+		"0x1F":   31,
+		"0xff":   255,
+		"-0x10":  -16,
+		"0o17":   15,
+		"0b1010": 10,
+		"1_000":  1000,
+		"2.5":    2.5,
+		// up to the largest unsigned 64-bit integer
+		"0x8000000000000000":  math.Pow(2, 63),
+		"0xFFFFFFFFFFFFFFFF":  float64(math.MaxUint64),
+		"-0xFFFFFFFFFFFFFFFF": -float64(math.MaxUint64),
+	}
+	for number, expected := range numbers {
+		t.Run(number, func(t *testing.T) {
+			input := io.NewTokenChannel(number + " bytes")
+			go func() {
+				input.WriteToken(types.Number.Token(number))
+				input.WriteToken(types.Units.Token("bytes"))
+				input.WriteToken(types.EOF.Token(""))
+			}()
+			actual, err := Parse(input)
+			assert.Nil(t, err)
+			assert.Equal(t, types.NewValue(expected, types.Units.Token("bytes")), actual)
+		})
+	}
+}
+
+func TestParseInvalidNumber(t *testing.T) {
+	input := io.NewTokenChannel("0x10000000000000000 bytes")
+	go func() {
+		input.WriteToken(types.Number.TokenAt("0x10000000000000000", 1))
+		input.WriteToken(types.Units.TokenAt("bytes", 21))
+		input.WriteToken(types.EOF.TokenAt("", 26))
+	}()
+	_, err := Parse(input)
+	assert.NotNil(t, err)
+	assert.Equal(t, "'0x10000000000000000' is not a valid number", err.Error())
+}
+
+func TestParseRadixConversion(t *testing.T) {
+	expr := "255 bytes in hex"
+	input := io.NewTokenChannel(expr)
+	go func() {
+		input.WriteToken(types.Number.Token("255"))
+		input.WriteToken(types.Units.Token("bytes"))
+		input.WriteToken(types.In.Token("in"))
+		input.WriteToken(types.Units.Token("hex"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(input)
+	expected := types.RadixConversionExpr(
+		types.NewValue(255, types.Units.Token("bytes")),
+		types.Units.Token("hex"))
+	assert.Equal(t, expected, actual)
+	assert.Nil(t, err)
+}
+
+func TestParseWithLocale(t *testing.T) {
+	input := io.NewTokenChannel("1.000,5 kg")
+	go func() {
+		input.WriteToken(types.Number.Token("1.000,5"))
+		input.WriteToken(types.Units.Token("kg"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(&input, WithLocale(locale.German))
+	assert.Nil(t, err)
+	assert.Equal(t, types.NewValue(1000.5, types.Units.Token("kg")), actual)
+}
+
+func TestParseAmbiguousNumber(t *testing.T) {
+	input := io.NewTokenChannel("1.000,5 kg")
+	go func() {
+		input.WriteToken(types.Number.TokenAt("1.000,5", 1))
+		input.WriteToken(types.Units.TokenAt("kg", 9))
+		input.WriteToken(types.EOF.TokenAt("", 11))
+	}()
+	_, err := Parse(input)
+	assert.NotNil(t, err)
+	assert.Equal(t, "'1.000,5' is ambiguous; use '.' to separate decimals and ',' to group thousands", err.Error())
+}
+
+func TestParseInvalidUnitsSuggestions(t *testing.T) {
+	input := io.NewTokenChannel("2 kilograms in punds")
+	go func() {
+		input.WriteToken(types.Number.TokenAt("2", 1))
+		input.WriteToken(types.Units.TokenAt("kilograms", 3))
+		input.WriteToken(types.In.TokenAt("in", 13))
+		input.WriteToken(types.Units.TokenAt("punds", 16))
+		input.WriteToken(types.EOF.TokenAt("", 21))
+	}()
+	_, err := Parse(input)
+	assert.NotNil(t, err)
+	assert.Equal(t, "'punds' is not a known measurement unit", err.Error())
+	invalid, ok := err.(*types.InvalidUnits)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"pounds", "pints"}, invalid.Suggestions())
+}
+
+func TestParseWithRegion(t *testing.T) {
+	input := io.NewTokenChannel("2 t in kg")
+	go func() {
+		input.WriteToken(types.Number.Token("2"))
+		input.WriteToken(types.Units.Token("t"))
+		input.WriteToken(types.In.Token("in"))
+		input.WriteToken(types.Units.Token("kg"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(&input, WithRegion(registry.US))
+	assert.Nil(t, err)
+	expected := types.UnitConversionExpr(types.NewValue(2, types.Units.Token("t")), types.Units.Token("kg")).InRegion(registry.US)
+	assert.Equal(t, expected, actual)
+}
+
+// the input used to benchmark parsing
+const benchmarkInput = "12 oz + 1.2 lbs + 24 oz - 0.8 lbs in pounds"
+
+func BenchmarkParseLexer(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := Parse(tokenizer.NewLexer(benchmarkInput))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseChannel(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tokens := io.NewTokenChannel(benchmarkInput)
+		go tokenizer.Tokenize(benchmarkInput, tokens)
+		_, err := Parse(tokens)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseCancelsChannel(t *testing.T) {
+	input := io.NewTokenChannel("pounds")
+	go func() {
+		input.WriteToken(types.Units.TokenAt("pounds", 1))
+	}()
+	_, err := Parse(input)
+	assert.NotNil(t, err)
+	// the writer is not blocked once the parser stops reading
+	assert.Equal(t, context.Canceled, input.WriteToken(types.EOF.TokenAt("", 7)))
+}
+
+func TestParseChannelNoLeaks(t *testing.T) {
+	before := runtime.NumGoroutine()
+	inputs := []string{"pounds", "2 googles in kg", "2 kg 3 kg 4 kg 5 kg", "1,00 kg + 2 kg + 3 kg"}
+	for i := 0; i < 5000; i++ {
+		input := inputs[i%len(inputs)]
+		tokens := io.NewTokenChannel(input)
+		go tokenizer.Tokenize(input, tokens)
+		// the tokenizer is not left blocked writing tokens that are never read; Parse cancels the channel
+		_, err := Parse(tokens)
+		assert.NotNil(t, err, input)
+	}
+	// allow the tokenizers to return
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+func TestParseWithLimits(t *testing.T) {
+	testCases := map[string]struct {
+		option   Option
+		expected string
+	}{
+		"2 kg + 3 kg":        {WithMaxTokens(4), "the input has more than 4 tokens"},
+		"1 kg + 2 kg + 3 kg": {WithMaxDepth(1), "the expression is nested more than 1 levels deep"},
+		"1 kg * 2 / 3":       {WithMaxDepth(1), "the expression is nested more than 1 levels deep"},
+		"1234567.8901 kg":    {WithMaxDigits(10), "'1234567.8901' has more than 10 significant digits"},
+	}
+	for input, tc := range testCases {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(tokenizer.NewLexer(input), tc.option)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Parse(tokenizer.NewLexer("2 kg"), WithContext(ctx))
+	assert.IsType(t, &types.Cancelled{}, err)
+}
+
+func TestSignificantDigits(t *testing.T) {
+	testCases := map[string]int{
+		"0":             0,
+		"1000":          1,
+		"1,234.5":       5,
+		"-0.000120":     2,
+		"1.23456e+300":  6,
+		"12_345_678.90": 9,
+	}
+	for value, expected := range testCases {
+		assert.Equal(t, expected, significantDigits(value), value)
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	input := "2 kg + 3 lbs in g"
+	node, err := ParseTree(tokenizer.NewLexer(input))
+	assert.Nil(t, err)
+	expected := &ast.Conversion{
+		Expr: &ast.Operation{
+			Operator:      ast.Add,
+			OperatorToken: ast.NewToken("+", 6),
+			Left:          &ast.Value{Number: 2, NumberToken: ast.NewToken("2", 1), Units: ast.NewToken("kg", 3)},
+			Right:         &ast.Value{Number: 3, NumberToken: ast.NewToken("3", 8), Units: ast.NewToken("lbs", 10)},
+		},
+		Keyword: ast.NewToken("in", 14),
+		Target:  ast.NewToken("g", 17),
+	}
+	assert.Equal(t, expected, node)
+}
+
+func TestParseTreeScaling(t *testing.T) {
+	input := "2 kg + 3 lbs * 2"
+	node, err := ParseTree(tokenizer.NewLexer(input))
+	assert.Nil(t, err)
+	expected := &ast.Operation{
+		Operator:      ast.Add,
+		OperatorToken: ast.NewToken("+", 6),
+		Left:          &ast.Value{Number: 2, NumberToken: ast.NewToken("2", 1), Units: ast.NewToken("kg", 3)},
+		Right: &ast.Scaling{
+			Expr:          &ast.Value{Number: 3, NumberToken: ast.NewToken("3", 8), Units: ast.NewToken("lbs", 10)},
+			Operator:      ast.Multiply,
+			OperatorToken: ast.NewToken("*", 14),
+			Factor:        2,
+			FactorToken:   ast.NewToken("2", 16),
+		},
+	}
+	assert.Equal(t, expected, node)
+}
+
+func TestParseScaling(t *testing.T) {
+	testCases := map[string]float64{
+		"per person * 3":      1200,
+		"rent / 3":            400,
+		"rent / 4 * 2 + 1 kg": 601,
+		"2 kg * 3 in g":       6000,
+		"1..2 kg * 2 - rent":  -1198,
+		"2 * rent":            2400,
+		"3 * 2 kg / 4":        1.5,
+		"2 * (rent + 1 kg)":   2402,
+	}
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"rent", "per person"}))
+			expr, err := Parse(lexer, WithVariables(testVars))
+			if assert.Nil(t, err) {
+				amount, err := expr.Eval(input)
+				assert.Nil(t, err)
+				assert.Equal(t, expected, amount.Value)
+			}
+		})
+	}
+}
+
+func TestParseScalingErrors(t *testing.T) {
+	testCases := map[string]string{
+		"2 kg * kg":      "expected number, but got 'k'",
+		"2 kg / 0":       "cannot divide 2.00 kg by zero",
+		"2 kg * 3 kg":    "cannot multiply kg by kg",
+		"2 kg * 3 * 4 *": "expected number, but got ''",
+		"2 * 3":          "reached end of input, but expected a unit",
+		"2 / 3 kg":       "got '/', but expected a unit",
+	}
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			expr, err := Parse(tokenizer.NewLexer(input))
+			if err == nil {
+				_, err = expr.Eval(input)
+			}
+			if assert.NotNil(t, err) {
+				assert.Equal(t, expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseTreeError(t *testing.T) {
+	_, err := ParseTree(tokenizer.NewLexer("2 kg +"))
+	assert.NotNil(t, err)
+}
+
+func TestParseProduct(t *testing.T) {
+	expr := "1 m + 2 m * 3 m"
+	input := io.NewTokenChannel(expr)
+	go func() {
+		input.WriteToken(types.Number.Token("1"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.Plus.Token("+"))
+		input.WriteToken(types.Number.Token("2"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.Multiply.Token("*"))
+		input.WriteToken(types.Number.Token("3"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(&input)
+	expected := types.AdditionExpr(
+		types.NewValue(1, types.Units.Token("m")),
+		types.ProductExpr(
+			types.NewValue(2, types.Units.Token("m")),
+			types.Multiply.Token("*"),
+			types.NewValue(3, types.Units.Token("m"))))
+	assert.Equal(t, expected, actual)
+	assert.Nil(t, err)
+}
+
+func TestParseGroup(t *testing.T) {
+	expr := "(1 m + 2 m) * 3 m"
+	input := io.NewTokenChannel(expr)
+	go func() {
+		input.WriteToken(types.LeftParen.Token("("))
+		input.WriteToken(types.Number.Token("1"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.Plus.Token("+"))
+		input.WriteToken(types.Number.Token("2"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.RightParen.Token(")"))
+		input.WriteToken(types.Multiply.Token("*"))
+		input.WriteToken(types.Number.Token("3"))
+		input.WriteToken(types.Units.Token("m"))
+		input.WriteToken(types.EOF.Token(""))
+	}()
+	actual, err := Parse(&input)
+	expected := types.ProductExpr(
+		types.AdditionExpr(
+			types.NewValue(1, types.Units.Token("m")),
+			types.NewValue(2, types.Units.Token("m"))),
+		types.Multiply.Token("*"),
+		types.NewValue(3, types.Units.Token("m")))
+	assert.Equal(t, expected, actual)
+	assert.Nil(t, err)
+}
+
+func TestParseGroupNotClosed(t *testing.T) {
+	input := io.NewTokenChannel("(2 kg")
+	go func() {
+		input.WriteToken(types.LeftParen.TokenAt("(", 1))
+		input.WriteToken(types.Number.TokenAt("2", 2))
+		input.WriteToken(types.Units.TokenAt("kg", 4))
+		input.WriteToken(types.EOF.TokenAt("", 6))
+	}()
+	_, err := Parse(&input)
+	assert.NotNil(t, err)
+	assert.Equal(t, "reached end of input, but expected ')'", err.Error())
+}
+
+// the variables used by tests
+var testVars = map[string]types.Amount{
+	"rent":       {Value: 1200, Units: types.Units.Token("kg")},
+	"per person": {Value: 400, Units: types.Units.Token("kg")},
+}
+
+func TestParseVariable(t *testing.T) {
+	input := "2 kg + rent"
+	lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"rent"}))
+	expr, err := Parse(lexer, WithVariables(testVars))
+	assert.Nil(t, err)
+	amount, err := expr.Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1202), amount.Value)
+}
+
+func TestParseStartsWithVariable(t *testing.T) {
+	input := "per person - 2 kg in g"
+	lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"per person"}))
+	expr, err := Parse(lexer, WithVariables(testVars))
+	assert.Nil(t, err)
+	amount, err := expr.Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(398000), amount.Value)
+}
+
+func TestParseUnknownVariable(t *testing.T) {
+	input := "2 kg + rent"
+	lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"rent"}))
+	_, err := Parse(lexer)
+	assert.NotNil(t, err)
+	assert.Equal(t, "got 'rent', but expected a number", err.Error())
+}
+
+func TestParseTreeAssignment(t *testing.T) {
+	input := "total = rent + 2 kg"
+	lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"rent"}))
+	node, err := ParseTree(lexer, WithVariables(testVars))
+	assert.Nil(t, err)
+	expected := &ast.Assignment{
+		Name:   ast.NewToken("total", 1),
+		Assign: ast.NewToken("=", 7),
+		Expr: &ast.Operation{
+			Operator:      ast.Add,
+			OperatorToken: ast.NewToken("+", 14),
+			Left:          &ast.Variable{Name: ast.NewToken("rent", 9)},
+			Right:         &ast.Value{Number: 2, NumberToken: ast.NewToken("2", 16), Units: ast.NewToken("kg", 18)},
+		},
+	}
+	assert.Equal(t, expected, node)
+}
+
+func TestParseAssignment(t *testing.T) {
+	input := "total = rent in g"
+	lexer := tokenizer.NewLexer(input, tokenizer.WithVariables([]string{"rent"}))
+	name, expr, err := ParseAssignment(lexer, WithVariables(testVars))
+	assert.Nil(t, err)
+	assert.Equal(t, "total", name)
+	amount, err := expr.Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1200000), amount.Value)
+
+	name, _, err = ParseAssignment(tokenizer.NewLexer("2 kg"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", name)
+}
+
+func TestParseReservedName(t *testing.T) {
+	// a variable cannot hide units, keywords in any locale or radixes
+	for _, input := range []string{"kg = 5 m", "in = 2 kg", "nach = 2 kg", "en = 2 kg", "hex = 2 kg", "Pfund = 2 kg"} {
+		t.Run(input, func(t *testing.T) {
+			lexer := tokenizer.NewLexer(input, tokenizer.WithLocale(locale.German))
+			_, _, err := ParseAssignment(lexer, WithLocale(locale.German))
+			if assert.NotNil(t, err) {
+				assert.Equal(t, types.CodeReservedName, err.Code())
+				start, width := err.Position()
+				assert.Equal(t, 1, start)
+				assert.Equal(t, strings.Index(input, " "), width)
+			}
+		})
+	}
+	// units named in the language of another locale may be used
+	name, _, err := ParseAssignment(tokenizer.NewLexer("Pfund = 2 kg"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Pfund", name)
+}
@@ -0,0 +1,34 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+)
+
+// the unit of area that is the square of each unit of length, by the name of the unit of length
+var squares = map[string]u.Unit{
+	u.Meter.Name:      SquareMeter,
+	u.KiloMeter.Name:  SquareKilometer,
+	u.CentiMeter.Name: SquareCentimeter,
+	u.MilliMeter.Name: SquareMillimeter,
+	u.Inch.Name:       SquareInch,
+	u.Foot.Name:       SquareFoot,
+	u.Yard.Name:       SquareYard,
+	u.Mile.Name:       SquareMile,
+}
+
+// Square returns the unit of area that is the square of a unit of length; like 'ft^2' for 'ft'.
+func Square(length u.Unit) (u.Unit, bool) {
+	square, ok := squares[length.Name]
+	return square, ok
+}
+
+// Side returns the unit of length whose square is a unit of area; like 'ft' for 'ft^2'.
+func Side(area u.Unit) (u.Unit, bool) {
+	for name, square := range squares {
+		if square.Name == area.Name {
+			unit, err := findCanonical(name)
+			return unit, err == nil
+		}
+	}
+	return u.Unit{}, false
+}
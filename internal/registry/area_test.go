@@ -0,0 +1,45 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSquare(t *testing.T) {
+	square, ok := Square(u.Foot)
+	assert.True(t, ok)
+	assert.Equal(t, "square foot", square.Name)
+
+	_, ok = Square(u.KiloGram)
+	assert.False(t, ok)
+}
+
+func TestSide(t *testing.T) {
+	side, ok := Side(SquareMeter)
+	assert.True(t, ok)
+	assert.Equal(t, "meter", side.Name)
+
+	_, ok = Side(Acre)
+	assert.False(t, ok)
+}
+
+func TestAreaConversion(t *testing.T) {
+	tests := map[string]float64{
+		"ft^2":         10.763910416709722,
+		"square feet":  10.763910416709722,
+		"acre":         0.00024710538146716532,
+		"ha":           0.0001,
+		"cm²":          10000,
+		"square miles": 3.861021585424458e-07,
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			unit, err := Find(name)
+			assert.Nil(t, err)
+			value, err := u.ConvertFloat(1, SquareMeter, unit)
+			assert.Nil(t, err)
+			assert.InDelta(t, expected, value.Float(), 1e-12)
+		})
+	}
+}
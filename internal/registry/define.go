@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"fmt"
+	u "github.com/bcicen/go-units"
+	"sort"
+)
+
+// Define defines a unit that is equal to an amount of a known unit; like a 'smoot' equal to 1.7018 meters.
+// The unit measures the same quantity as the known unit. Units should be defined before any calculations.
+func Define(name, symbol string, amount float64, base string) (u.Unit, error) {
+	var unit u.Unit
+	if name == "" {
+		return unit, fmt.Errorf("a unit must have a name")
+	}
+	if amount <= 0 {
+		return unit, fmt.Errorf("'%s' must be a positive amount of '%s'", name, base)
+	}
+	for _, existing := range []string{name, symbol} {
+		if _, err := Find(existing); existing != "" && err == nil {
+			return unit, fmt.Errorf("'%s' is already a unit", existing)
+		}
+	}
+	baseUnit, err := Find(base)
+	if err != nil {
+		return unit, fmt.Errorf("'%s' is not a known unit", base)
+	}
+	unit = u.NewUnit(name, symbol, u.UnitOptionQuantity(baseUnit.Quantity))
+	u.NewRatioConversion(unit, baseUnit, amount)
+
+	// the new names must be found by the tokenizer
+	namesLock.Lock()
+	defer namesLock.Unlock()
+	names = allNames()
+	localizedNames = map[string]*localized{}
+	return unit, nil
+}
+
+// All returns all of the known units ordered by quantity, then by name.
+func All() []u.Unit {
+	all := u.All()
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Quantity != all[j].Quantity {
+			return all[i].Quantity < all[j].Quantity
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	smoot, err := Define("smoot", "smt", 1.7018, "meters")
+	assert.Nil(t, err)
+	assert.Equal(t, u.Meter.Quantity, smoot.Quantity)
+
+	// the unit can be found by its name and symbol
+	found, err := Find("smt")
+	assert.Nil(t, err)
+	assert.Equal(t, "smoot", found.Name)
+	assert.Contains(t, Names(), "smoots")
+
+	meters, err := u.ConvertFloat(2, smoot, u.Meter)
+	assert.Nil(t, err)
+	assert.InDelta(t, 3.4036, meters.Float(), 0.000001)
+}
+
+func TestDefine_Errors(t *testing.T) {
+	tests := map[string]struct {
+		name   string
+		symbol string
+		amount float64
+		base   string
+	}{
+		"a unit must have a name":                    {"", "x", 1, "m"},
+		"'smidgen' must be a positive amount of 'g'": {"smidgen", "", 0, "g"},
+		"'kilogram' is already a unit":               {"kilogram", "", 1000, "g"},
+		"'kg' is already a unit":                     {"kilo", "kg", 1000, "g"},
+		"'blarg' is not a known unit":                {"blargle", "", 2, "blarg"},
+	}
+	for expected, test := range tests {
+		t.Run(expected, func(t *testing.T) {
+			_, err := Define(test.name, test.symbol, test.amount, test.base)
+			if assert.NotNil(t, err) {
+				assert.Equal(t, expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	all := All()
+	assert.NotEmpty(t, all)
+	for i := 1; i < len(all); i++ {
+		prev, next := all[i-1], all[i]
+		assert.True(t, prev.Quantity < next.Quantity || (prev.Quantity == next.Quantity && prev.Name < next.Name))
+	}
+}
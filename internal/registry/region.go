@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"fmt"
+	u "github.com/bcicen/go-units"
+	"strings"
+	"unicode/utf8"
+)
+
+// Region is a preference for the units of a region when the name of a unit is ambiguous; like a short ton for 't' in the US.
+type Region string
+
+const (
+	// AnyRegion has no preference for the units of any region.
+	AnyRegion Region = ""
+	// US prefers United States customary units.
+	US Region = "us"
+	// UK prefers British imperial units.
+	UK Region = "uk"
+	// Metric prefers metric units.
+	Metric Region = "metric"
+)
+
+// all of the known regions
+var regions = []Region{US, UK, Metric}
+
+// FindRegion returns the region with the given name.
+func FindRegion(name string) (Region, error) {
+	for _, region := range regions {
+		if strings.EqualFold(string(region), name) {
+			return region, nil
+		}
+	}
+	return AnyRegion, fmt.Errorf("unknown region '%s'", name)
+}
+
+// AmbiguousError indicates that the name of a unit could refer to more than one unit.
+type AmbiguousError struct {
+	Name       string   // the ambiguous name
+	Candidates []u.Unit // the units that the name could refer to
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("unit \"%s\" is ambiguous", e.Name)
+}
+
+// the units that an ambiguous name could refer to and the units preferred by each region
+type ambiguity struct {
+	candidates []u.Unit
+	preferred  map[Region][]u.Unit
+}
+
+var (
+	tons = ambiguity{
+		candidates: []u.Unit{Tonne, ShortTon, u.Ton},
+		preferred:  map[Region][]u.Unit{US: {ShortTon}, UK: {u.Ton}, Metric: {Tonne}},
+	}
+	ounces = ambiguity{
+		candidates: []u.Unit{u.Ounce, u.FluidOunce, u.CustomaryFluidOunce},
+		preferred:  map[Region][]u.Unit{US: {u.Ounce, u.CustomaryFluidOunce}, UK: {u.Ounce, u.FluidOunce}, Metric: {u.Ounce, u.FluidOunce}},
+	}
+	gallons = ambiguity{
+		candidates: []u.Unit{u.Gallon, u.FluidGallon},
+		preferred:  map[Region][]u.Unit{US: {u.FluidGallon}, UK: {u.Gallon}},
+	}
+	calories = ambiguity{
+		candidates: []u.Unit{Calorie, KiloCalorie},
+		preferred:  map[Region][]u.Unit{US: {KiloCalorie}, UK: {KiloCalorie}, Metric: {Calorie}},
+	}
+)
+
+// the names that could refer to more than one unit; symbols are case sensitive so that 'Cal' is a kilocalorie
+var ambiguities = map[string]ambiguity{
+	"t":        {tons.candidates, map[Region][]u.Unit{US: {ShortTon}, UK: {Tonne}, Metric: {Tonne}}},
+	"ton":      tons,
+	"tons":     tons,
+	"oz":       ounces,
+	"ounce":    ounces,
+	"ounces":   ounces,
+	"gal":      gallons,
+	"gallon":   gallons,
+	"gallons":  gallons,
+	"cal":      calories,
+	"calorie":  calories,
+	"calories": calories,
+}
+
+// findAmbiguity returns the ambiguity of a name, if it is ambiguous.
+func findAmbiguity(name string) (ambiguity, bool) {
+	if a, ok := ambiguities[name]; ok {
+		return a, true
+	}
+	// names, unlike symbols, are not case sensitive
+	if utf8.RuneCountInString(name) > 3 {
+		a, ok := ambiguities[strings.ToLower(name)]
+		return a, ok
+	}
+	return ambiguity{}, false
+}
+
+// Candidates returns the units that a name could refer to; more than one if the name is ambiguous like 'oz'.
+func Candidates(name string) ([]u.Unit, error) {
+	if a, ok := findAmbiguity(name); ok {
+		return a.candidates, nil
+	}
+	unit, err := Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return []u.Unit{unit}, nil
+}
+
+// Resolve returns the unit that a name refers to. An ambiguous name is resolved by preferring the units of
+// the given quantities, like 'volume' for '8 oz in ml', and then the units preferred by the region.
+// An *AmbiguousError is returned if the name still refers to more than one unit.
+func Resolve(name string, region Region, quantities ...string) (u.Unit, error) {
+	candidates, err := Candidates(name)
+	if err != nil {
+		return u.Unit{}, err
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	a, _ := findAmbiguity(name)
+	matching := candidates
+	if len(quantities) > 0 {
+		matching = filter(candidates, func(unit u.Unit) bool { return contains(quantities, unit.Quantity) })
+		if len(matching) == 0 {
+			// none of the units can be converted; the first is as good as any other
+			return candidates[0], nil
+		}
+	}
+	if preferred := filter(matching, func(unit u.Unit) bool { return includes(a.preferred[region], unit) }); len(preferred) > 0 {
+		matching = preferred
+	}
+	if len(matching) > 1 {
+		return u.Unit{}, &AmbiguousError{Name: name, Candidates: matching}
+	}
+	return matching[0], nil
+}
+
+// Quantities returns the quantities of the units that a name could refer to; like 'mass' and 'volume' for 'oz'.
+func Quantities(name string) []string {
+	candidates, _ := Candidates(name)
+	var quantities []string
+	for _, unit := range candidates {
+		if !contains(quantities, unit.Quantity) {
+			quantities = append(quantities, unit.Quantity)
+		}
+	}
+	return quantities
+}
+
+func filter(units []u.Unit, keep func(u.Unit) bool) []u.Unit {
+	var kept []u.Unit
+	for _, unit := range units {
+		if keep(unit) {
+			kept = append(kept, unit)
+		}
+	}
+	return kept
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func includes(units []u.Unit, unit u.Unit) bool {
+	for _, v := range units {
+		if v.Name == unit.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindRegion(t *testing.T) {
+	region, err := FindRegion("US")
+	assert.Nil(t, err)
+	assert.Equal(t, US, region)
+
+	_, err = FindRegion("mars")
+	assert.NotNil(t, err)
+}
+
+func TestCandidates(t *testing.T) {
+	testCases := map[string][]u.Unit{
+		"t":       {Tonne, ShortTon, u.Ton},
+		"oz":      {u.Ounce, u.FluidOunce, u.CustomaryFluidOunce},
+		"Gallons": {u.Gallon, u.FluidGallon},
+		"cal":     {Calorie, KiloCalorie},
+		"Cal":     {KiloCalorie},
+		"kg":      {u.KiloGram},
+	}
+	for name, expected := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Candidates(name)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	testCases := []struct {
+		name       string
+		region     Region
+		quantities []string
+		expected   u.Unit
+	}{
+		{"t", US, nil, ShortTon},
+		{"t", Metric, nil, Tonne},
+		{"ton", UK, nil, u.Ton},
+		{"gal", US, nil, u.FluidGallon},
+		{"gal", UK, nil, u.Gallon},
+		{"oz", AnyRegion, []string{"mass"}, u.Ounce},
+		{"oz", US, []string{"volume"}, u.CustomaryFluidOunce},
+		{"oz", UK, []string{"volume"}, u.FluidOunce},
+		{"cal", Metric, nil, Calorie},
+		{"kg", AnyRegion, nil, u.KiloGram},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name+" "+string(tc.region), func(t *testing.T) {
+			actual, err := Resolve(tc.name, tc.region, tc.quantities...)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected.Name, actual.Name)
+		})
+	}
+}
+
+func TestResolve_Ambiguous(t *testing.T) {
+	_, err := Resolve("gal", Metric)
+	if assert.NotNil(t, err) {
+		ambiguous, ok := err.(*AmbiguousError)
+		assert.True(t, ok)
+		assert.Equal(t, []u.Unit{u.Gallon, u.FluidGallon}, ambiguous.Candidates)
+	}
+	_, err = Resolve("oz", AnyRegion, "volume")
+	assert.IsType(t, &AmbiguousError{}, err)
+}
+
+func TestResolve_Unknown(t *testing.T) {
+	_, err := Resolve("googles", US)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,282 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// units that are not provided by go-units
+var (
+	// NauticalMile is a unit of length used at sea and in the air.
+	NauticalMile = u.NewUnit("nautical mile", "nmi", u.Length)
+	// MeterPerSecond is the SI unit of speed.
+	MeterPerSecond = u.NewUnit("meter per second", "m/s", speed, u.SI, u.UnitOptionPlural("meters per second"))
+	// KilometerPerHour is a unit of speed.
+	KilometerPerHour = u.NewUnit("kilometer per hour", "km/h", speed, u.SI, u.UnitOptionPlural("kilometers per hour"), u.UnitOptionAliases("kph"))
+	// MilePerHour is a unit of speed.
+	MilePerHour = u.NewUnit("mile per hour", "mph", speed, u.BI, u.UnitOptionPlural("miles per hour"))
+	// Knot is a unit of speed equal to one nautical mile per hour.
+	Knot = u.NewUnit("knot", "kn", speed, u.UnitOptionAliases("kt"))
+	// Tonne is a metric unit of mass equal to 1000 kilograms.
+	Tonne = u.NewUnit("tonne", "", u.Mass, u.SI, u.UnitOptionAliases("metric ton"))
+	// ShortTon is a US unit of mass equal to 2000 pounds.
+	ShortTon = u.NewUnit("short ton", "", u.Mass, u.US, u.UnitOptionAliases("us ton"))
+	// Joule is the SI unit of energy.
+	Joule = u.NewUnit("joule", "J", energy, u.SI)
+	// KiloJoule is a unit of energy equal to 1000 joules.
+	KiloJoule = u.NewUnit("kilojoule", "kJ", energy, u.SI)
+	// Calorie is the energy needed to warm a gram of water by one degree Celsius.
+	Calorie = u.NewUnit("calorie", "cal", energy, u.SI)
+	// KiloCalorie is a unit of energy equal to 1000 calories; the calorie of food labels.
+	KiloCalorie = u.NewUnit("kilocalorie", "kcal", energy, u.SI, u.UnitOptionAliases("Cal", "food calorie"))
+	// SquareMeter is the SI unit of area.
+	SquareMeter = u.NewUnit("square meter", "m^2", area, u.SI, u.UnitOptionAliases("m²"))
+	// SquareKilometer is a unit of area equal to 1000000 square meters.
+	SquareKilometer = u.NewUnit("square kilometer", "km^2", area, u.SI, u.UnitOptionAliases("km²"))
+	// SquareCentimeter is a unit of area equal to 0.0001 square meters.
+	SquareCentimeter = u.NewUnit("square centimeter", "cm^2", area, u.SI, u.UnitOptionAliases("cm²"))
+	// SquareMillimeter is a unit of area equal to 0.000001 square meters.
+	SquareMillimeter = u.NewUnit("square millimeter", "mm^2", area, u.SI, u.UnitOptionAliases("mm²"))
+	// Hectare is a metric unit of area equal to 10000 square meters.
+	Hectare = u.NewUnit("hectare", "ha", area, u.SI)
+	// SquareInch is a unit of area equal to the square of an inch.
+	SquareInch = u.NewUnit("square inch", "in^2", area, u.BI, u.UnitOptionPlural("square inches"), u.UnitOptionAliases("in²"))
+	// SquareFoot is a unit of area equal to the square of a foot.
+	SquareFoot = u.NewUnit("square foot", "ft^2", area, u.BI, u.UnitOptionPlural("square feet"), u.UnitOptionAliases("ft²"))
+	// SquareYard is a unit of area equal to the square of a yard.
+	SquareYard = u.NewUnit("square yard", "yd^2", area, u.BI, u.UnitOptionAliases("yd²"))
+	// SquareMile is a unit of area equal to the square of a mile.
+	SquareMile = u.NewUnit("square mile", "mi^2", area, u.BI, u.UnitOptionAliases("mi²"))
+	// Acre is a unit of area equal to 43560 square feet.
+	Acre = u.NewUnit("acre", "ac", area, u.BI)
+	// USDollar is the currency of the United States.
+	USDollar = u.NewUnit("us dollar", "USD", currency)
+	// Euro is the currency of the euro area.
+	Euro = u.NewUnit("euro", "EUR", currency)
+	// BritishPound is the currency of the United Kingdom.
+	BritishPound = u.NewUnit("british pound", "GBP", currency, u.UnitOptionAliases("pound sterling"))
+	// JapaneseYen is the currency of Japan.
+	JapaneseYen = u.NewUnit("japanese yen", "JPY", currency, u.UnitOptionPlural("japanese yen"))
+	// SwissFranc is the currency of Switzerland.
+	SwissFranc = u.NewUnit("swiss franc", "CHF", currency)
+	// CanadianDollar is the currency of Canada.
+	CanadianDollar = u.NewUnit("canadian dollar", "CAD", currency)
+	// AustralianDollar is the currency of Australia.
+	AustralianDollar = u.NewUnit("australian dollar", "AUD", currency)
+	// ChineseYuan is the currency of China.
+	ChineseYuan = u.NewUnit("chinese yuan", "CNY", currency, u.UnitOptionPlural("chinese yuan"))
+	// IndianRupee is the currency of India.
+	IndianRupee = u.NewUnit("indian rupee", "INR", currency)
+)
+
+// the quantities of units that are not provided by go-units
+var (
+	speed  = u.UnitOptionQuantity("speed")
+	energy = u.UnitOptionQuantity("energy")
+	area   = u.UnitOptionQuantity("area")
+	// the rates between currencies change daily, so none are known and a currency converts only to itself
+	currency = u.UnitOptionQuantity("currency")
+)
+
+func init() {
+	u.NewRatioConversion(NauticalMile, u.Meter, 1852)
+	u.NewRatioConversion(KilometerPerHour, MeterPerSecond, 1000.0/3600.0)
+	u.NewRatioConversion(MilePerHour, MeterPerSecond, 0.44704)
+	u.NewRatioConversion(Knot, MeterPerSecond, 1852.0/3600.0)
+	u.NewRatioConversion(Tonne, u.KiloGram, 1000)
+	u.NewRatioConversion(ShortTon, u.Pound, 2000)
+	u.NewRatioConversion(KiloJoule, Joule, 1000)
+	u.NewRatioConversion(Calorie, Joule, 4.184)
+	u.NewRatioConversion(KiloCalorie, Joule, 4184)
+	u.NewRatioConversion(SquareKilometer, SquareMeter, 1e6)
+	u.NewRatioConversion(SquareCentimeter, SquareMeter, 1e-4)
+	u.NewRatioConversion(SquareMillimeter, SquareMeter, 1e-6)
+	u.NewRatioConversion(Hectare, SquareMeter, 1e4)
+	u.NewRatioConversion(SquareInch, SquareMeter, 0.00064516)
+	u.NewRatioConversion(SquareFoot, SquareMeter, 0.09290304)
+	u.NewRatioConversion(SquareYard, SquareMeter, 0.83612736)
+	u.NewRatioConversion(SquareMile, SquareMeter, 2589988.110336)
+	u.NewRatioConversion(Acre, SquareMeter, 4046.8564224)
+}
+
+// additional lower case names for the units of go-units and their canonical names
+var aliases = map[string]string{
+	"°c":                 "celsius",
+	"degree celsius":     "celsius",
+	"degrees celsius":    "celsius",
+	"°f":                 "fahrenheit",
+	"degree fahrenheit":  "fahrenheit",
+	"degrees fahrenheit": "fahrenheit",
+	"'":                  "foot",
+	"′":                  "foot",
+	"\"":                 "inch",
+	"″":                  "inch",
+	"long ton":           "ton",
+	"long tons":          "ton",
+	"imperial gallon":    "gallon",
+	"imperial gallons":   "gallon",
+	"us gallon":          "fluid gallon",
+	"us gallons":         "fluid gallon",
+	"us fluid ounce":     "customary fluid ounce",
+	"us fluid ounces":    "customary fluid ounce",
+}
+
+// the units already found by name; go-units allocates heavily when finding a unit
+var found sync.Map
+
+// Find returns the unit with a name, symbol or alias; like 'kg'. Units named in the language of any known
+// locale are also found, like 'Pfund', so that units already checked with FindIn can be evaluated in any locale.
+func Find(name string) (u.Unit, error) {
+	unit, err := findCanonical(name)
+	if err == nil {
+		return unit, nil
+	}
+	for _, loc := range locale.All() {
+		if canonical, ok := loc.CanonicalUnit(name); ok {
+			return findCanonical(canonical)
+		}
+	}
+	return unit, err
+}
+
+// FindIn returns the unit with a name, symbol or alias, or with a name in the language of a locale; like
+// 'Pfund' in German, but not in English.
+func FindIn(name string, loc locale.Locale) (u.Unit, error) {
+	unit, err := findCanonical(name)
+	if err == nil {
+		return unit, nil
+	}
+	if canonical, ok := loc.CanonicalUnit(name); ok {
+		return findCanonical(canonical)
+	}
+	return unit, err
+}
+
+// findCanonical returns the unit with a name, symbol or alias that is not in the language of a locale.
+func findCanonical(name string) (u.Unit, error) {
+	if unit, ok := found.Load(name); ok {
+		return unit.(u.Unit), nil
+	}
+	unit, err := find(name)
+	if err == nil {
+		found.Store(name, unit)
+	}
+	return unit, err
+}
+
+func find(name string) (u.Unit, error) {
+	// the micro sign and the greek letter mu are both used for micro; like µm
+	name = strings.ReplaceAll(name, "µ", "μ")
+	unit, err := u.Find(name)
+	if err == nil {
+		return unit, nil
+	}
+	if canonical, ok := aliases[strings.ToLower(name)]; ok {
+		return u.Find(canonical)
+	}
+	return unit, err
+}
+
+// the names of all units, longest first, and the names of each locale; which also include the names in its language
+var (
+	names          = allNames()
+	localizedNames = map[string]*localized{}
+	namesLock      sync.RWMutex
+)
+
+// the names of all units along with those in the language of a locale, longest first
+type localized struct {
+	names []string          // all of the names
+	index map[rune][]string // the names by their first rune, regardless of case
+}
+
+// Names returns all of the names, symbols and aliases of all units, longest first.
+func Names() []string {
+	namesLock.RLock()
+	defer namesLock.RUnlock()
+	return names
+}
+
+// NamesIn returns all of the names, symbols and aliases of all units along with their names in the
+// language of a locale, longest first.
+func NamesIn(loc locale.Locale) []string {
+	return localizedIn(loc).names
+}
+
+// NamesStartingWith returns the names of NamesIn that start with a rune, regardless of case, longest first;
+// like 'fluid ounces' and 'ft' for 'f'.
+func NamesStartingWith(first rune, loc locale.Locale) []string {
+	return localizedIn(loc).index[foldRune(first)]
+}
+
+// localizedIn returns the names of all units along with those in the language of a locale.
+func localizedIn(loc locale.Locale) *localized {
+	namesLock.RLock()
+	l, ok := localizedNames[loc.Name]
+	namesLock.RUnlock()
+	if ok {
+		return l
+	}
+	namesLock.Lock()
+	defer namesLock.Unlock()
+	all := append([]string(nil), names...)
+	for name := range loc.Units {
+		if _, err := findCanonical(name); err != nil {
+			all = append(all, name)
+		}
+	}
+	sortNames(all)
+	l = &localized{names: all, index: make(map[rune][]string)}
+	for _, name := range all {
+		first, _ := utf8.DecodeRuneInString(name)
+		l.index[foldRune(first)] = append(l.index[foldRune(first)], name)
+	}
+	localizedNames[loc.Name] = l
+	return l
+}
+
+// foldRune returns the same rune for each case of a rune; like 'a' for both 'a' and 'A', or for both the
+// micro sign and the greek letter mu.
+func foldRune(r rune) rune {
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < folded {
+			folded = f
+		}
+	}
+	return folded
+}
+
+func allNames() []string {
+	unique := make(map[string]bool)
+	for _, unit := range u.All() {
+		for _, name := range append(unit.Names(), unit.PluralName()) {
+			unique[name] = true
+		}
+	}
+	for alias := range aliases {
+		unique[alias] = true
+	}
+	var all []string
+	for name := range unique {
+		all = append(all, name)
+	}
+	sortNames(all)
+	return all
+}
+
+// sortNames sorts names longest first, and then alphabetically.
+func sortNames(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		left, right := utf8.RuneCountInString(names[i]), utf8.RuneCountInString(names[j])
+		if left != right {
+			return left > right
+		}
+		return names[i] < names[j]
+	})
+}
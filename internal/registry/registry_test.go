@@ -0,0 +1,101 @@
+package registry
+
+import (
+	u "github.com/bcicen/go-units"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	testCases := map[string]u.Unit{
+		"kg":              u.KiloGram,
+		"pounds":          u.Pound,
+		"Kilogramm":       u.KiloGram,
+		"Pfund":           u.Pound,
+		"Fuß":             u.Foot,
+		"metros":          u.Meter,
+		"pies":            u.Foot,
+		"fluid ounces":    u.FluidOunce,
+		"degrees Celsius": u.Celsius,
+		"°F":              u.Fahrenheit,
+		"'":               u.Foot,
+		"\"":              u.Inch,
+		"µm":              u.MicroMeter,
+		"nautical miles":  NauticalMile,
+		"m/s":             MeterPerSecond,
+		"kph":             KilometerPerHour,
+		"knots":           Knot,
+		"USD":             USDollar,
+		"euros":           Euro,
+		"pound sterling":  BritishPound,
+	}
+	for name, expected := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Find(name)
+			assert.Nil(t, err)
+			assert.Equal(t, expected.Name, actual.Name)
+		})
+	}
+}
+
+func TestFind_Unknown(t *testing.T) {
+	_, err := Find("googles")
+	assert.NotNil(t, err)
+}
+
+func TestFindIn(t *testing.T) {
+	testCases := []struct {
+		name  string
+		loc   locale.Locale
+		found bool
+	}{
+		{"Pfund", locale.German, true},
+		{"Pfund", locale.English, false},
+		{"Pfund", locale.Spanish, false},
+		{"metros", locale.Spanish, true},
+		{"metros", locale.German, false},
+		{"pounds", locale.German, true},
+		{"kg", locale.Default, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name+" "+tc.loc.Name, func(t *testing.T) {
+			_, err := FindIn(tc.name, tc.loc)
+			assert.Equal(t, tc.found, err == nil)
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	assert.Contains(t, names, "fluid ounces")
+	assert.Contains(t, names, "°f")
+	assert.NotContains(t, names, "pfund")
+	for i := 1; i < len(names); i++ {
+		assert.True(t, len([]rune(names[i-1])) >= len([]rune(names[i])), "expected the longest names first")
+	}
+}
+
+func TestNamesIn(t *testing.T) {
+	names := NamesIn(locale.German)
+	assert.Contains(t, names, "fluid ounces")
+	assert.Contains(t, names, "pfund")
+	assert.NotContains(t, names, "metros")
+	for i := 1; i < len(names); i++ {
+		assert.True(t, len([]rune(names[i-1])) >= len([]rune(names[i])), "expected the longest names first")
+	}
+}
+
+func TestNamesStartingWith(t *testing.T) {
+	names := NamesStartingWith('F', locale.German)
+	assert.Contains(t, names, "fluid ounces")
+	assert.Contains(t, names, "ft")
+	assert.Contains(t, names, "fuß")
+	assert.NotContains(t, names, "pounds")
+	for i := 1; i < len(names); i++ {
+		assert.True(t, len([]rune(names[i-1])) >= len([]rune(names[i])), "expected the longest names first")
+	}
+	// the micro sign and the greek letter mu are the same
+	assert.Equal(t, NamesStartingWith('µ', locale.Default), NamesStartingWith('μ', locale.Default))
+	assert.NotContains(t, NamesStartingWith('f', locale.English), "fuß")
+}
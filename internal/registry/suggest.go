@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// the maximum number of suggestions for an unknown unit
+const maxSuggestions = 3
+
+// common misspellings of unit names and their correct spelling
+var misspellings = map[string]string{
+	"celcius":      "celsius",
+	"centigrade":   "celsius",
+	"farenheit":    "fahrenheit",
+	"farenheight":  "fahrenheit",
+	"fahrenheight": "fahrenheit",
+	"foots":        "feet",
+	"feets":        "feet",
+	"inchs":        "inches",
+	"gramme":       "gram",
+	"grammes":      "grams",
+	"kilogramme":   "kilogram",
+	"kilogrammes":  "kilograms",
+	"milimeter":    "millimeter",
+	"milimeters":   "millimeters",
+	"mililiter":    "milliliter",
+	"mililiters":   "milliliters",
+	"ounze":        "ounce",
+	"ounzes":       "ounces",
+	"lites":        "liters",
+}
+
+// a suggested name for an unknown unit
+type suggestion struct {
+	name     string // the suggested name
+	unit     string // the canonical name of the suggested unit
+	distance int    // the edit distance from the unknown name
+}
+
+// Suggest returns the names of known units, including those in the language of a locale, that are similar
+// to an unknown name, most similar first.
+func Suggest(unknown string, loc locale.Locale) []string {
+	lower := strings.ToLower(unknown)
+	if correct, ok := misspellings[lower]; ok {
+		return []string{correct}
+	}
+	// short names like 'xz' are too similar to too many symbols
+	length := utf8.RuneCountInString(lower)
+	if length < 3 {
+		return nil
+	}
+	maxDistance := (length + 3) / 4
+
+	// keep the most similar name for each unit
+	best := make(map[string]suggestion)
+	for _, name := range NamesIn(loc) {
+		// the edit distance is at least the difference in length; allowing for a plural 'es'
+		if diff := utf8.RuneCountInString(name) - length; diff > maxDistance+2 || -diff > maxDistance+2 {
+			continue
+		}
+		distance := variantDistance(lower, strings.ToLower(name))
+		// a symbol like 'Ms' is not similar just because it is short
+		if distance > maxDistance || distance >= utf8.RuneCountInString(name) {
+			continue
+		}
+		unit, err := FindIn(name, loc)
+		if err != nil {
+			continue
+		}
+		current, ok := best[unit.Name]
+		if !ok || distance < current.distance || (distance == current.distance && name < current.name) {
+			best[unit.Name] = suggestion{name, unit.Name, distance}
+		}
+	}
+
+	var ranked []suggestion
+	for _, s := range best {
+		ranked = append(ranked, s)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].distance != ranked[j].distance {
+			return ranked[i].distance < ranked[j].distance
+		}
+		return ranked[i].name < ranked[j].name
+	})
+	var suggestions []string
+	for i := 0; i < len(ranked) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, ranked[i].name)
+	}
+	return suggestions
+}
+
+// variantDistance returns the edit distance between an unknown name and a known name,
+// allowing for the unknown name to be the plural or the singular of the known name.
+func variantDistance(unknown, known string) int {
+	distance := editDistance(unknown, known)
+	for _, variant := range []string{unknown + "s", strings.TrimSuffix(unknown, "s"), strings.TrimSuffix(unknown, "es")} {
+		if variant == unknown {
+			continue
+		}
+		// a plural or singular variant is a closer match than an arbitrary edit
+		if d := editDistance(variant, known) + 1; d < distance {
+			distance = d
+		}
+	}
+	return distance
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions needed to change
+// one string into another; also known as the optimal string alignment distance.
+func editDistance(from, to string) int {
+	a, b := []rune(from), []rune(to)
+	// only the last three rows of the distance matrix are needed
+	previous, last, current := make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range last {
+		last[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(last[j]+1, current[j-1]+1, last[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous[j-2]+1)
+			}
+		}
+		previous, last, current = last, current, previous
+	}
+	return last[len(b)]
+}
+
+func min(values ...int) int {
+	smallest := values[0]
+	for _, v := range values[1:] {
+		if v < smallest {
+			smallest = v
+		}
+	}
+	return smallest
+}
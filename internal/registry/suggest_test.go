@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	testCases := map[string][]string{
+		"punds":         {"pounds", "pints"},
+		"kilgrams":      {"kilograms"},
+		"meterz":        {"meter"},
+		"gallns":        {"gallons"},
+		"poundss":       {"pounds"},
+		"nautical mils": {"nautical mile"},
+		"celcius":       {"celsius"},
+		"Farenheit":     {"fahrenheit"},
+		"foots":         {"feet"},
+	}
+	for name, expected := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, Suggest(name, locale.English))
+		})
+	}
+}
+
+func TestSuggest_Locale(t *testing.T) {
+	// names in the language of another locale are not suggested
+	assert.Equal(t, []string{"miles"}, Suggest("mlies", locale.English))
+	assert.Equal(t, []string{"pfund"}, Suggest("Pfnud", locale.German))
+}
+
+func TestSuggest_NoSuggestions(t *testing.T) {
+	for _, name := range []string{"googles", "xz", "zzzzzzzz"} {
+		t.Run(name, func(t *testing.T) {
+			assert.Empty(t, Suggest(name, locale.English))
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("pound", "pound"))
+	assert.Equal(t, 1, editDistance("pund", "pound"))
+	assert.Equal(t, 1, editDistance("pnuod", "pnoud"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("µm", "μm"))
+}
@@ -0,0 +1,108 @@
+// Package service evaluates the expressions of a request to a service, like the HTTP and gRPC servers, with the
+// settings of the request and the variables that its expressions assign.
+package service
+
+import (
+	"context"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"strconv"
+	"strings"
+)
+
+// Settings The settings of a request; those that are empty are the defaults of the service.
+type Settings struct {
+	Locale    string // the locale of numbers and messages; like 'de'
+	Precision *int   // the number of decimal places in each result
+	Region    string // the region preferred for ambiguous units; like 'uk'
+}
+
+// Defaults The settings of a service, unless a request sets them.
+type Defaults struct {
+	Options   []calc.Option // the options used to calculate every expression
+	Locale    calc.Locale   // governs how numbers are read and written
+	Precision int           // the number of decimal places in a result
+}
+
+// Evaluator Evaluates the expressions of a request, or of a session, with the variables that they assign.
+type Evaluator struct {
+	opts   []calc.Option          // the options used to calculate each expression
+	locale calc.Locale            // the locale of numbers and messages
+	vars   map[string]calc.Amount // the value of each variable that is assigned
+}
+
+// Result The result of an expression, or what is wrong with it.
+type Result struct {
+	Input      string                 // the expression
+	Name       string                 // the variable that is assigned the result, if any
+	Result     string                 // the formatted result; like '2.00 kg'
+	Amount     calc.Amount            // the value of the result
+	Unit       *calc.Unit             // the units of the result, if they are known
+	Diagnostic *diagnostic.Diagnostic // what is wrong with the expression, if anything
+}
+
+// New returns an evaluator with the settings of a request, or the defaults of the service; returns an error if the
+// settings are not valid, like a locale that is not known.
+func New(defaults Defaults, settings Settings) (*Evaluator, error) {
+	loc, precision := defaults.Locale, defaults.Precision
+	if settings.Locale != "" {
+		found, err := calc.FindLocale(settings.Locale)
+		if err != nil {
+			return nil, err
+		}
+		loc = found
+	}
+	if settings.Precision != nil {
+		if *settings.Precision < 0 || *settings.Precision > calc.MaxPrecision {
+			return nil, fmt.Errorf("expected a precision from 0 to %d, but got %d", calc.MaxPrecision, *settings.Precision)
+		}
+		precision = *settings.Precision
+	}
+	opts := append([]calc.Option(nil), defaults.Options...)
+	opts = append(opts, calc.WithLocale(loc), calc.WithPrecision(precision))
+	if settings.Region != "" {
+		region, err := calc.FindRegion(settings.Region)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, calc.WithRegion(region))
+	}
+	return &Evaluator{opts: opts, locale: loc, vars: map[string]calc.Amount{}}, nil
+}
+
+// Evaluate evaluates an expression, and assigns its value to a variable if it is an assignment; the evaluation
+// stops once the context is done.
+func (e *Evaluator) Evaluate(ctx context.Context, input string) Result {
+	opts := append(append([]calc.Option(nil), e.opts...), calc.WithVariables(e.vars))
+	name, amt, err := calc.CalculateAssignmentContext(ctx, input, opts...)
+	if err != nil {
+		d := diagnostic.Translate(err, e.locale)
+		return Result{Input: input, Diagnostic: &d}
+	}
+	if name != "" {
+		e.vars[name] = amt
+	}
+	result := Result{Input: input, Name: name, Result: calc.Format(amt, opts...), Amount: amt}
+	if unit, err := calc.FindUnit(amt.Units.Value, opts...); err == nil {
+		result.Unit = &unit
+	}
+	return result
+}
+
+// Convert converts a value from one unit to another. The units must each be a unit, rather than part of an
+// expression like 'kg + 2 kg'.
+func (e *Evaluator) Convert(ctx context.Context, number float64, from, to string) Result {
+	// the value is written like '2.5' in any locale, but is read by the calculator in the locale of the evaluator
+	value := strings.Replace(strconv.FormatFloat(number, 'f', -1, 64), ".", string(e.locale.Decimal), 1)
+	input := fmt.Sprintf("%s %s in %s", value, from, to)
+	for _, units := range []types.Token{types.Units.TokenAt(from, len(value)+2), types.Units.TokenAt(to, len(input)-len(to)+1)} {
+		if _, err := calc.FindUnit(units.Value, e.opts...); err != nil {
+			d := diagnostic.Translate(types.ErrorInvalidUnits(input, units, registry.Suggest(units.Value, e.locale)...), e.locale)
+			return Result{Input: input, Diagnostic: &d}
+		}
+	}
+	return e.Evaluate(ctx, input)
+}
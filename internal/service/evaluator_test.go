@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// the defaults used by tests
+var defaults = Defaults{Locale: locale.Default, Precision: calc.DefaultPrecision}
+
+func TestEvaluate(t *testing.T) {
+	e, err := New(defaults, Settings{})
+	assert.Nil(t, err)
+	rent := e.Evaluate(context.Background(), "rent = 1200 kg")
+	assert.Nil(t, rent.Diagnostic)
+	assert.Equal(t, "rent", rent.Name)
+
+	// the variables are shared by the expressions of the evaluator
+	result := e.Evaluate(context.Background(), "rent / 3 in g")
+	assert.Nil(t, result.Diagnostic)
+	assert.Equal(t, "400000.00 g", result.Result)
+	assert.Equal(t, 400000.0, result.Amount.Value)
+	if assert.NotNil(t, result.Unit) {
+		assert.Equal(t, "gram", result.Unit.Name)
+		assert.Equal(t, "mass", result.Unit.Quantity)
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	e, err := New(defaults, Settings{Locale: "es"})
+	assert.Nil(t, err)
+	result := e.Evaluate(context.Background(), "2 metros + 3 kg")
+	if assert.NotNil(t, result.Diagnostic) {
+		assert.Equal(t, diagnostic.IncompatibleUnits, result.Diagnostic.Code)
+		assert.Equal(t, "no se puede convertir de kg a metros", result.Diagnostic.Message)
+	}
+	assert.Empty(t, result.Result)
+}
+
+func TestNewWithSettings(t *testing.T) {
+	precision := 0
+	e, err := New(defaults, Settings{Locale: "de", Precision: &precision, Region: "us"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.814 kg", e.Evaluate(context.Background(), "2 t in kg").Result)
+}
+
+func TestNewInvalidSettings(t *testing.T) {
+	tooPrecise, negative := calc.MaxPrecision+1, -1
+	tests := map[string]Settings{
+		"locale":    {Locale: "xx"},
+		"region":    {Region: "mars"},
+		"precision": {Precision: &tooPrecise},
+		"negative":  {Precision: &negative},
+	}
+	for name, settings := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(defaults, settings)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := map[string]struct {
+		from, to string
+		result   string
+		code     diagnostic.Code
+	}{
+		"units":      {"kg", "lb", "5.51 lb", ""},
+		"unknown":    {"kg", "punds", "", diagnostic.UnknownUnit},
+		"expression": {"kg + 2 kg", "lb", "", diagnostic.UnknownUnit},
+		"different":  {"kg", "m", "", diagnostic.IncompatibleUnits},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := New(defaults, Settings{})
+			assert.Nil(t, err)
+			result := e.Convert(context.Background(), 2.5, test.from, test.to)
+			assert.Equal(t, test.result, result.Result)
+			if test.code == "" {
+				assert.Nil(t, result.Diagnostic)
+			} else if assert.NotNil(t, result.Diagnostic) {
+				assert.Equal(t, test.code, result.Diagnostic.Code)
+			}
+		})
+	}
+}
+
+func TestConvertInLocale(t *testing.T) {
+	e, err := New(defaults, Settings{Locale: "de"})
+	assert.Nil(t, err)
+	result := e.Convert(context.Background(), 2.5, "kg", "g")
+	assert.Equal(t, "2,5 kg in g", result.Input)
+	assert.Equal(t, "2.500,00 g", result.Result)
+}
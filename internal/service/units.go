@@ -0,0 +1,30 @@
+package service
+
+import (
+	calc "github.com/nickwallen/quick-calc"
+	"strings"
+)
+
+// Units returns the known units of a quantity, or of every quantity if none, whose names contain a search term,
+// ignoring case.
+func Units(quantity, search string) []calc.Unit {
+	search = strings.ToLower(search)
+	units := []calc.Unit{}
+	for _, unit := range calc.Units() {
+		if quantity != "" && unit.Quantity != quantity || !matches(unit, search) {
+			continue
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// matches returns true if a name of a unit contains the search term, which is in lower case.
+func matches(unit calc.Unit, search string) bool {
+	for _, name := range append([]string{unit.Name, unit.Symbol, unit.Plural}, unit.Aliases...) {
+		if strings.Contains(strings.ToLower(name), search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	for _, unit := range Units("mass", "") {
+		assert.Equal(t, "mass", unit.Quantity)
+	}
+	assert.NotEmpty(t, Units("mass", ""))
+	assert.Empty(t, Units("mass", "meter"))
+	assert.Empty(t, Units("", "no such unit"))
+}
+
+func TestUnitsSearch(t *testing.T) {
+	var names []string
+	for _, unit := range Units("", "KILOGRAM") {
+		names = append(names, unit.Name)
+	}
+	assert.Contains(t, names, "kilogram")
+}
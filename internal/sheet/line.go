@@ -0,0 +1,21 @@
+// Package sheet reads the lines of a sheet; a file of calculations, like a budget, that are evaluated in order with
+// shared variables.
+package sheet
+
+import (
+	"strings"
+)
+
+// SplitLine splits a line into an expression and the comment that follows it; like 'rent = 1200 USD # per month'.
+// A line without an expression, like a blank line or a comment that starts with '#', is all comment and is not
+// evaluated.
+func SplitLine(line string) (expr, comment string) {
+	expr = line
+	if i := strings.IndexRune(line, '#'); i >= 0 {
+		expr, comment = line[:i], line[i:]
+	}
+	if strings.TrimSpace(expr) == "" {
+		return "", line
+	}
+	return expr, comment
+}
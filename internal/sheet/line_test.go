@@ -0,0 +1,21 @@
+package sheet
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := map[string][]string{
+		"2 kg":            {"2 kg", ""},
+		"2 kg # per week": {"2 kg ", "# per week"},
+		"# a note":        {"", "# a note"},
+		"  # a note":      {"", "  # a note"},
+		"  ":              {"", "  "},
+		"":                {"", ""},
+	}
+	for line, expected := range tests {
+		expr, comment := SplitLine(line)
+		assert.Equal(t, expected, []string{expr, comment}, line)
+	}
+}
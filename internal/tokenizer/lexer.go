@@ -0,0 +1,88 @@
+package tokenizer
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/types"
+)
+
+// Lexer Tokenizes an input string on demand, one token at a time, without a goroutine or a channel.
+type Lexer struct {
+	tok   *tokenizer  // runs the state functions that find tokens
+	queue *tokenQueue // the tokens found, but not yet returned
+}
+
+// NewLexer Creates a lexer for an input string.
+func NewLexer(input string, opts ...Option) *Lexer {
+	queue := &tokenQueue{tokens: make([]types.Token, 0, 2)}
+	tok := &tokenizer{
+		state:  start,
+		input:  input,
+		writer: queue,
+		locale: locale.Default,
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(tok)
+	}
+	return &Lexer{tok: tok, queue: queue}
+}
+
+// Next returns the next token. Once the input is exhausted, or after an error token, only EOF is returned.
+func (l *Lexer) Next() types.Token {
+	// run the state functions until a token is found
+	for l.queue.empty() {
+		if l.tok.state == nil {
+			return types.EOF.TokenAt("", len(l.tok.input)+1)
+		}
+		if err := l.tok.ctx.Err(); err != nil {
+			// stop, even when recovering from errors
+			l.tok.error("the calculation was cancelled; %s", err)
+			l.tok.state = nil
+			continue
+		}
+		l.tok.state = l.tok.state(l.tok)
+	}
+	return l.queue.pop()
+}
+
+// ReadToken reads the next token; it allows a lexer to be read by the parser.
+func (l *Lexer) ReadToken() (types.Token, error) {
+	return l.Next(), nil
+}
+
+// Input returns the original input that is tokenized.
+func (l *Lexer) Input() string {
+	return l.tok.input
+}
+
+// tokenQueue Holds the tokens found by the state functions until they are returned by the lexer.
+type tokenQueue struct {
+	tokens []types.Token // the queued tokens
+	head   int           // the index of the oldest token that has not been returned
+}
+
+// WriteToken adds a token to the queue.
+func (q *tokenQueue) WriteToken(token types.Token) error {
+	q.tokens = append(q.tokens, token)
+	return nil
+}
+
+// Close does nothing; the lexer returns EOF once the state functions are done.
+func (q *tokenQueue) Close() {}
+
+// empty returns true if every token in the queue has been returned.
+func (q *tokenQueue) empty() bool {
+	return q.head >= len(q.tokens)
+}
+
+// pop removes the oldest token from the queue.
+func (q *tokenQueue) pop() types.Token {
+	token := q.tokens[q.head]
+	q.head++
+	if q.empty() {
+		// reuse the underlying array
+		q.tokens, q.head = q.tokens[:0], 0
+	}
+	return token
+}
@@ -0,0 +1,144 @@
+package tokenizer
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/io"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLexer(t *testing.T) {
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			lexer := NewLexer(input)
+			for _, expect := range expected {
+				assert.Equal(t, expect, lexer.Next(), "'%s'", input)
+			}
+		})
+	}
+}
+
+func TestLexerWithLocale(t *testing.T) {
+	for input, expected := range germanTestCases {
+		t.Run(input, func(t *testing.T) {
+			lexer := NewLexer(input, WithLocale(locale.German))
+			for _, expect := range expected {
+				assert.Equal(t, expect, lexer.Next(), "'%s'", input)
+			}
+		})
+	}
+}
+
+func TestLexerAfterEOF(t *testing.T) {
+	lexer := NewLexer("2 kg")
+	assert.Equal(t, types.Number.TokenAt("2", 1), lexer.Next())
+	assert.Equal(t, types.Units.TokenAt("kg", 3), lexer.Next())
+	assert.Equal(t, types.EOF.TokenAt("", 5), lexer.Next())
+	assert.Equal(t, types.EOF.TokenAt("", 5), lexer.Next())
+}
+
+func TestLexerAfterError(t *testing.T) {
+	lexer := NewLexer("2 kg ?")
+	lexer.Next()
+	lexer.Next()
+	assert.Equal(t, types.Error, lexer.Next().TokenType)
+	assert.Equal(t, types.EOF.TokenAt("", 7), lexer.Next())
+}
+
+func TestLexerWithRecovery(t *testing.T) {
+	lexer := NewLexer("0x + 2 $$ in lbs", WithRecovery())
+	expected := []types.Token{
+		types.Error.TokenAt("expected number, but got '0x'", 1),
+		types.Plus.TokenAt("+", 4),
+		types.Number.TokenAt("2", 6),
+		types.Error.TokenAt("expected symbol, but got '$'", 8),
+		types.In.TokenAt("in", 11),
+		types.Units.TokenAt("lbs", 14),
+		types.EOF.TokenAt("", 17),
+	}
+	for _, expect := range expected {
+		assert.Equal(t, expect, lexer.Next())
+	}
+}
+
+func TestLexerWithRecoveryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lexer := NewLexer("2 kg", WithRecovery(), WithContext(ctx))
+	assert.Equal(t, types.Error, lexer.Next().TokenType)
+	assert.Equal(t, types.EOF.TokenAt("", 5), lexer.Next())
+}
+
+func TestLexerReadToken(t *testing.T) {
+	lexer := NewLexer("2 kg")
+	token, err := lexer.ReadToken()
+	assert.Nil(t, err)
+	assert.Equal(t, types.Number.TokenAt("2", 1), token)
+	assert.Equal(t, "2 kg", lexer.Input())
+}
+
+// the input used to benchmark tokenization
+const benchmarkInput = "12 oz + 1.2 lbs + 24 oz - 0.8 lbs in pounds"
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lexer := NewLexer(benchmarkInput)
+		for lexer.Next().TokenType != types.EOF {
+		}
+	}
+}
+
+func BenchmarkTokenizeChannel(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		output := io.NewTokenChannel(benchmarkInput)
+		go Tokenize(benchmarkInput, output)
+		for {
+			token, _ := output.ReadToken()
+			if token.TokenType == types.EOF {
+				break
+			}
+		}
+	}
+}
+
+func TestTokenizeWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output := io.NewTokenChannel("2 kg + 3 kg")
+	Tokenize("2 kg + 3 kg", output, WithContext(ctx))
+
+	// nothing is written once the context is done, but the writer is closed
+	token, err := output.ReadToken()
+	assert.Nil(t, err)
+	assert.Equal(t, types.EOF, token.TokenType)
+}
+
+func TestTokenizeCancelled(t *testing.T) {
+	output := io.NewTokenChannel("2 kg + 3 kg + 4 kg + 5 kg")
+	output.Cancel()
+	done := make(chan bool)
+	go func() {
+		Tokenize("2 kg + 3 kg + 4 kg + 5 kg", output)
+		done <- true
+	}()
+	// the tokenizer returns even though no tokens are read
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tokenizer is blocked")
+	}
+}
+
+func TestLexerWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lexer := NewLexer("2 kg + 3 kg", WithContext(ctx))
+	assert.Equal(t, types.Number.TokenAt("2", 1), lexer.Next())
+	cancel()
+	assert.Equal(t, types.Error.TokenAt("the calculation was cancelled; context canceled", 3), lexer.Next())
+	assert.Equal(t, types.EOF, lexer.Next().TokenType)
+}
@@ -1,8 +1,12 @@
 package tokenizer
 
 import (
+	"context"
 	"fmt"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/registry"
 	"github.com/nickwallen/quick-calc/internal/types"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -12,14 +16,56 @@ const (
 	eofRune = rune(0)
 )
 
+// the symbols for a unit conversion in any locale; 5 km -> mi
+var conversionSymbols = []string{"->", "=>"}
+
 // tokenizer A tokenizer performs lexical analysis on an input string.
 type tokenizer struct {
-	state  stateFn     // the current state function
-	input  string      // the string to scan
-	start  int         // start position for this item
-	pos    int         // current position in the input
-	width  int         // width of the last rune read
-	writer tokenWriter // allows the tokenizer to write tokens that it finds
+	state    stateFn         // the current state function
+	input    string          // the string to scan
+	start    int             // start position for this item
+	pos      int             // current position in the input
+	width    int             // width of the last rune read
+	writer   tokenWriter     // allows the tokenizer to write tokens that it finds
+	locale   locale.Locale   // governs the decimal and grouping separators of numbers
+	ctx      context.Context // stops the tokenizer once done
+	recovery bool            // continues after an error, rather than stopping
+	vars     []string        // the names of variables, longest first
+	factor   bool            // true if the next number multiplies or divides, and so has no units
+}
+
+// Option configures the tokenizer.
+type Option func(*tokenizer)
+
+// WithLocale sets the locale that governs how numbers are written.
+func WithLocale(loc locale.Locale) Option {
+	return func(tok *tokenizer) {
+		tok.locale = loc
+	}
+}
+
+// WithContext sets a context that stops the tokenizer once done.
+func WithContext(ctx context.Context) Option {
+	return func(tok *tokenizer) {
+		tok.ctx = ctx
+	}
+}
+
+// WithRecovery continues tokenizing after an error so that every problem in the input can be found.
+func WithRecovery() Option {
+	return func(tok *tokenizer) {
+		tok.recovery = true
+	}
+}
+
+// WithVariables sets the names of the variables that can be used in place of a value; like 'rent'.
+func WithVariables(names []string) Option {
+	return func(tok *tokenizer) {
+		tok.vars = append([]string(nil), names...)
+		sort.SliceStable(tok.vars, func(i, j int) bool {
+			return len(tok.vars[i]) > len(tok.vars[j])
+		})
+	}
 }
 
 // the state of the scanner as a function that returns the next state.
@@ -31,14 +77,20 @@ type tokenWriter interface {
 	Close()
 }
 
-// Tokenize Tokenize the input string and writes each Token to the output channel.
-func Tokenize(input string, writer tokenWriter) {
-	tok := &tokenizer{
-		state:  start,
-		input:  input,
-		writer: writer,
+// Tokenize Tokenize the input string and writes each Token to the output channel. This adapts
+// the Lexer for a writer like a TokenChannel that is read by another goroutine. Tokenize returns
+// once all of the tokens are written, the writer fails, or the context of the tokenizer is done.
+func Tokenize(input string, writer tokenWriter, opts ...Option) {
+	lexer := NewLexer(input, opts...)
+	for lexer.tok.ctx.Err() == nil {
+		token := lexer.Next()
+		err := writer.WriteToken(token)
+		stop := token.TokenType == types.EOF || (token.TokenType == types.Error && !lexer.tok.recovery)
+		if err != nil || stop {
+			break
+		}
 	}
-	tok.run()
+	writer.Close()
 }
 
 // returns what is currently being scanned
@@ -54,6 +106,9 @@ func (tok *tokenizer) emit(tokenType types.TokenType) error {
 	switch tokenType {
 	case types.EOF:
 		token = types.EOF.TokenAt("", len(tok.input)+1)
+	case types.Units, types.Name:
+		// the names of units and variables like 'fluid ounces' may contain spaces
+		token = tokenType.TokenAt(tok.input[tok.start:tok.pos], tok.start+1)
 	default:
 		token = tokenType.TokenAt(tok.current(), tok.start+1)
 	}
@@ -91,6 +146,115 @@ func (tok *tokenizer) backup() {
 	tok.pos -= tok.width
 }
 
+// lookingAt returns true if the pending input starts with a prefix.
+func (tok *tokenizer) lookingAt(prefix string) bool {
+	return strings.HasPrefix(tok.input[tok.pos:], prefix)
+}
+
+// conversion returns the symbol or keyword for a unit conversion, like '->' or 'in', that is next in the input.
+// A keyword must be followed by whitespace to distinguish it from units like 'in' for inches.
+func (tok *tokenizer) conversion() string {
+	pending := tok.input[tok.pos:]
+	for _, symbol := range conversionSymbols {
+		if strings.HasPrefix(pending, symbol) {
+			return symbol
+		}
+	}
+	for _, keyword := range tok.locale.Keywords {
+		if len(pending) <= len(keyword) || !strings.EqualFold(pending[:len(keyword)], keyword) {
+			continue
+		}
+		if next, _ := utf8.DecodeRuneInString(pending[len(keyword):]); unicode.IsSpace(next) {
+			return pending[:len(keyword)]
+		}
+	}
+	return ""
+}
+
+// finalConversion returns the keyword for a unit conversion, like 'in', that ends the input. After units, a
+// variable or a group, the keyword is a conversion with its units missing rather than units like 'in' for inches.
+func (tok *tokenizer) finalConversion() string {
+	pending := tok.input[tok.pos:]
+	for _, keyword := range tok.locale.Keywords {
+		if strings.EqualFold(pending, keyword) {
+			return pending
+		}
+	}
+	return ""
+}
+
+// radixConversion returns true if a conversion to a radix, like 'in hex', is next in the input; a number
+// without units can be displayed in a radix, so 'in' is a conversion rather than inches.
+func (tok *tokenizer) radixConversion() bool {
+	conversion := tok.conversion()
+	if conversion == "" {
+		return false
+	}
+	rest := strings.TrimLeftFunc(tok.input[tok.pos+len(conversion):], unicode.IsSpace)
+	end := strings.IndexFunc(rest, func(r rune) bool { return !isAlphaNum(r) })
+	if end < 0 {
+		end = len(rest)
+	}
+	return types.IsRadix(rest[:end])
+}
+
+// unitName returns the longest name of a known unit, like 'fluid ounces' or '°F', that is next in the input.
+func (tok *tokenizer) unitName() string {
+	pending := tok.input[tok.pos:]
+	first, _ := utf8.DecodeRuneInString(pending)
+	for _, name := range registry.NamesStartingWith(first, tok.locale) {
+		if len(pending) < len(name) || !strings.EqualFold(pending[:len(name)], name) {
+			continue
+		}
+		// a name ending in a letter or number must not be followed by one; 'in' is not a match for 'ints'
+		last, _ := utf8.DecodeLastRuneInString(name)
+		next, _ := utf8.DecodeRuneInString(pending[len(name):])
+		if isAlphaNum(last) && isAlphaNum(next) {
+			continue
+		}
+		return pending[:len(name)]
+	}
+	return ""
+}
+
+// variableName returns the longest name of a variable, like 'rent', that is next in the input.
+func (tok *tokenizer) variableName() string {
+	pending := tok.input[tok.pos:]
+	for _, name := range tok.vars {
+		if !strings.HasPrefix(pending, name) {
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(pending[len(name):])
+		if isAlphaNum(next) || next == '_' {
+			continue
+		}
+		return name
+	}
+	return ""
+}
+
+// assignment returns the name of the variable that is assigned a value, like 'per person' in
+// 'per person = rent / 3', when the rest of the input is an assignment.
+func (tok *tokenizer) assignment() string {
+	pending := tok.input[tok.pos:]
+	i := strings.IndexRune(pending, '=')
+	if i <= 0 || strings.HasPrefix(pending[i:], "=>") {
+		return ""
+	}
+	name := strings.TrimRightFunc(pending[:i], unicode.IsSpace)
+	for j, r := range name {
+		if j == 0 && !unicode.IsLetter(r) || !isAlphaNum(r) && r != '_' && r != ' ' {
+			return ""
+		}
+	}
+	return name
+}
+
+// isAlphaNum returns true if a rune is a letter or a number.
+func isAlphaNum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
 // peek returns, but does not consume the next rune in the input.
 func (tok *tokenizer) peek() rune {
 	next := tok.next()
@@ -128,6 +292,15 @@ func (tok *tokenizer) acceptRun(valid string) (count int) {
 	return count
 }
 
+// acceptDigitRun consumes a run of digits, stopping at the '..' of an interval
+func (tok *tokenizer) acceptDigitRun(digits string) (count int) {
+	for !tok.lookingAt("..") && tok.accept(digits) {
+		// keep consuming runes
+		count++
+	}
+	return count
+}
+
 // acceptLetterRun consumes a run of alphabetic characters
 func (tok *tokenizer) acceptLetterRun() (count int) {
 	for unicode.IsLetter(tok.next()) {
@@ -150,32 +323,66 @@ func (tok *tokenizer) acceptAlphaNumRun() (count int) {
 	return count
 }
 
-// run lexes the input by executing state functions until the state is nil
-func (tok *tokenizer) run() {
-	startState := tok.state
-	for state := startState; state != nil; {
-		state = state(tok)
-	}
-	tok.writer.Close()
-}
-
 func (tok *tokenizer) error(format string, args ...interface{}) stateFn {
-	msg := fmt.Sprintf(format, args...)
+	msg := tok.locale.Sprintf(format, args...)
 	token := types.Error.TokenAt(msg, tok.start+1)
 	err := tok.writer.WriteToken(token)
 	if err != nil {
 		panic(fmt.Sprintf("unable to write token; %s", err))
 	}
+	if tok.recovery {
+		return resume
+	}
 	// stop the tokenizer
 	return nil
 }
 
+// resume the state function that continues after an error. The rest of the bad input, up to
+// the next space or operator, is skipped and then whatever comes next is tokenized.
+func resume(tok *tokenizer) stateFn {
+	if tok.pos == tok.start {
+		// always make progress
+		tok.next()
+	}
+	for next := tok.peek(); next != eofRune && !unicode.IsSpace(next) && !isOperator(next) && !isParen(next); next = tok.peek() {
+		tok.next()
+	}
+	tok.ignore()
+	tok.ignoreSpaceRun()
+	switch next := tok.peek(); {
+	case next == eofRune:
+		return expectEOF
+	case tok.conversion() != "":
+		return expectIn
+	case isOperator(next), next == ')':
+		return expectSymbol
+	case unicode.IsLetter(next):
+		return expectUnits
+	default:
+		return expectNumber
+	}
+}
+
+// isOperator returns true if a rune is an operator like '+'.
+func isOperator(r rune) bool {
+	return strings.ContainsRune("+-*/", r)
+}
+
+// isParen returns true if a rune opens or closes a group like '(2 ft + 3 in)'.
+func isParen(r rune) bool {
+	return r == '(' || r == ')'
+}
+
 // start the state function that we start at.
 func start(tok *tokenizer) stateFn {
 	// numbers or units are reasonable to expect at the start
 	tok.ignoreSpaceRun()
 	next := tok.peek()
 	switch {
+	case tok.assignment() != "":
+		return expectAssignment
+	case tok.variableName() != "":
+		return expectVariable
 	case unicode.IsLetter(next):
 		return expectUnits
 	default:
@@ -185,31 +392,71 @@ func start(tok *tokenizer) stateFn {
 
 // the state function expecting a number
 func expectNumber(tok *tokenizer) stateFn {
+	factor := tok.factor
+	tok.factor = false
 	tok.ignoreSpaceRun()
 
+	// a variable can be used in place of a number and its units
+	if tok.variableName() != "" {
+		return expectVariable
+	}
+
+	// a group like '(2 ft + 3 in)' can be used in place of a number and its units
+	if tok.accept("(") {
+		err := tok.emit(types.LeftParen)
+		if err != nil {
+			return tok.error("cannot emit token; %s", err)
+		}
+		return expectNumber
+	}
+
 	// optional sign
+	negative := tok.peek() == '-'
 	tok.accept("+-")
 	tok.acceptRun(" ")
+	if negative && tok.lookingAt("(") {
+		// a group cannot be negated; like '-(2 kg)'
+		return tok.error("'-' cannot negate a group; multiply it by -1 instead")
+	}
 
-	// expect decimal values
-	digits := "0123456789,"
+	// expect decimal values; the digits may be grouped like 1,000
+	group := string(tok.locale.Group)
+	decimalDigits := "0123456789_" + group
+	digits := decimalDigits
 	decimal := true
 
+	// a leading zero may be followed by a prefix like '0x' for hexadecimal
 	leadZero := tok.accept("0")
-	if leadZero && tok.accept("xX") {
-		// expect hexadecimal values
-		digits = "0123456789ABCDEF"
+	afterZero := tok.pos
+	prefix := eofRune
+	if leadZero && tok.accept("xXoObB") {
+		prefix = unicode.ToLower(rune(tok.input[afterZero]))
 		decimal = false
 	}
+	switch prefix {
+	case 'x':
+		digits = "0123456789abcdefABCDEF_"
+	case 'o':
+		digits = "01234567_"
+	case 'b':
+		digits = "01_"
+	}
 
-	// avoid leading commas
-	if tok.accept(",") {
+	// avoid leading group separators
+	if tok.accept(group) {
 		tok.next()
 		return tok.error("expected number, but got '%s'", tok.current())
 	}
 
 	// accept a run of digits
-	count := tok.acceptRun(digits)
+	count := tok.acceptDigitRun(digits)
+
+	// without any digits, '0b' and '0o' are a zero followed by units; like '0bytes' or '0oz'
+	if (prefix == 'b' || prefix == 'o') && count <= 0 {
+		tok.pos = afterZero
+		digits = decimalDigits
+		decimal = true
+	}
 
 	// validate that we have valid digits
 	invalidHex := !decimal && count <= 0
@@ -219,15 +466,17 @@ func expectNumber(tok *tokenizer) stateFn {
 		return tok.error("expected number, but got '%s'", tok.current())
 	}
 
-	// floating point number
-	if tok.accept(".") {
-		tok.acceptRun(digits)
-	}
+	if decimal {
+		// floating point number; avoid consuming the start of an interval like '3..5'
+		if !tok.lookingAt("..") && tok.accept(string(tok.locale.Decimal)) {
+			tok.acceptDigitRun(digits)
+		}
 
-	// scientific notation
-	if tok.accept("eE") {
-		tok.accept("+-")
-		tok.acceptRun("0123456789")
+		// scientific notation
+		if tok.accept("eE") {
+			tok.accept("+-")
+			tok.acceptRun("0123456789")
+		}
 	}
 
 	// we have the number
@@ -242,13 +491,35 @@ func expectNumber(tok *tokenizer) stateFn {
 	switch {
 	case next == eofRune, next == '\n':
 		return expectEOF
-	case unicode.IsLetter(next) || unicode.IsNumber(next):
+	case factor && tok.conversion() != "":
+		// a factor has no units, so 'in' is a conversion rather than inches; like '2 kg * 3 in lb'
+		return expectIn
+	case tok.radixConversion():
+		// like '255 in hex'
+		return expectIn
+	case tok.lookingAt(".."):
+		return expectRange
+	case isAlphaNum(next) || tok.unitName() != "":
 		return expectUnits
 	default:
 		return expectSymbol
 	}
 }
 
+// the state function where the '..' of an interval is expected
+func expectRange(tok *tokenizer) stateFn {
+	tok.ignoreSpaceRun()
+	if tok.accept(".") && tok.accept(".") {
+		err := tok.emit(types.Range)
+		if err != nil {
+			return tok.error("cannot emit token; %s", err)
+		}
+		return expectNumber
+	}
+	tok.next()
+	return tok.error("expected '..', but got '%s'", tok.current())
+}
+
 // the state function where an EOF is expected
 func expectEOF(tok *tokenizer) stateFn {
 	tok.ignoreSpaceRun()
@@ -284,13 +555,25 @@ func expectSymbol(tok *tokenizer) stateFn {
 			if err != nil {
 				return tok.error("cannot emit token; %s", err)
 			}
+			tok.factor = true
 			return expectNumber
 		case next == '/':
 			err := tok.emit(types.Divide)
 			if err != nil {
 				return tok.error("cannot emit token; %s", err)
 			}
+			tok.factor = true
 			return expectNumber
+		case next == ')':
+			err := tok.emit(types.RightParen)
+			if err != nil {
+				return tok.error("cannot emit token; %s", err)
+			}
+			// a group may be converted; like '(2 ft + 3 in) in cm'
+			tok.ignoreSpaceRun()
+			if tok.conversion() != "" || tok.finalConversion() != "" {
+				return expectIn
+			}
 		case unicode.IsSpace(next):
 			tok.ignore()
 		case next == eofRune:
@@ -305,8 +588,10 @@ func expectSymbol(tok *tokenizer) stateFn {
 // the state function where units are expected
 func expectUnits(tok *tokenizer) stateFn {
 	tok.ignoreSpaceRun()
-	count := tok.acceptAlphaNumRun()
-	if count <= 0 {
+	if name := tok.unitName(); name != "" {
+		// prefer the longest known name; like 'fluid ounces' rather than 'fluid'
+		tok.pos += len(name)
+	} else if count := tok.acceptAlphaNumRun(); count <= 0 {
 		tok.next()
 		return tok.error("expected units, but got '%s'", tok.current())
 	}
@@ -318,10 +603,7 @@ func expectUnits(tok *tokenizer) stateFn {
 	// what is next?
 	tok.ignoreSpaceRun()
 	switch {
-	case tok.accept("iI") && tok.accept("nN") && tok.accept(" "):
-		tok.backup()
-		tok.backup()
-		tok.backup()
+	case tok.conversion() != "", tok.finalConversion() != "":
 		return expectIn
 	case unicode.IsLetter(tok.peek()):
 		return expectUnits
@@ -332,14 +614,60 @@ func expectUnits(tok *tokenizer) stateFn {
 	}
 }
 
-// the state function where 'in' is expected
+// the state function where an assignment like 'rent = 1200 USD' is expected
+func expectAssignment(tok *tokenizer) stateFn {
+	tok.pos += len(tok.assignment())
+	err := tok.emit(types.Name)
+	if err != nil {
+		return tok.error("cannot emit token; %s", err)
+	}
+	tok.ignoreSpaceRun()
+	if !tok.accept("=") {
+		tok.next()
+		return tok.error("expected '=', but got '%s'", tok.current())
+	}
+	err = tok.emit(types.Assign)
+	if err != nil {
+		return tok.error("cannot emit token; %s", err)
+	}
+	return expectNumber
+}
+
+// the state function where a variable like 'rent' is expected
+func expectVariable(tok *tokenizer) stateFn {
+	tok.ignoreSpaceRun()
+	tok.pos += len(tok.variableName())
+	err := tok.emit(types.Name)
+	if err != nil {
+		return tok.error("cannot emit token; %s", err)
+	}
+
+	// what is next?
+	tok.ignoreSpaceRun()
+	if tok.conversion() != "" || tok.finalConversion() != "" {
+		return expectIn
+	}
+	return expectSymbol
+}
+
+// the state function where a conversion keyword like 'in' or a symbol like '->' is expected
 func expectIn(tok *tokenizer) stateFn {
 	tok.ignoreSpaceRun()
-	if tok.accept("iI") && tok.accept("nN") && tok.accept(" ") {
+	conversion := tok.conversion()
+	if conversion == "" {
+		conversion = tok.finalConversion()
+	}
+	if conversion != "" {
+		tok.pos += len(conversion)
 		err := tok.emit(types.In)
 		if err != nil {
 			return tok.error("cannot emit token; %s", err)
 		}
+		tok.ignoreSpaceRun()
+		if tok.peek() == eofRune {
+			// the units are missing; like '2 kg in'
+			return expectEOF
+		}
 		return expectUnits
 	}
 	// error
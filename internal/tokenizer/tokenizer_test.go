@@ -2,6 +2,7 @@ package tokenizer
 
 import (
 	"github.com/nickwallen/quick-calc/internal/io"
+	"github.com/nickwallen/quick-calc/internal/locale"
 	"github.com/nickwallen/quick-calc/internal/types"
 	"testing"
 
@@ -46,6 +47,28 @@ var testCases = map[string][]types.Token{
 		types.Number.TokenAt("0xAF", 1),
 		types.EOF.TokenAt("", 8),
 	},
+	"0xaf_01": {
+		types.Number.TokenAt("0xaf_01", 1),
+		types.EOF.TokenAt("", 8),
+	},
+	"0o17": {
+		types.Number.TokenAt("0o17", 1),
+		types.EOF.TokenAt("", 5),
+	},
+	"0b1010 bytes": {
+		types.Number.TokenAt("0b1010", 1),
+		types.Units.TokenAt("bytes", 8),
+		types.EOF.TokenAt("", 13),
+	},
+	"0bytes": {
+		types.Number.TokenAt("0", 1),
+		types.Units.TokenAt("bytes", 2),
+		types.EOF.TokenAt("", 7),
+	},
+	"1_000": {
+		types.Number.TokenAt("1_000", 1),
+		types.EOF.TokenAt("", 6),
+	},
 	"0xG2": {
 		types.Error.TokenAt("expected number, but got '0xG'", 1),
 	},
@@ -119,6 +142,20 @@ var testCases = map[string][]types.Token{
 		types.Number.TokenAt("+2", 3),
 		types.EOF.TokenAt("", 5),
 	},
+	"2 kg in": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("kg", 3),
+		types.In.TokenAt("in", 6),
+		types.EOF.TokenAt("", 8),
+	},
+	"2 in": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("in", 3),
+		types.EOF.TokenAt("", 5),
+	},
+	"- (2 kg)": {
+		types.Error.TokenAt("'-' cannot negate a group; multiply it by -1 instead", 1),
+	},
 	"2 --- 2": {
 		types.Number.TokenAt("2", 1),
 		types.Minus.TokenAt("-", 3),
@@ -146,6 +183,35 @@ var testCases = map[string][]types.Token{
 		types.Divide.TokenAt("/", 2),
 		types.Error.TokenAt("expected number, but got '/'", 3),
 	},
+	"255 in hex": {
+		types.Number.TokenAt("255", 1),
+		types.In.TokenAt("in", 5),
+		types.Units.TokenAt("hex", 8),
+		types.EOF.TokenAt("", 11),
+	},
+	"255 in hexes": {
+		types.Number.TokenAt("255", 1),
+		types.Units.TokenAt("in", 5),
+		types.Units.TokenAt("hexes", 8),
+		types.EOF.TokenAt("", 13),
+	},
+	"2 kg * 3 in lb": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("kg", 3),
+		types.Multiply.TokenAt("*", 6),
+		types.Number.TokenAt("3", 8),
+		types.In.TokenAt("in", 10),
+		types.Units.TokenAt("lb", 13),
+		types.EOF.TokenAt("", 15),
+	},
+	"2 kg / 3 in": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("kg", 3),
+		types.Divide.TokenAt("/", 6),
+		types.Number.TokenAt("3", 8),
+		types.Units.TokenAt("in", 10),
+		types.EOF.TokenAt("", 12),
+	},
 	"245 lbs": {
 		types.Number.TokenAt("245", 1),
 		types.Units.TokenAt("lbs", 5),
@@ -191,6 +257,93 @@ var testCases = map[string][]types.Token{
 		types.Units.TokenAt("kg", 15),
 		types.EOF.TokenAt("", 20),
 	},
+	"20 lbs to kg": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("lbs", 4),
+		types.In.TokenAt("to", 8),
+		types.Units.TokenAt("kg", 11),
+		types.EOF.TokenAt("", 13),
+	},
+	"20 lbs AS kg": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("lbs", 4),
+		types.In.TokenAt("AS", 8),
+		types.Units.TokenAt("kg", 11),
+		types.EOF.TokenAt("", 13),
+	},
+	"20 lbs in\tkg": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("lbs", 4),
+		types.In.TokenAt("in", 8),
+		types.Units.TokenAt("kg", 11),
+		types.EOF.TokenAt("", 13),
+	},
+	"20lbs->kg": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("lbs", 3),
+		types.In.TokenAt("->", 6),
+		types.Units.TokenAt("kg", 8),
+		types.EOF.TokenAt("", 10),
+	},
+	"20 lbs => kg": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("lbs", 4),
+		types.In.TokenAt("=>", 8),
+		types.Units.TokenAt("kg", 11),
+		types.EOF.TokenAt("", 13),
+	},
+	"20 as": {
+		types.Number.TokenAt("20", 1),
+		types.Units.TokenAt("as", 4),
+		types.EOF.TokenAt("", 6),
+	},
+	"3 fluid ounces in ml": {
+		types.Number.TokenAt("3", 1),
+		types.Units.TokenAt("fluid ounces", 3),
+		types.In.TokenAt("in", 16),
+		types.Units.TokenAt("ml", 19),
+		types.EOF.TokenAt("", 21),
+	},
+	"3 fl oz": {
+		types.Number.TokenAt("3", 1),
+		types.Units.TokenAt("fl oz", 3),
+		types.EOF.TokenAt("", 8),
+	},
+	"2 Nautical Miles in km": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("Nautical Miles", 3),
+		types.In.TokenAt("in", 18),
+		types.Units.TokenAt("km", 21),
+		types.EOF.TokenAt("", 23),
+	},
+	"100 degrees celsius": {
+		types.Number.TokenAt("100", 1),
+		types.Units.TokenAt("degrees celsius", 5),
+		types.EOF.TokenAt("", 20),
+	},
+	"100°F": {
+		types.Number.TokenAt("100", 1),
+		types.Units.TokenAt("°F", 4),
+		types.EOF.TokenAt("", 7),
+	},
+	"30 m/s": {
+		types.Number.TokenAt("30", 1),
+		types.Units.TokenAt("m/s", 4),
+		types.EOF.TokenAt("", 7),
+	},
+	"5 µm": {
+		types.Number.TokenAt("5", 1),
+		types.Units.TokenAt("µm", 3),
+		types.EOF.TokenAt("", 6),
+	},
+	"6' + 3\"": {
+		types.Number.TokenAt("6", 1),
+		types.Units.TokenAt("'", 2),
+		types.Plus.TokenAt("+", 4),
+		types.Number.TokenAt("3", 6),
+		types.Units.TokenAt("\"", 7),
+		types.EOF.TokenAt("", 8),
+	},
 	"20 ints": {
 		types.Number.TokenAt("20", 1),
 		types.Units.TokenAt("ints", 4),
@@ -242,6 +395,56 @@ var testCases = map[string][]types.Token{
 		types.Units.TokenAt("in", 4),
 		types.EOF.TokenAt("", 6),
 	},
+	"3..5 kg": {
+		types.Number.TokenAt("3", 1),
+		types.Range.TokenAt("..", 2),
+		types.Number.TokenAt("5", 4),
+		types.Units.TokenAt("kg", 6),
+		types.EOF.TokenAt("", 8),
+	},
+	"2.5 .. 3.5 kg": {
+		types.Number.TokenAt("2.5", 1),
+		types.Range.TokenAt("..", 5),
+		types.Number.TokenAt("3.5", 8),
+		types.Units.TokenAt("kg", 12),
+		types.EOF.TokenAt("", 14),
+	},
+	"3...5 kg": {
+		types.Number.TokenAt("3", 1),
+		types.Range.TokenAt("..", 2),
+		types.Error.TokenAt("expected number, but got '.'", 4),
+	},
+	"(10..12 m) * (3..4 m) in ft^2": {
+		types.LeftParen.TokenAt("(", 1),
+		types.Number.TokenAt("10", 2),
+		types.Range.TokenAt("..", 4),
+		types.Number.TokenAt("12", 6),
+		types.Units.TokenAt("m", 9),
+		types.RightParen.TokenAt(")", 10),
+		types.Multiply.TokenAt("*", 12),
+		types.LeftParen.TokenAt("(", 14),
+		types.Number.TokenAt("3", 15),
+		types.Range.TokenAt("..", 16),
+		types.Number.TokenAt("4", 18),
+		types.Units.TokenAt("m", 20),
+		types.RightParen.TokenAt(")", 21),
+		types.In.TokenAt("in", 23),
+		types.Units.TokenAt("ft^2", 26),
+		types.EOF.TokenAt("", 30),
+	},
+	"1 m + (2 ft + 3 in)": {
+		types.Number.TokenAt("1", 1),
+		types.Units.TokenAt("m", 3),
+		types.Plus.TokenAt("+", 5),
+		types.LeftParen.TokenAt("(", 7),
+		types.Number.TokenAt("2", 8),
+		types.Units.TokenAt("ft", 10),
+		types.Plus.TokenAt("+", 13),
+		types.Number.TokenAt("3", 15),
+		types.Units.TokenAt("in", 17),
+		types.RightParen.TokenAt(")", 19),
+		types.EOF.TokenAt("", 20),
+	},
 	"in pounds 22": {
 		types.Units.TokenAt("in", 1), // the tokenizer assumes this is the unit 'inches', not the keyword 'in'
 		types.Units.TokenAt("pounds", 4),
@@ -254,7 +457,106 @@ func TestTokens(t *testing.T) {
 	for input, expected := range testCases {
 		t.Run(input, func(t *testing.T) {
 			output := io.NewTokenChannel(input)
-			go Tokenize(input, &output)
+			go Tokenize(input, output)
+			for _, expect := range expected {
+				actual, err := output.ReadToken()
+				assert.Nil(t, err)
+				assert.Equal(t, expect, actual, "'%s'", input)
+			}
+		})
+	}
+}
+
+var germanTestCases = map[string][]types.Token{
+	"1.000,5 kg": {
+		types.Number.TokenAt("1.000,5", 1),
+		types.Units.TokenAt("kg", 9),
+		types.EOF.TokenAt("", 11),
+	},
+	"2,5..3,5 kg": {
+		types.Number.TokenAt("2,5", 1),
+		types.Range.TokenAt("..", 4),
+		types.Number.TokenAt("3,5", 6),
+		types.Units.TokenAt("kg", 10),
+		types.EOF.TokenAt("", 12),
+	},
+	".200": {
+		types.Error.TokenAt("Zahl erwartet, aber '.2' gefunden", 1),
+	},
+	"2 Kilogramm nach Pfund": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("Kilogramm", 3),
+		types.In.TokenAt("nach", 13),
+		types.Units.TokenAt("Pfund", 18),
+		types.EOF.TokenAt("", 23),
+	},
+	"2 Fuß in Zoll": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("Fuß", 3),
+		types.In.TokenAt("in", 8),
+		types.Units.TokenAt("Zoll", 11),
+		types.EOF.TokenAt("", 15),
+	},
+}
+
+func TestTokensWithLocale(t *testing.T) {
+	for input, expected := range germanTestCases {
+		t.Run(input, func(t *testing.T) {
+			output := io.NewTokenChannel(input)
+			go Tokenize(input, output, WithLocale(locale.German))
+			for _, expect := range expected {
+				actual, err := output.ReadToken()
+				assert.Nil(t, err)
+				assert.Equal(t, expect, actual, "'%s'", input)
+			}
+		})
+	}
+}
+
+var variableTestCases = map[string][]types.Token{
+	"rent = 1200 kg": {
+		types.Name.TokenAt("rent", 1),
+		types.Assign.TokenAt("=", 6),
+		types.Number.TokenAt("1200", 8),
+		types.Units.TokenAt("kg", 13),
+		types.EOF.TokenAt("", 15),
+	},
+	"per person=rent - 2 kg": {
+		types.Name.TokenAt("per person", 1),
+		types.Assign.TokenAt("=", 11),
+		types.Name.TokenAt("rent", 12),
+		types.Minus.TokenAt("-", 17),
+		types.Number.TokenAt("2", 19),
+		types.Units.TokenAt("kg", 21),
+		types.EOF.TokenAt("", 23),
+	},
+	"2 kg + rent in g": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("kg", 3),
+		types.Plus.TokenAt("+", 6),
+		types.Name.TokenAt("rent", 8),
+		types.In.TokenAt("in", 13),
+		types.Units.TokenAt("g", 16),
+		types.EOF.TokenAt("", 17),
+	},
+	"rental": {
+		types.Units.TokenAt("rental", 1),
+		types.EOF.TokenAt("", 7),
+	},
+	"2 kg => g": {
+		types.Number.TokenAt("2", 1),
+		types.Units.TokenAt("kg", 3),
+		types.In.TokenAt("=>", 6),
+		types.Units.TokenAt("g", 9),
+		types.EOF.TokenAt("", 10),
+	},
+}
+
+func TestTokensWithVariables(t *testing.T) {
+	for input, expected := range variableTestCases {
+		t.Run(input, func(t *testing.T) {
+			output := io.NewTokenChannel(input)
+			go Tokenize(input, output, WithVariables([]string{"rent", "per person"}))
 			for _, expect := range expected {
 				actual, err := output.ReadToken()
 				assert.Nil(t, err)
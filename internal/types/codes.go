@@ -0,0 +1,125 @@
+package types
+
+import (
+	"fmt"
+)
+
+// Code A stable code that identifies a kind of error, like 'QC1001', so that errors can be told apart without their messages.
+type Code string
+
+const (
+	// CodeUnknownUnit Units that are not known; like 'punds'.
+	CodeUnknownUnit Code = "QC1001"
+	// CodeAmbiguousUnit Units that could be more than one unit; like 'gal'.
+	CodeAmbiguousUnit Code = "QC1002"
+	// CodeIncompatibleUnits Units that cannot be converted to one another; like meters to pounds.
+	CodeIncompatibleUnits Code = "QC1003"
+	// CodeInvalidRadix An amount that cannot be displayed in a radix; like kg in hex.
+	CodeInvalidRadix Code = "QC1004"
+	// CodeIncompatibleFactors Amounts that cannot be multiplied, or divided, by one another; like kg by kg.
+	CodeIncompatibleFactors Code = "QC1005"
+	// CodeUnexpectedToken A token that is not expected; like the second 'kg' in '2 kg kg'.
+	CodeUnexpectedToken Code = "QC2001"
+	// CodeUnexpectedEnd An input that ends too soon; like '2 kg +'.
+	CodeUnexpectedEnd Code = "QC2002"
+	// CodeInvalidToken Input that cannot be tokenized; like '?'.
+	CodeInvalidToken Code = "QC2003"
+	// CodeInvalidOperator An operator that is not supported.
+	CodeInvalidOperator Code = "QC2004"
+	// CodeReservedName A variable named like units, a keyword or a radix; like 'kg' in 'kg = 5 m'.
+	CodeReservedName Code = "QC2005"
+	// CodeInvalidNumber A number that is not valid.
+	CodeInvalidNumber Code = "QC3001"
+	// CodeAmbiguousNumber A number whose digit grouping is ambiguous; like '1,00'.
+	CodeAmbiguousNumber Code = "QC3002"
+	// CodeTooManyDigits A number with more significant digits than the limit.
+	CodeTooManyDigits Code = "QC3003"
+	// CodeDivisionByZero An amount that is divided by zero; like '2 m / (1 m - 1 m)'.
+	CodeDivisionByZero Code = "QC3004"
+	// CodeInputTooLong An input that is longer than the limit.
+	CodeInputTooLong Code = "QC4001"
+	// CodeTooManyTokens An input with more tokens than the limit.
+	CodeTooManyTokens Code = "QC4002"
+	// CodeTooDeep An expression that is nested deeper than the limit.
+	CodeTooDeep Code = "QC4003"
+	// CodeCancelled A calculation that was cancelled.
+	CodeCancelled Code = "QC5001"
+	// CodeReadFailed Tokens that could not be read.
+	CodeReadFailed Code = "QC5002"
+)
+
+// the names of each code
+var codeNames = map[Code]string{
+	CodeUnknownUnit:         "unknown-unit",
+	CodeAmbiguousUnit:       "ambiguous-unit",
+	CodeIncompatibleUnits:   "incompatible-units",
+	CodeInvalidRadix:        "invalid-radix",
+	CodeIncompatibleFactors: "incompatible-factors",
+	CodeUnexpectedToken:     "unexpected-token",
+	CodeUnexpectedEnd:       "unexpected-end",
+	CodeInvalidToken:        "invalid-token",
+	CodeInvalidOperator:     "invalid-operator",
+	CodeReservedName:        "reserved-name",
+	CodeInvalidNumber:       "invalid-number",
+	CodeAmbiguousNumber:     "ambiguous-number",
+	CodeTooManyDigits:       "too-many-digits",
+	CodeDivisionByZero:      "division-by-zero",
+	CodeInputTooLong:        "input-too-long",
+	CodeTooManyTokens:       "too-many-tokens",
+	CodeTooDeep:             "too-deep",
+	CodeCancelled:           "cancelled",
+	CodeReadFailed:          "read-failed",
+}
+
+// Name returns a short, readable name for the code; like 'unknown-unit'.
+func (c Code) Name() string {
+	return codeNames[c]
+}
+
+// Severity returns the severity of the errors with this code.
+func (c Code) Severity() Severity {
+	return SeverityError
+}
+
+func (c Code) String() string {
+	return fmt.Sprintf("%s %s", string(c), c.Name())
+}
+
+// Severity How severe an error is.
+type Severity int
+
+const (
+	// SeverityError A problem that stops the calculation.
+	SeverityError Severity = iota
+	// SeverityWarning A problem that does not stop the calculation, but may give an unexpected result.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the severity as text; like 'error'.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the severity from text; like 'error'.
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "error":
+		*s = SeverityError
+	case "warning":
+		*s = SeverityWarning
+	default:
+		return fmt.Errorf("unknown severity '%s'", text)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCodeNames(t *testing.T) {
+	names := map[string]bool{}
+	for code, name := range codeNames {
+		assert.Regexp(t, `^QC\d{4}$`, string(code))
+		assert.False(t, names[name], "duplicate name '%s'", name)
+		names[name] = true
+	}
+	assert.Equal(t, "QC1001 unknown-unit", CodeUnknownUnit.String())
+	assert.Equal(t, SeverityError, CodeUnknownUnit.Severity())
+}
+
+func TestErrorCodes(t *testing.T) {
+	errs := map[Code]InputError{
+		CodeUnexpectedToken:     ErrorUnexpectedToken("2 kg kg", Units.TokenAt("kg", 6), Plus),
+		CodeUnexpectedEnd:       ErrorUnexpectedEOF("2", EOF.TokenAt("", 2), Units),
+		CodeInvalidToken:        ErrorTokenizerError("?", Error.TokenAt("bad", 1)),
+		CodeUnknownUnit:         ErrorInvalidUnits("2 x", Units.TokenAt("x", 3)),
+		CodeInvalidNumber:       ErrorInvalidNumber("x kg", Number.TokenAt("x", 1)),
+		CodeInputTooLong:        ErrorInputTooLong("2 kg", 1),
+		CodeDivisionByZero:      ErrorDivisionByZero("2 kg / 0", "2 kg", Number.TokenAt("0", 8)),
+		CodeIncompatibleFactors: ErrorIncompatibleFactors("2 kg * 3 kg", Multiply.TokenAt("*", 6), "kg", Units.TokenAt("kg", 10)),
+		CodeReservedName:        ErrorReservedName("kg = 5 m", Name.TokenAt("kg", 1)),
+		CodeIncompatibleUnits:   ErrorUnitlessOperand("x + 2 kg", Name.TokenAt("x", 1), Units.TokenAt("kg", 7)),
+		CodeCancelled:           ErrorCancelled("2 kg", nil),
+	}
+	for code, err := range errs {
+		assert.Equal(t, code, err.Code())
+		assert.NotEmpty(t, code.Name())
+	}
+	assert.Equal(t, []TokenType{Plus}, errs[CodeUnexpectedToken].Expected())
+	assert.Nil(t, errs[CodeInvalidNumber].Expected())
+	assert.Nil(t, errs[CodeInvalidNumber].Suggestions())
+}
+
+func TestSeverityText(t *testing.T) {
+	for _, severity := range []Severity{SeverityError, SeverityWarning} {
+		text, err := severity.MarshalText()
+		assert.Nil(t, err)
+		var decoded Severity
+		assert.Nil(t, decoded.UnmarshalText(text))
+		assert.Equal(t, severity, decoded)
+	}
+	var decoded Severity
+	assert.NotNil(t, decoded.UnmarshalText([]byte("fatal")))
+}
+
+func TestTokenTypeName(t *testing.T) {
+	assert.Equal(t, "eof", EOF.Name())
+	assert.Equal(t, "units", Units.Name())
+	assert.Equal(t, "assign", Assign.Name())
+	assert.Equal(t, "unknown", TokenType(99).Name())
+}
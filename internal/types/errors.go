@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	u "github.com/bcicen/go-units"
+	"github.com/nickwallen/quick-calc/internal/locale"
 	"strings"
 )
 
@@ -13,6 +15,14 @@ type InputError interface {
 	Input() string
 	// Position returns the position of the error.
 	Position() (start, width int)
+	// Translate returns the error message in the language of a locale.
+	Translate(loc locale.Locale) string
+	// Code returns a stable code for the kind of error; like 'QC1001' for units that are not known.
+	Code() Code
+	// Expected returns the types of token that were expected, if any.
+	Expected() []TokenType
+	// Suggestions returns alternatives to what was found in the input, if any; like similar units.
+	Suggestions() []string
 }
 
 // ErrorUnexpectedToken creates a new unexpected token error.
@@ -51,13 +61,14 @@ func ErrorTokenizerError(input string, errorToken Token) *TokenizerError {
 	}
 }
 
-// ErrorInvalidUnits Creates a new invalid units error.
-func ErrorInvalidUnits(input string, badToken Token) *InvalidUnits {
+// ErrorInvalidUnits Creates a new invalid units error with optional suggestions of similar units.
+func ErrorInvalidUnits(input string, badToken Token, suggestions ...string) *InvalidUnits {
 	return &InvalidUnits{
 		invalidName: badToken.Value,
 		position:    badToken.Position,
 		width:       len(badToken.Value),
 		input:       input,
+		suggestions: suggestions,
 	}
 }
 
@@ -81,6 +92,112 @@ func ErrorInvalidNumber(input string, invalid Token) *InvalidNumber {
 	}
 }
 
+// ErrorAmbiguousNumber Creates an error for a number whose digit grouping is ambiguous.
+func ErrorAmbiguousNumber(input string, invalid Token, decimal rune, group rune) *AmbiguousNumber {
+	return &AmbiguousNumber{
+		invalid:  invalid,
+		decimal:  decimal,
+		group:    group,
+		input:    input,
+		position: invalid.Position,
+		width:    len(invalid.Value),
+	}
+}
+
+// ErrorAmbiguousUnits Creates an error for units that could refer to more than one unit; like 'gal'.
+func ErrorAmbiguousUnits(input string, ambiguous Token, candidates []u.Unit) *AmbiguousUnits {
+	return &AmbiguousUnits{
+		ambiguous:  ambiguous,
+		candidates: candidates,
+		input:      input,
+		position:   ambiguous.Position,
+		width:      len(ambiguous.Value),
+	}
+}
+
+// ErrorInputTooLong Creates an error for an input that is longer than the limit.
+func ErrorInputTooLong(input string, limit int) *InputTooLong {
+	// the error spans the input beyond the limit
+	position := len(input) + 1
+	if runes := []rune(input); len(runes) > limit {
+		position = len(string(runes[:limit])) + 1
+	}
+	return &InputTooLong{
+		limit:    limit,
+		input:    input,
+		position: position,
+		width:    len(input) + 1 - position,
+	}
+}
+
+// ErrorTooManyTokens Creates an error for an input with more tokens than the limit.
+func ErrorTooManyTokens(input string, token Token, limit int) *TooManyTokens {
+	return &TooManyTokens{
+		limit:    limit,
+		input:    input,
+		position: token.Position,
+		width:    len(token.Value),
+	}
+}
+
+// ErrorTooDeep Creates an error for an expression that is nested deeper than the limit.
+func ErrorTooDeep(input string, token Token, limit int) *TooDeep {
+	return &TooDeep{
+		limit:    limit,
+		input:    input,
+		position: token.Position,
+		width:    len(token.Value),
+	}
+}
+
+// ErrorTooManyDigits Creates an error for a number with more significant digits than the limit.
+func ErrorTooManyDigits(input string, number Token, limit int) *TooManyDigits {
+	return &TooManyDigits{
+		number:   number,
+		limit:    limit,
+		input:    input,
+		position: number.Position,
+		width:    len(number.Value),
+	}
+}
+
+// ErrorDivisionByZero Creates an error for an amount that is divided by zero.
+func ErrorDivisionByZero(input string, amount string, zero Token) *DivisionByZero {
+	return &DivisionByZero{
+		amount:   amount,
+		input:    input,
+		position: zero.Position,
+		width:    len(zero.Value),
+	}
+}
+
+// ErrorUnitlessOperand Creates an error for an amount with no units that is added to, subtracted from, or
+// converted to, an amount with units; like 'ratio' in 'ratio + 3 kg'.
+func ErrorUnitlessOperand(input string, operand Token, units Token) *UnitlessOperand {
+	return &UnitlessOperand{
+		operand:  operand.Value,
+		units:    units.Value,
+		input:    input,
+		position: operand.Position,
+		width:    len(operand.Value),
+	}
+}
+
+// ErrorReservedName Creates an error for a variable named like units, a keyword or a radix; like 'kg' in 'kg = 5 m'.
+func ErrorReservedName(input string, name Token) *ReservedName {
+	return &ReservedName{
+		name:     name.Value,
+		input:    input,
+		position: name.Position,
+		width:    len(name.Value),
+	}
+}
+
+// ErrorCancelled Creates an error for a calculation that was cancelled; like when a deadline is exceeded.
+func ErrorCancelled(input string, cause error) *Cancelled {
+	return &Cancelled{cause, input}
+}
+
 // ErrorInvalidUnitConversion Creates an invalid unit conversion error.
 func ErrorInvalidUnitConversion(input string, from Token, to Token) *InvalidUnitConversion {
 	return &InvalidUnitConversion{
@@ -92,6 +209,30 @@ func ErrorInvalidUnitConversion(input string, from Token, to Token) *InvalidUnit
 	}
 }
 
+// ErrorInvalidRadixConversion Creates an error for an amount that cannot be displayed in a radix like hex.
+func ErrorInvalidRadixConversion(input string, amount string, radix Token) *InvalidRadixConversion {
+	return &InvalidRadixConversion{
+		amount:   amount,
+		radix:    radix.Value,
+		position: radix.Position,
+		width:    len(radix.Value),
+		input:    input,
+	}
+}
+
+// ErrorIncompatibleFactors Creates an error for amounts that cannot be multiplied, or divided, by one another;
+// the error is placed at the units of the right operand.
+func ErrorIncompatibleFactors(input string, operator Token, left string, right Token) *IncompatibleFactors {
+	return &IncompatibleFactors{
+		divide:   operator.TokenType == Divide,
+		left:     left,
+		right:    right.Value,
+		input:    input,
+		position: right.Position,
+		width:    len(right.Value),
+	}
+}
+
 // InvalidUnitConversion is an error that occurs when a unit conversion is invalid; like meters to pounds.
 type InvalidUnitConversion struct {
 	from     string
@@ -103,7 +244,12 @@ type InvalidUnitConversion struct {
 
 // Error returns an error message.
 func (e *InvalidUnitConversion) Error() string {
-	return fmt.Sprintf("cannot convert from %s to %s", e.from, e.to)
+	return e.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (e *InvalidUnitConversion) Translate(loc locale.Locale) string {
+	return loc.Sprintf("cannot convert from %s to %s", e.from, e.to)
 }
 
 // Input returns the input string.
@@ -116,6 +262,65 @@ func (e *InvalidUnitConversion) Position() (start, width int) {
 	return e.position, e.width
 }
 
+// Code returns a stable code for the kind of error.
+func (e *InvalidUnitConversion) Code() Code {
+	return CodeIncompatibleUnits
+}
+
+// Expected returns nothing; no particular token was expected.
+func (e *InvalidUnitConversion) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (e *InvalidUnitConversion) Suggestions() []string {
+	return nil
+}
+
+// InvalidRadixConversion is an error that occurs when an amount cannot be displayed in a radix; like 2.5 kg in hex.
+type InvalidRadixConversion struct {
+	amount   string
+	radix    string
+	position int
+	width    int
+	input    string
+}
+
+// Error returns an error message.
+func (e *InvalidRadixConversion) Error() string {
+	return e.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (e *InvalidRadixConversion) Translate(loc locale.Locale) string {
+	return loc.Sprintf("cannot display %s in %s; only whole numbers of bytes or bits", e.amount, e.radix)
+}
+
+// Input returns the input string.
+func (e *InvalidRadixConversion) Input() string {
+	return e.input
+}
+
+// Position returns the position of the error.
+func (e *InvalidRadixConversion) Position() (start, width int) {
+	return e.position, e.width
+}
+
+// Code returns a stable code for the kind of error.
+func (e *InvalidRadixConversion) Code() Code {
+	return CodeInvalidRadix
+}
+
+// Expected returns nothing; no particular token was expected.
+func (e *InvalidRadixConversion) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (e *InvalidRadixConversion) Suggestions() []string {
+	return nil
+}
+
 // UnexpectedToken is an error indicated that an unexpected token found.
 type UnexpectedToken struct {
 	expected []TokenType // the token(s) that were expected
@@ -135,12 +340,37 @@ func (u *UnexpectedToken) Position() (start, width int) {
 	return u.position, u.width
 }
 
+// Code returns a stable code for the kind of error.
+func (u *UnexpectedToken) Code() Code {
+	return CodeUnexpectedToken
+}
+
+// Expected returns the types of token that were expected.
+func (u *UnexpectedToken) Expected() []TokenType {
+	return u.expected
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (u *UnexpectedToken) Suggestions() []string {
+	return nil
+}
+
 func (u *UnexpectedToken) Error() string {
+	return u.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (u *UnexpectedToken) Translate(loc locale.Locale) string {
+	return loc.Sprintf("got '%s', but expected %s", u.badToken.Value, expectedTokens(loc, u.expected))
+}
+
+// expectedTokens returns a list of the expected tokens in the language of a locale.
+func expectedTokens(loc locale.Locale, tokenTypes []TokenType) string {
 	var expected []string
-	for _, e := range u.expected {
-		expected = append(expected, fmt.Sprintf("%s", e))
+	for _, e := range tokenTypes {
+		expected = append(expected, loc.Translate(e.String()))
 	}
-	return fmt.Sprintf("got '%s', but expected %s", u.badToken.Value, strings.Join(expected, ", "))
+	return strings.Join(expected, ", ")
 }
 
 // UnexpectedEOF is an error indicating that a premature EOF was encountered.
@@ -152,11 +382,12 @@ type UnexpectedEOF struct {
 }
 
 func (u *UnexpectedEOF) Error() string {
-	var expected []string
-	for _, e := range u.expected {
-		expected = append(expected, fmt.Sprintf("%s", e))
-	}
-	return fmt.Sprintf("reached end of input, but expected %s", strings.Join(expected, ", "))
+	return u.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (u *UnexpectedEOF) Translate(loc locale.Locale) string {
+	return loc.Sprintf("reached end of input, but expected %s", expectedTokens(loc, u.expected))
 }
 
 // Input returns the input string.
@@ -169,6 +400,21 @@ func (u *UnexpectedEOF) Position() (start, width int) {
 	return u.position, 1
 }
 
+// Code returns a stable code for the kind of error.
+func (u *UnexpectedEOF) Code() Code {
+	return CodeUnexpectedEnd
+}
+
+// Expected returns the types of token that were expected.
+func (u *UnexpectedEOF) Expected() []TokenType {
+	return u.expected
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (u *UnexpectedEOF) Suggestions() []string {
+	return nil
+}
+
 // ReadFailed is an error that occurs when tokens cannot be read.
 type ReadFailed struct {
 	cause error  // the cause of the read failure
@@ -176,6 +422,11 @@ type ReadFailed struct {
 }
 
 func (r *ReadFailed) Error() string {
+	return r.Translate(locale.Default)
+}
+
+// Translate returns the error message; the cause of a read failure is not translated.
+func (r *ReadFailed) Translate(loc locale.Locale) string {
 	return fmt.Sprintf("%s", r.cause)
 }
 
@@ -189,6 +440,21 @@ func (r *ReadFailed) Position() (start, width int) {
 	return 1, 1
 }
 
+// Code returns a stable code for the kind of error.
+func (r *ReadFailed) Code() Code {
+	return CodeReadFailed
+}
+
+// Expected returns nothing; no particular token was expected.
+func (r *ReadFailed) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (r *ReadFailed) Suggestions() []string {
+	return nil
+}
+
 // TokenizerError is an error encountered during tokenization.
 type TokenizerError struct {
 	errorToken Token  // the error token from the tokenizer
@@ -198,6 +464,11 @@ type TokenizerError struct {
 }
 
 func (t *TokenizerError) Error() string {
+	return t.Translate(locale.Default)
+}
+
+// Translate returns the error message; the tokenizer has already written it in the language of its locale.
+func (t *TokenizerError) Translate(loc locale.Locale) string {
 	return fmt.Sprintf("%s", t.errorToken.Value)
 }
 
@@ -211,16 +482,42 @@ func (t *TokenizerError) Position() (start, width int) {
 	return t.position, t.width
 }
 
+// Code returns a stable code for the kind of error.
+func (t *TokenizerError) Code() Code {
+	return CodeInvalidToken
+}
+
+// Expected returns nothing; no particular token was expected.
+func (t *TokenizerError) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (t *TokenizerError) Suggestions() []string {
+	return nil
+}
+
 // InvalidUnits is an error indicating an invalid unit of measure was encountered.
 type InvalidUnits struct {
-	invalidName string // the name that is not a valid unit of measurement
-	position    int    // the position of the invalid unit invalidName
-	width       int    // the width of the invalid unit invalidName
-	input       string // the input string
+	invalidName string   // the name that is not a valid unit of measurement
+	position    int      // the position of the invalid unit invalidName
+	width       int      // the width of the invalid unit invalidName
+	input       string   // the input string
+	suggestions []string // the names of similar units, most similar first
 }
 
 func (u *InvalidUnits) Error() string {
-	return fmt.Sprintf("'%s' is not a known measurement unit", u.invalidName)
+	return u.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (u *InvalidUnits) Translate(loc locale.Locale) string {
+	return loc.Sprintf("'%s' is not a known measurement unit", u.invalidName)
+}
+
+// Suggestions returns the names of known units that are similar to the invalid units, most similar first.
+func (u *InvalidUnits) Suggestions() []string {
+	return u.suggestions
 }
 
 // Input returns the input string.
@@ -233,6 +530,16 @@ func (u *InvalidUnits) Position() (start, width int) {
 	return u.position, u.width
 }
 
+// Code returns a stable code for the kind of error.
+func (u *InvalidUnits) Code() Code {
+	return CodeUnknownUnit
+}
+
+// Expected returns nothing; no particular token was expected.
+func (u *InvalidUnits) Expected() []TokenType {
+	return nil
+}
+
 // InvalidOperator is an error indicating an invalid operator was encountered.
 type InvalidOperator struct {
 	invalid  Token  // the operator that is not valid
@@ -242,7 +549,12 @@ type InvalidOperator struct {
 }
 
 func (i *InvalidOperator) Error() string {
-	return fmt.Sprintf("found invalid operator %s", i.invalid)
+	return i.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (i *InvalidOperator) Translate(loc locale.Locale) string {
+	return loc.Sprintf("found invalid operator %s", i.invalid)
 }
 
 // Input returns the input string.
@@ -255,6 +567,202 @@ func (i *InvalidOperator) Position() (start, width int) {
 	return i.position, i.width
 }
 
+// Code returns a stable code for the kind of error.
+func (i *InvalidOperator) Code() Code {
+	return CodeInvalidOperator
+}
+
+// Expected returns nothing; no particular token was expected.
+func (i *InvalidOperator) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (i *InvalidOperator) Suggestions() []string {
+	return nil
+}
+
+// DivisionByZero is an error that occurs when an amount is divided by zero.
+type DivisionByZero struct {
+	amount   string // the amount that is divided
+	input    string // the input string
+	position int    // the position of the zero
+	width    int    // the width of the zero
+}
+
+func (d *DivisionByZero) Error() string {
+	return d.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (d *DivisionByZero) Translate(loc locale.Locale) string {
+	return loc.Sprintf("cannot divide %s by zero", d.amount)
+}
+
+// Input returns the input string.
+func (d *DivisionByZero) Input() string {
+	return d.input
+}
+
+// Position returns the position of the error.
+func (d *DivisionByZero) Position() (start, width int) {
+	return d.position, d.width
+}
+
+// Code returns a stable code for the kind of error.
+func (d *DivisionByZero) Code() Code {
+	return CodeDivisionByZero
+}
+
+// Expected returns nothing; no particular token was expected.
+func (d *DivisionByZero) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (d *DivisionByZero) Suggestions() []string {
+	return nil
+}
+
+// IncompatibleFactors is an error that occurs when amounts cannot be multiplied, or divided, by one another;
+// like kg by kg.
+type IncompatibleFactors struct {
+	divide   bool   // true if the amounts are divided rather than multiplied
+	left     string // the units of the left operand
+	right    string // the units of the right operand
+	input    string // the input string
+	position int    // the position of the units of the right operand
+	width    int    // the width of the units of the right operand
+}
+
+func (i *IncompatibleFactors) Error() string {
+	return i.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (i *IncompatibleFactors) Translate(loc locale.Locale) string {
+	if i.divide {
+		return loc.Sprintf("cannot divide %s by %s", i.left, i.right)
+	}
+	return loc.Sprintf("cannot multiply %s by %s", i.left, i.right)
+}
+
+// Input returns the input string.
+func (i *IncompatibleFactors) Input() string {
+	return i.input
+}
+
+// Position returns the position of the error.
+func (i *IncompatibleFactors) Position() (start, width int) {
+	return i.position, i.width
+}
+
+// Code returns a stable code for the kind of error.
+func (i *IncompatibleFactors) Code() Code {
+	return CodeIncompatibleFactors
+}
+
+// Expected returns nothing; no particular token was expected.
+func (i *IncompatibleFactors) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (i *IncompatibleFactors) Suggestions() []string {
+	return nil
+}
+
+// UnitlessOperand is an error that occurs when an amount with no units, like the ratio of two lengths, is
+// combined with an amount with units; which would otherwise be read as units with no name.
+type UnitlessOperand struct {
+	operand  string // the operand with no units, like 'ratio'; empty if it has no name
+	units    string // the units of the other operand
+	input    string // the input string
+	position int    // the position of the operand
+	width    int    // the width of the operand
+}
+
+// Error returns an error message.
+func (e *UnitlessOperand) Error() string {
+	return e.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (e *UnitlessOperand) Translate(loc locale.Locale) string {
+	if e.operand == "" {
+		return loc.Sprintf("a number with no units cannot be combined with %s", e.units)
+	}
+	return loc.Sprintf("'%s' has no units, so cannot be combined with %s", e.operand, e.units)
+}
+
+// Input returns the input string.
+func (e *UnitlessOperand) Input() string {
+	return e.input
+}
+
+// Position returns the position of the error.
+func (e *UnitlessOperand) Position() (start, width int) {
+	return e.position, e.width
+}
+
+// Code returns a stable code for the kind of error.
+func (e *UnitlessOperand) Code() Code {
+	return CodeIncompatibleUnits
+}
+
+// Expected returns nothing; no particular token was expected.
+func (e *UnitlessOperand) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (e *UnitlessOperand) Suggestions() []string {
+	return nil
+}
+
+// ReservedName is an error that occurs when a variable is named like units, a keyword or a radix; which the
+// variable would otherwise hide.
+type ReservedName struct {
+	name     string // the name of the variable
+	input    string // the input string
+	position int    // the position of the name
+	width    int    // the width of the name
+}
+
+func (r *ReservedName) Error() string {
+	return r.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (r *ReservedName) Translate(loc locale.Locale) string {
+	return loc.Sprintf("'%s' is a unit or keyword, so cannot name a variable", r.name)
+}
+
+// Input returns the input string.
+func (r *ReservedName) Input() string {
+	return r.input
+}
+
+// Position returns the position of the error.
+func (r *ReservedName) Position() (start, width int) {
+	return r.position, r.width
+}
+
+// Code returns a stable code for the kind of error.
+func (r *ReservedName) Code() Code {
+	return CodeReservedName
+}
+
+// Expected returns nothing; no particular token was expected.
+func (r *ReservedName) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (r *ReservedName) Suggestions() []string {
+	return nil
+}
+
 // InvalidNumber is an error indicating an invalid number was encountered.
 type InvalidNumber struct {
 	invalid  Token  // the number that is not valid
@@ -263,16 +771,345 @@ type InvalidNumber struct {
 	width    int    // the width of the error
 }
 
-func (i InvalidNumber) Error() string {
-	return i.Error()
+func (i *InvalidNumber) Error() string {
+	return i.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (i *InvalidNumber) Translate(loc locale.Locale) string {
+	return loc.Sprintf("'%s' is not a valid number", i.invalid.Value)
 }
 
 // Input returns the input string.
-func (i InvalidNumber) Input() string {
+func (i *InvalidNumber) Input() string {
 	return i.input
 }
 
 // Position returns the position of the error.
-func (i InvalidNumber) Position() (start, width int) {
+func (i *InvalidNumber) Position() (start, width int) {
 	return i.position, i.width
 }
+
+// Code returns a stable code for the kind of error.
+func (i *InvalidNumber) Code() Code {
+	return CodeInvalidNumber
+}
+
+// Expected returns nothing; no particular token was expected.
+func (i *InvalidNumber) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (i *InvalidNumber) Suggestions() []string {
+	return nil
+}
+
+// AmbiguousNumber is an error indicating that the digit grouping of a number is ambiguous; like 1,00.
+type AmbiguousNumber struct {
+	invalid  Token  // the number that is ambiguous
+	decimal  rune   // the expected decimal separator
+	group    rune   // the expected digit grouping separator
+	input    string // the input string
+	position int    // the position of the error
+	width    int    // the width of the error
+}
+
+func (a *AmbiguousNumber) Error() string {
+	return a.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (a *AmbiguousNumber) Translate(loc locale.Locale) string {
+	return loc.Sprintf("'%s' is ambiguous; use '%c' to separate decimals and '%c' to group thousands", a.invalid.Value, a.decimal, a.group)
+}
+
+// Input returns the input string.
+func (a *AmbiguousNumber) Input() string {
+	return a.input
+}
+
+// Position returns the position of the error.
+func (a *AmbiguousNumber) Position() (start, width int) {
+	return a.position, a.width
+}
+
+// Code returns a stable code for the kind of error.
+func (a *AmbiguousNumber) Code() Code {
+	return CodeAmbiguousNumber
+}
+
+// Expected returns nothing; no particular token was expected.
+func (a *AmbiguousNumber) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (a *AmbiguousNumber) Suggestions() []string {
+	return nil
+}
+
+// AmbiguousUnits is an error indicating that units could refer to more than one unit; like 'gal' for US and imperial gallons.
+type AmbiguousUnits struct {
+	ambiguous  Token    // the ambiguous units
+	candidates []u.Unit // the units that could be meant
+	input      string   // the input string
+	position   int      // the position of the error
+	width      int      // the width of the error
+}
+
+func (a *AmbiguousUnits) Error() string {
+	return a.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (a *AmbiguousUnits) Translate(loc locale.Locale) string {
+	var candidates []string
+	for _, unit := range a.candidates {
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", unit.Name, unit.System()))
+	}
+	return loc.Sprintf("'%s' is ambiguous; it could be %s", a.ambiguous.Value, strings.Join(candidates, ", "))
+}
+
+// Suggestions returns the names of the units that could be meant.
+func (a *AmbiguousUnits) Suggestions() []string {
+	var names []string
+	for _, unit := range a.candidates {
+		names = append(names, unit.Name)
+	}
+	return names
+}
+
+// Input returns the input string.
+func (a *AmbiguousUnits) Input() string {
+	return a.input
+}
+
+// Position returns the position of the error.
+func (a *AmbiguousUnits) Position() (start, width int) {
+	return a.position, a.width
+}
+
+// Code returns a stable code for the kind of error.
+func (a *AmbiguousUnits) Code() Code {
+	return CodeAmbiguousUnit
+}
+
+// Expected returns nothing; no particular token was expected.
+func (a *AmbiguousUnits) Expected() []TokenType {
+	return nil
+}
+
+// InputTooLong is an error indicating that the input is longer than the limit.
+type InputTooLong struct {
+	limit    int    // the maximum number of characters
+	input    string // the input string
+	position int    // the position of the error
+	width    int    // the width of the error
+}
+
+func (i *InputTooLong) Error() string {
+	return i.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (i *InputTooLong) Translate(loc locale.Locale) string {
+	return loc.Sprintf("the input is longer than %d characters", i.limit)
+}
+
+// Input returns the input string.
+func (i *InputTooLong) Input() string {
+	return i.input
+}
+
+// Position returns the position of the error.
+func (i *InputTooLong) Position() (start, width int) {
+	return i.position, i.width
+}
+
+// Code returns a stable code for the kind of error.
+func (i *InputTooLong) Code() Code {
+	return CodeInputTooLong
+}
+
+// Expected returns nothing; no particular token was expected.
+func (i *InputTooLong) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (i *InputTooLong) Suggestions() []string {
+	return nil
+}
+
+// TooManyTokens is an error indicating that the input has more tokens than the limit.
+type TooManyTokens struct {
+	limit    int    // the maximum number of tokens
+	input    string // the input string
+	position int    // the position of the error
+	width    int    // the width of the error
+}
+
+func (t *TooManyTokens) Error() string {
+	return t.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (t *TooManyTokens) Translate(loc locale.Locale) string {
+	return loc.Sprintf("the input has more than %d tokens", t.limit)
+}
+
+// Input returns the input string.
+func (t *TooManyTokens) Input() string {
+	return t.input
+}
+
+// Position returns the position of the error.
+func (t *TooManyTokens) Position() (start, width int) {
+	return t.position, t.width
+}
+
+// Code returns a stable code for the kind of error.
+func (t *TooManyTokens) Code() Code {
+	return CodeTooManyTokens
+}
+
+// Expected returns nothing; no particular token was expected.
+func (t *TooManyTokens) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (t *TooManyTokens) Suggestions() []string {
+	return nil
+}
+
+// TooDeep is an error indicating that the expression is nested deeper than the limit.
+type TooDeep struct {
+	limit    int    // the maximum depth
+	input    string // the input string
+	position int    // the position of the error
+	width    int    // the width of the error
+}
+
+func (t *TooDeep) Error() string {
+	return t.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (t *TooDeep) Translate(loc locale.Locale) string {
+	return loc.Sprintf("the expression is nested more than %d levels deep", t.limit)
+}
+
+// Input returns the input string.
+func (t *TooDeep) Input() string {
+	return t.input
+}
+
+// Position returns the position of the error.
+func (t *TooDeep) Position() (start, width int) {
+	return t.position, t.width
+}
+
+// Code returns a stable code for the kind of error.
+func (t *TooDeep) Code() Code {
+	return CodeTooDeep
+}
+
+// Expected returns nothing; no particular token was expected.
+func (t *TooDeep) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (t *TooDeep) Suggestions() []string {
+	return nil
+}
+
+// TooManyDigits is an error indicating that a number has more significant digits than can be calculated precisely.
+type TooManyDigits struct {
+	number   Token  // the number with too many digits
+	limit    int    // the maximum number of significant digits
+	input    string // the input string
+	position int    // the position of the error
+	width    int    // the width of the error
+}
+
+func (t *TooManyDigits) Error() string {
+	return t.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (t *TooManyDigits) Translate(loc locale.Locale) string {
+	return loc.Sprintf("'%s' has more than %d significant digits", t.number.Value, t.limit)
+}
+
+// Input returns the input string.
+func (t *TooManyDigits) Input() string {
+	return t.input
+}
+
+// Position returns the position of the error.
+func (t *TooManyDigits) Position() (start, width int) {
+	return t.position, t.width
+}
+
+// Code returns a stable code for the kind of error.
+func (t *TooManyDigits) Code() Code {
+	return CodeTooManyDigits
+}
+
+// Expected returns nothing; no particular token was expected.
+func (t *TooManyDigits) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (t *TooManyDigits) Suggestions() []string {
+	return nil
+}
+
+// Cancelled is an error indicating that the calculation was cancelled or its deadline was exceeded.
+type Cancelled struct {
+	cause error  // why the calculation was cancelled
+	input string // the input string
+}
+
+func (c *Cancelled) Error() string {
+	return c.Translate(locale.Default)
+}
+
+// Translate returns the error message in the language of a locale.
+func (c *Cancelled) Translate(loc locale.Locale) string {
+	return loc.Sprintf("the calculation was cancelled; %s", c.cause)
+}
+
+// Unwrap returns the cause of the cancellation; like context.DeadlineExceeded.
+func (c *Cancelled) Unwrap() error {
+	return c.cause
+}
+
+// Input returns the input string.
+func (c *Cancelled) Input() string {
+	return c.input
+}
+
+// Position returns the position of the error.
+func (c *Cancelled) Position() (start, width int) {
+	return 1, 0
+}
+
+// Code returns a stable code for the kind of error.
+func (c *Cancelled) Code() Code {
+	return CodeCancelled
+}
+
+// Expected returns nothing; no particular token was expected.
+func (c *Cancelled) Expected() []TokenType {
+	return nil
+}
+
+// Suggestions returns nothing; there are no alternatives to suggest.
+func (c *Cancelled) Suggestions() []string {
+	return nil
+}
@@ -1,19 +1,47 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	u "github.com/bcicen/go-units"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/trace"
+	"math"
+	"sort"
+	"strings"
 )
 
 // Amount is the result of evaluating an expression.
 type Amount struct {
-	Value float64
-	Units Token
+	Value    float64 // the value or, for an interval, the lower bound
+	Units    Token   // the units of measure
+	Upper    float64 // the upper bound of an interval
+	Interval bool    // true if the amount is an interval like '3..5 kg'
+	Radix    int     // the radix used to display the amount, like 16 for hexadecimal; 0 if unspecified
+}
+
+// Bounds returns the lower and upper bounds of the amount. Both bounds are equal unless the amount is an interval.
+func (a Amount) Bounds() (lower, upper float64) {
+	if a.Interval {
+		return a.Value, a.Upper
+	}
+	return a.Value, a.Value
 }
 
 // Expression is something that can be evaluated.
 type Expression interface {
 	Eval(input string) (Amount, InputError)
+	// EvalContext evaluates the expression unless the context is done.
+	EvalContext(ctx context.Context, input string) (Amount, InputError)
+}
+
+// checkContext returns an error if the context of a calculation is done.
+func checkContext(ctx context.Context, input string) InputError {
+	if err := ctx.Err(); err != nil {
+		return ErrorCancelled(input, err)
+	}
+	return nil
 }
 
 // Value represents a fixed Value like "2 pounds".
@@ -29,10 +57,17 @@ func NewValue(number float64, unit Token) Value {
 
 // Eval evaluates a simple Value expression.
 func (v Value) Eval(input string) (Amount, InputError) {
-	// validate the units
+	return v.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a simple Value expression unless the context is done.
+func (v Value) EvalContext(ctx context.Context, input string) (Amount, InputError) {
 	var amount Amount
-	_, err := u.Find(v.unit.Value)
-	if err != nil {
+	if err := checkContext(ctx, input); err != nil {
+		return amount, err
+	}
+	// validate the units; a number displayed in a radix like '255 in hex' has none
+	if _, err := registry.Find(v.unit.Value); err != nil && v.unit.Value != "" {
 		return amount, ErrorInvalidUnits(input, v.unit)
 	}
 	return Amount{
@@ -45,21 +80,110 @@ func (v Value) String() string {
 	return fmt.Sprintf("%.2f %s", v.number, v.unit)
 }
 
+// Variable represents a named amount like "rent".
+type Variable struct {
+	name   Token
+	amount Amount
+}
+
+// NewVariable creates a new Variable.
+func NewVariable(name Token, amount Amount) Variable {
+	return Variable{name, amount}
+}
+
+// Eval evaluates a Variable expression.
+func (v Variable) Eval(input string) (Amount, InputError) {
+	return v.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a Variable expression unless the context is done. The units of the amount are placed
+// at the variable so that any error refers to the variable.
+func (v Variable) EvalContext(ctx context.Context, input string) (Amount, InputError) {
+	if err := checkContext(ctx, input); err != nil {
+		return Amount{}, err
+	}
+	amount := v.amount
+	amount.Units = Units.TokenAt(amount.Units.Value, v.name.Position)
+	return amount, nil
+}
+
+func (v Variable) String() string {
+	return v.name.Value
+}
+
+// Interval represents a range of values like "3..5 kg".
+type Interval struct {
+	lower float64
+	upper float64
+	unit  Token
+}
+
+// NewInterval creates a new Interval.
+func NewInterval(lower, upper float64, unit Token) Interval {
+	if lower > upper {
+		lower, upper = upper, lower
+	}
+	return Interval{lower, upper, unit}
+}
+
+// Eval evaluates an Interval expression.
+func (i Interval) Eval(input string) (Amount, InputError) {
+	return i.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates an Interval expression unless the context is done.
+func (i Interval) EvalContext(ctx context.Context, input string) (Amount, InputError) {
+	var amount Amount
+	if err := checkContext(ctx, input); err != nil {
+		return amount, err
+	}
+	// validate the units; numbers displayed in a radix like '1..16 in hex' have none
+	if _, err := registry.Find(i.unit.Value); err != nil && i.unit.Value != "" {
+		return amount, ErrorInvalidUnits(input, i.unit)
+	}
+	return Amount{
+		Value:    i.lower,
+		Upper:    i.upper,
+		Units:    i.unit,
+		Interval: true,
+	}, nil
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("%.2f..%.2f %s", i.lower, i.upper, i.unit)
+}
+
 // Addition is an expression that performs addition.
 type Addition struct {
-	left  Expression
-	right Expression
+	left   Expression
+	right  Expression
+	region registry.Region
 }
 
 // AdditionExpr creates a new expression that performs Addition.
 func AdditionExpr(left, right Expression) Addition {
-	return Addition{left, right}
+	return Addition{left: left, right: right}
+}
+
+// InRegion resolves ambiguous units like 'gal' with the preferences of a region.
+func (s Addition) InRegion(region registry.Region) Addition {
+	s.region = region
+	return s
 }
 
 // Eval evaluates an Addition expression.
 func (s Addition) Eval(input string) (sum Amount, err InputError) {
-	add := func(l float64, r float64) float64 { return l + r }
-	return eval(s.left, s.right, add, input)
+	return s.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates an Addition expression unless the context is done.
+func (s Addition) EvalContext(ctx context.Context, input string) (sum Amount, err InputError) {
+	add := func(left, right Amount) (lower, upper float64) {
+		leftLower, leftUpper := left.Bounds()
+		rightLower, rightUpper := right.Bounds()
+		return leftLower + rightLower, leftUpper + rightUpper
+	}
+	return eval(ctx, s.left, s.right, add, trace.Addition, s.region, input)
 }
 
 func (s Addition) String() string {
@@ -68,114 +192,486 @@ func (s Addition) String() string {
 
 // Subtraction an expression that performs subtraction.
 type Subtraction struct {
-	left  Expression
-	right Expression
+	left   Expression
+	right  Expression
+	region registry.Region
 }
 
 // SubtractionExpr creates a new expression that performs Subtraction.
 func SubtractionExpr(left, right Expression) Subtraction {
-	return Subtraction{left, right}
+	return Subtraction{left: left, right: right}
+}
+
+// InRegion resolves ambiguous units like 'gal' with the preferences of a region.
+func (s Subtraction) InRegion(region registry.Region) Subtraction {
+	s.region = region
+	return s
 }
 
 // Eval evaluates a Subtraction expression.
 func (s Subtraction) Eval(input string) (diff Amount, err InputError) {
-	subtract := func(l float64, r float64) float64 { return l - r }
-	return eval(s.left, s.right, subtract, input)
+	return s.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a Subtraction expression unless the context is done.
+func (s Subtraction) EvalContext(ctx context.Context, input string) (diff Amount, err InputError) {
+	subtract := func(left, right Amount) (lower, upper float64) {
+		leftLower, leftUpper := left.Bounds()
+		rightLower, rightUpper := right.Bounds()
+		return leftLower - rightUpper, leftUpper - rightLower
+	}
+	return eval(ctx, s.left, s.right, subtract, trace.Subtraction, s.region, input)
 }
 
 func (s Subtraction) String() string {
 	return fmt.Sprintf("%s - %s", s.left, s.right)
 }
 
+// Scaling is an expression that multiplies, or divides, an amount by a number; like '1200 USD / 3'.
+type Scaling struct {
+	expr     Expression
+	operator Token
+	factor   float64
+	number   Token
+}
+
+// ScalingExpr creates a new expression that multiplies, or divides, the amount of an expression by a number.
+func ScalingExpr(expr Expression, operator Token, factor float64, number Token) Scaling {
+	return Scaling{expr: expr, operator: operator, factor: factor, number: number}
+}
+
+// Eval evaluates a Scaling expression.
+func (s Scaling) Eval(input string) (Amount, InputError) {
+	return s.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a Scaling expression unless the context is done.
+func (s Scaling) EvalContext(ctx context.Context, input string) (Amount, InputError) {
+	amount, err := s.expr.EvalContext(ctx, input)
+	if err != nil {
+		return amount, err
+	}
+	kind, scale := trace.Multiplication, func(value float64) float64 { return value * s.factor }
+	if s.operator.TokenType == Divide {
+		if s.factor == 0 {
+			return amount, ErrorDivisionByZero(input, quantity(amount).String(), s.number)
+		}
+		kind, scale = trace.Division, func(value float64) float64 { return value / s.factor }
+	}
+	lower, upper := amount.Bounds()
+	scaled := newAmount(scale(lower), scale(upper), amount.Units, amount.Interval)
+	scaled.Radix = amount.Radix
+	if recorder := trace.FromContext(ctx); recorder != nil {
+		recorder.Record(trace.Step{
+			Kind:     kind,
+			Operands: []trace.Quantity{quantity(amount)},
+			Result:   quantity(scaled),
+			Factor:   s.factor,
+		})
+	}
+	return scaled, nil
+}
+
+func (s Scaling) String() string {
+	return fmt.Sprintf("%s %s %g", s.expr, s.operator.Value, s.factor)
+}
+
+// Product is an expression that multiplies, or divides, one amount by another; like '(10..12 m) * (3..4 m)'.
+type Product struct {
+	left     Expression
+	operator Token
+	right    Expression
+	region   registry.Region
+}
+
+// ProductExpr creates a new expression that multiplies, or divides, the amount of one expression by another.
+func ProductExpr(left Expression, operator Token, right Expression) Product {
+	return Product{left: left, operator: operator, right: right}
+}
+
+// InRegion resolves ambiguous units like 'gal' with the preferences of a region.
+func (p Product) InRegion(region registry.Region) Product {
+	p.region = region
+	return p
+}
+
+// Eval evaluates a Product expression.
+func (p Product) Eval(input string) (Amount, InputError) {
+	return p.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a Product expression unless the context is done. The bounds of the result are the least
+// and greatest products, or quotients, of the bounds of the operands.
+func (p Product) EvalContext(ctx context.Context, input string) (Amount, InputError) {
+	var result Amount
+	left, err := p.left.EvalContext(ctx, input)
+	if err != nil {
+		return result, err
+	}
+	right, err := p.right.EvalContext(ctx, input)
+	if err != nil {
+		return result, err
+	}
+	units, left, right, err := p.units(ctx, left, right, input)
+	if err != nil {
+		return result, err
+	}
+	leftLower, leftUpper := left.Bounds()
+	rightLower, rightUpper := right.Bounds()
+	kind, op := trace.Multiplication, func(l, r float64) float64 { return l * r }
+	if p.operator.TokenType == Divide {
+		if rightLower <= 0 && rightUpper >= 0 {
+			return result, ErrorDivisionByZero(input, quantity(left).String(), right.Units)
+		}
+		kind, op = trace.Division, func(l, r float64) float64 { return l / r }
+	}
+	bounds := []float64{op(leftLower, rightLower), op(leftLower, rightUpper), op(leftUpper, rightLower), op(leftUpper, rightUpper)}
+	lower, upper := bounds[0], bounds[0]
+	for _, bound := range bounds[1:] {
+		lower, upper = math.Min(lower, bound), math.Max(upper, bound)
+	}
+	result = newAmount(lower, upper, units, left.Interval || right.Interval)
+	if recorder := trace.FromContext(ctx); recorder != nil {
+		recorder.Record(trace.Step{Kind: kind, Operands: []trace.Quantity{quantity(left), quantity(right)}, Result: quantity(result)})
+	}
+	return result, nil
+}
+
+// units returns the units of the result, along with the operands converted to the units that are multiplied, or
+// divided. Lengths are multiplied into areas, like 'm^2'; an area divided by a length is a length; and an amount
+// divided by another of the same quantity has no units. An amount with no units scales the other.
+func (p Product) units(ctx context.Context, left, right Amount, input string) (Token, Amount, Amount, InputError) {
+	incompatible := ErrorIncompatibleFactors(input, p.operator, left.Units.Value, right.Units)
+	if right.Units.Value == "" {
+		return left.Units, left, right, nil
+	}
+	if left.Units.Value == "" {
+		if p.operator.TokenType == Divide {
+			return Token{}, left, right, incompatible
+		}
+		return right.Units, left, right, nil
+	}
+	leftUnit, err := resolveUnits(input, left.Units, right.Units, p.region)
+	if err != nil {
+		return Token{}, left, right, err
+	}
+	at := func(units string) Token {
+		return Units.TokenAt(units, left.Units.Position)
+	}
+	if p.operator.TokenType == Divide {
+		if converted, err := p.convert(ctx, right, left.Units, incompatible, input); err == nil {
+			return at(""), left, converted, nil
+		}
+	}
+	var area, length u.Unit
+	var ok bool
+	switch leftUnit.Quantity {
+	case u.Meter.Quantity:
+		length = leftUnit
+		area, ok = registry.Square(leftUnit)
+	case registry.SquareMeter.Quantity:
+		area = leftUnit
+		length, ok = registry.Side(leftUnit)
+	default:
+		return Token{}, left, right, incompatible
+	}
+	if !ok {
+		// units like acres, or nautical miles, have no side, or square, so meters are used instead
+		base := registry.SquareMeter
+		if leftUnit.Quantity == u.Meter.Quantity {
+			base = u.Meter
+		}
+		area, length = registry.SquareMeter, u.Meter
+		if left, err = p.convert(ctx, left, at(symbol(base)), incompatible, input); err != nil {
+			return Token{}, left, right, err
+		}
+	}
+	right, err = p.convert(ctx, right, at(symbol(length)), incompatible, input)
+	if p.operator.TokenType == Multiply {
+		return at(symbol(area)), left, right, err
+	}
+	return at(symbol(length)), left, right, err
+}
+
+// symbol returns the symbol of a unit, or its name if it has none; like 'mile'.
+func symbol(unit u.Unit) string {
+	if unit.Symbol == "" {
+		return unit.Name
+	}
+	return unit.Symbol
+}
+
+// convert converts the right operand to the units of the left; units that cannot be converted are incompatible.
+func (p Product) convert(ctx context.Context, right Amount, units Token, incompatible InputError, input string) (Amount, InputError) {
+	converted, err := convert(ctx, right, units, p.region, input)
+	var conversion *InvalidUnitConversion
+	if errors.As(err, &conversion) {
+		return converted, incompatible
+	}
+	return converted, err
+}
+
+func (p Product) String() string {
+	return fmt.Sprintf("%s %s %s", p.left, p.operator.Value, p.right)
+}
+
 // UnitConversion converts between units of measure
 type UnitConversion struct {
 	expr        Expression
 	targetUnits Token
+	region      registry.Region
 }
 
 // UnitConversionExpr creates a new unit conversion expression.
 func UnitConversionExpr(expr Expression, targetUnits Token) UnitConversion {
-	return UnitConversion{expr, targetUnits}
+	return UnitConversion{expr: expr, targetUnits: targetUnits}
+}
+
+// InRegion resolves ambiguous units like 'gal' with the preferences of a region.
+func (c UnitConversion) InRegion(region registry.Region) UnitConversion {
+	c.region = region
+	return c
 }
 
-// Eval evaluates a unit conversion expression.s
+// Eval evaluates a unit conversion expression.
 func (c UnitConversion) Eval(input string) (amount Amount, err InputError) {
+	return c.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a unit conversion expression unless the context is done.
+func (c UnitConversion) EvalContext(ctx context.Context, input string) (amount Amount, err InputError) {
 	// evaluate the expression
-	amount, err = c.expr.Eval(input)
+	amount, err = c.expr.EvalContext(ctx, input)
 	if err != nil {
 		return amount, err
 	}
-	// is unit conversion needed?
-	if amount.Units.String() != c.targetUnits.Value {
-		fromUnits, unitErr := u.Find(amount.Units.Value)
-		if unitErr != nil {
-			return amount, ErrorInvalidUnits(input, amount.Units)
-		}
+	if amount.Units.Value == "" {
+		return amount, ErrorUnitlessOperand(input, operand(c.expr, amount), c.targetUnits)
+	}
+	return convert(ctx, amount, c.targetUnits, c.region, input)
+}
+
+// convert converts an amount that is already evaluated to the target units; both bounds are converted
+// when the amount is an interval.
+func convert(ctx context.Context, amount Amount, targetUnits Token, region registry.Region, input string) (Amount, InputError) {
+	// is unit conversion needed? the same units need not be resolved, even if ambiguous like 'oz'
+	if amount.Units.Value == targetUnits.Value {
+		amount.Units = targetUnits
+		return amount, nil
+	}
+	fromUnits, unitErr := resolveUnits(input, amount.Units, targetUnits, region)
+	if unitErr != nil {
+		return amount, unitErr
+	}
+	toUnits, unitErr := resolveUnits(input, targetUnits, amount.Units, region)
+	if unitErr != nil {
+		return amount, unitErr
+	}
 
-		toUnits, unitErr := u.Find(c.targetUnits.Value)
+	if fromUnits.Name == toUnits.Name {
+		// no conversion is necessary; for example 2 kilograms in kg requires no conversion
+		amount.Units = targetUnits
+		return amount, nil
+	}
+
+	// unit conversion; both bounds are converted when the amount is an interval
+	lower, upper := amount.Bounds()
+	lowerValue, convErr := u.ConvertFloat(lower, fromUnits, toUnits)
+	if convErr != nil {
+		return amount, ErrorInvalidUnitConversion(input, amount.Units, targetUnits)
+	}
+	upperValue, convErr := u.ConvertFloat(upper, fromUnits, toUnits)
+	if convErr != nil {
+		return amount, ErrorInvalidUnitConversion(input, amount.Units, targetUnits)
+	}
+	converted := newAmount(lowerValue.Float(), upperValue.Float(), targetUnits, amount.Interval)
+	if recorder := trace.FromContext(ctx); recorder != nil {
+		recorder.Record(trace.Step{
+			Kind:     trace.Conversion,
+			Operands: []trace.Quantity{quantity(amount)},
+			Result:   quantity(converted),
+			Factor:   conversionFactor(fromUnits, toUnits),
+		})
+	}
+	return converted, nil
+}
+
+// conversionFactor returns the factor that converts one unit to another; 0 if the conversion is not linear like °C to °F.
+func conversionFactor(from, to u.Unit) float64 {
+	zero, err := u.ConvertFloat(0, from, to)
+	if err != nil || zero.Float() != 0 {
+		return 0
+	}
+	one, err := u.ConvertFloat(1, from, to)
+	if err != nil {
+		return 0
+	}
+	return one.Float()
+}
+
+func (c UnitConversion) String() string {
+	return fmt.Sprintf("%s in %s", c.expr, c.targetUnits)
+}
+
+// operand returns a token that names an operand in an error, like the variable 'ratio'. An operand that is not
+// a variable has no name, and is placed at its units.
+func operand(expr Expression, amount Amount) Token {
+	if variable, ok := expr.(Variable); ok {
+		return variable.name
+	}
+	return amount.Units
+}
+
+// resolveUnits finds the units to convert from or to. Ambiguous units like 'oz' are resolved by the
+// quantity of the other units, like 'volume' for '8 oz in ml', and then by the preferences of the region.
+func resolveUnits(input string, units Token, other Token, region registry.Region) (u.Unit, InputError) {
+	resolved, err := registry.Resolve(units.Value, region, registry.Quantities(other.Value)...)
+	var ambiguous *registry.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		return resolved, ErrorAmbiguousUnits(input, units, ambiguous.Candidates)
+	}
+	if err != nil {
+		return resolved, ErrorInvalidUnits(input, units)
+	}
+	return resolved, nil
+}
+
+// the names of the radixes that an amount can be displayed in; 23 bytes in hex
+var radixes = map[string]int{
+	"bin":         2,
+	"binary":      2,
+	"oct":         8,
+	"octal":       8,
+	"hex":         16,
+	"hexadecimal": 16,
+}
+
+// IsRadix returns true if the name refers to a radix like 'hex' or 'bin'.
+func IsRadix(name string) bool {
+	_, ok := radixes[strings.ToLower(name)]
+	return ok
+}
+
+// RadixNames returns the names of the radixes that an amount can be displayed in, in order; like 'hex'.
+func RadixNames() []string {
+	names := make([]string, 0, len(radixes))
+	for name := range radixes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RadixConversion displays an amount in a different radix like hexadecimal.
+type RadixConversion struct {
+	expr  Expression
+	radix Token
+}
+
+// RadixConversionExpr creates a new radix conversion expression.
+func RadixConversionExpr(expr Expression, radix Token) RadixConversion {
+	return RadixConversion{expr, radix}
+}
+
+// Eval evaluates a radix conversion expression.
+func (r RadixConversion) Eval(input string) (amount Amount, err InputError) {
+	return r.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates a radix conversion expression unless the context is done.
+func (r RadixConversion) EvalContext(ctx context.Context, input string) (amount Amount, err InputError) {
+	amount, err = r.expr.EvalContext(ctx, input)
+	if err != nil {
+		return amount, err
+	}
+	// only whole numbers, of bytes or bits if there are units, can be displayed in a different radix
+	if amount.Units.Value != "" {
+		units, unitErr := registry.Find(amount.Units.Value)
 		if unitErr != nil {
-			return amount, ErrorInvalidUnits(input, c.targetUnits)
+			return amount, ErrorInvalidUnits(input, amount.Units)
 		}
-
-		if fromUnits.Name == toUnits.Name {
-			// no conversion is necessary; for example 2 kilograms in kg requires no conversion
-			amount = Amount{amount.Value, c.targetUnits}
-			return amount, nil
+		if units.Quantity != u.Byte.Quantity {
+			return amount, ErrorInvalidRadixConversion(input, amount.Units.Value, r.radix)
 		}
-
-		// unit conversion
-		value, err := u.ConvertFloat(amount.Value, fromUnits, toUnits)
-		if err != nil {
-			return amount, ErrorInvalidUnitConversion(input, amount.Units, c.targetUnits)
+	}
+	lower, upper := amount.Bounds()
+	for _, bound := range []float64{lower, upper} {
+		if bound != math.Trunc(bound) {
+			value := strings.TrimSpace(fmt.Sprintf("%g %s", bound, amount.Units.Value))
+			return amount, ErrorInvalidRadixConversion(input, value, r.radix)
 		}
-		amount = Amount{value.Float(), c.targetUnits}
+	}
+	amount.Radix = radixes[strings.ToLower(r.radix.Value)]
+	if recorder := trace.FromContext(ctx); recorder != nil {
+		recorder.Record(trace.Step{
+			Kind:     trace.Radix,
+			Operands: []trace.Quantity{quantity(amount)},
+			Result:   quantity(amount),
+			Radix:    amount.Radix,
+		})
 	}
 	return amount, nil
 }
 
-func (c UnitConversion) String() string {
-	return fmt.Sprintf("%s in %s", c.expr, c.targetUnits)
+func (r RadixConversion) String() string {
+	return fmt.Sprintf("%s in %s", r.expr, r.radix)
 }
 
-type opFunction func(float64, float64) float64
+// opFunction applies an operator to the bounds of two amounts and returns the bounds of the result.
+type opFunction func(left, right Amount) (lower, upper float64)
 
-func eval(leftExpr Expression, rightExpr Expression, opFunc opFunction, input string) (Amount, InputError) {
+// newAmount creates an amount from its bounds, which are reordered if necessary.
+func newAmount(lower, upper float64, units Token, interval bool) Amount {
+	if lower > upper {
+		lower, upper = upper, lower
+	}
+	if !interval {
+		return Amount{Value: lower, Units: units}
+	}
+	return Amount{Value: lower, Upper: upper, Units: units, Interval: true}
+}
+
+// quantity describes an amount for the trace of an evaluation.
+func quantity(amount Amount) trace.Quantity {
+	return trace.Quantity{Value: amount.Value, Upper: amount.Upper, Interval: amount.Interval, Units: amount.Units.Value}
+}
+
+func eval(ctx context.Context, leftExpr Expression, rightExpr Expression, opFunc opFunction, kind trace.Kind, region registry.Region, input string) (Amount, InputError) {
+	recorder := trace.FromContext(ctx)
 	var result Amount
 
 	// evaluate the left side
-	left, err := leftExpr.Eval(input)
+	left, err := leftExpr.EvalContext(ctx, input)
 	if err != nil {
 		return result, err
 	}
 
 	// prefer the units of the left side
 	targetUnit := left.Units
-
-	// unit conversion, if needed
-	if left.Units != targetUnit {
-		left, err = UnitConversionExpr(leftExpr, targetUnit).Eval(input)
-		if err != nil {
-			return result, err
-		}
+	if recorder != nil {
+		recorder.Record(trace.Step{Kind: trace.Units, Operands: []trace.Quantity{quantity(left)}, Result: quantity(left)})
 	}
 
-	// evaluate the right side
-	right, err := rightExpr.Eval(input)
+	// evaluate the right side, and then convert it to the units of the left side
+	right, err := rightExpr.EvalContext(ctx, input)
 	if err != nil {
 		return result, err
 	}
-
-	// unit conversion, if needed
-	if right.Units != targetUnit {
-		right, err = UnitConversionExpr(rightExpr, targetUnit).Eval(input)
-		if err != nil {
-			return result, err
-		}
+	switch {
+	case left.Units.Value == "" && right.Units.Value != "":
+		return result, ErrorUnitlessOperand(input, operand(leftExpr, left), right.Units)
+	case right.Units.Value == "" && left.Units.Value != "":
+		return result, ErrorUnitlessOperand(input, operand(rightExpr, right), left.Units)
+	}
+	right, err = convert(ctx, right, targetUnit, region, input)
+	if err != nil {
+		return result, err
 	}
 
-	result = Amount{
-		Value: opFunc(left.Value, right.Value),
-		Units: targetUnit,
+	lower, upper := opFunc(left, right)
+	result = newAmount(lower, upper, targetUnit, left.Interval || right.Interval)
+	if recorder != nil {
+		recorder.Record(trace.Step{Kind: kind, Operands: []trace.Quantity{quantity(left), quantity(right)}, Result: quantity(result)})
 	}
 	return result, nil
 }
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/trace"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -23,6 +26,54 @@ func TestValue_Eval_InvalidUnits(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestVariable_Eval(t *testing.T) {
+	input := "2 kg + rent"
+	stored := Amount{Value: 1200, Units: Units.TokenAt("USD", 10)}
+	amount, err := NewVariable(Name.TokenAt("rent", 8), stored).Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1200), amount.Value)
+	assert.Equal(t, Units.TokenAt("USD", 8), amount.Units)
+}
+
+func TestAddition_Eval_UnitlessVariable(t *testing.T) {
+	ratio := NewVariable(Name.TokenAt("ratio", 1), Amount{Value: 5})
+	for input, expr := range map[string]Expression{
+		"ratio + 3 kg": AdditionExpr(ratio, NewValue(3, Units.TokenAt("kg", 11))),
+		"ratio - 3 kg": SubtractionExpr(ratio, NewValue(3, Units.TokenAt("kg", 11))),
+	} {
+		_, err := expr.Eval(input)
+		if assert.IsType(t, &UnitlessOperand{}, err, input) {
+			assert.Equal(t, "'ratio' has no units, so cannot be combined with kg", err.Error())
+			assert.Equal(t, CodeIncompatibleUnits, err.Code())
+			start, width := err.Position()
+			assert.Equal(t, []int{1, 5}, []int{start, width})
+		}
+	}
+}
+
+func TestSubtraction_Eval_UnitlessVariable(t *testing.T) {
+	input := "3 kg - ratio"
+	ratio := NewVariable(Name.TokenAt("ratio", 8), Amount{Value: 5})
+	_, err := SubtractionExpr(NewValue(3, Units.TokenAt("kg", 3)), ratio).Eval(input)
+	if assert.IsType(t, &UnitlessOperand{}, err) {
+		assert.Equal(t, "'ratio' has no units, so cannot be combined with kg", err.Error())
+		start, width := err.Position()
+		assert.Equal(t, []int{8, 5}, []int{start, width})
+	}
+}
+
+func TestAddition_Eval_UnitlessVariables(t *testing.T) {
+	input := "x + y"
+	x := NewVariable(Name.TokenAt("x", 1), Amount{Value: 5})
+	y := NewVariable(Name.TokenAt("y", 5), Amount{Value: 2})
+	amount, err := AdditionExpr(x, y).Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(7), amount.Value)
+	amount, err = SubtractionExpr(x, y).Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), amount.Value)
+}
+
 func TestAddition_Eval(t *testing.T) {
 	input := "2 pounds + 1 stone"
 	pounds := Units.TokenAt("pounds", 3)
@@ -54,6 +105,44 @@ func TestAddition_Eval_InvalidUnits(t *testing.T) {
 	}
 }
 
+func TestScaling_Eval(t *testing.T) {
+	kg := Units.TokenAt("kg", 3)
+	tests := map[string]struct {
+		operator Token
+		factor   float64
+		expected Amount
+	}{
+		"2 kg * 3":     {Multiply.TokenAt("*", 6), 3, Amount{Value: 6, Units: kg}},
+		"2 kg / 4":     {Divide.TokenAt("/", 6), 4, Amount{Value: 0.5, Units: kg}},
+		"2 kg * -1.5":  {Multiply.TokenAt("*", 6), -1.5, Amount{Value: -3, Units: kg}},
+		"2 kg / 0.001": {Divide.TokenAt("/", 6), 0.001, Amount{Value: 2000, Units: kg}},
+	}
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			amount, err := ScalingExpr(NewValue(2, kg), test.operator, test.factor, Number.TokenAt("0", 8)).Eval(input)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, amount)
+		})
+	}
+}
+
+func TestScaling_Eval_Interval(t *testing.T) {
+	kg := Units.TokenAt("kg", 6)
+	amount, err := ScalingExpr(NewInterval(2, 4, kg), Multiply.TokenAt("*", 9), -2, Number.TokenAt("-2", 11)).Eval("2..4 kg * -2")
+	assert.Nil(t, err)
+	assert.Equal(t, Amount{Value: -8, Upper: -4, Units: kg, Interval: true}, amount)
+}
+
+func TestScaling_Eval_DivisionByZero(t *testing.T) {
+	input := "2 kg / 0"
+	_, err := ScalingExpr(NewValue(2, Units.TokenAt("kg", 3)), Divide.TokenAt("/", 6), 0, Number.TokenAt("0", 8)).Eval(input)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "cannot divide 2.00 kg by zero", err.Error())
+		start, width := err.Position()
+		assert.Equal(t, []int{8, 1}, []int{start, width})
+	}
+}
+
 func TestSubtraction_Eval(t *testing.T) {
 	input := "2 stones - 1 pound"
 	stones := Units.TokenAt("stone", 2)
@@ -116,3 +205,225 @@ func TestUnitConversion_Eval_InvalidUnits(t *testing.T) {
 		assert.True(t, ok, "expected invalid unit conversion error, got %s", err)
 	}
 }
+
+func TestInterval_Eval(t *testing.T) {
+	input := "5..3 kg"
+	kg := Units.TokenAt("kg", 6)
+	amount, err := NewInterval(5, 3, kg).Eval(input)
+	assert.Nil(t, err)
+	assert.True(t, amount.Interval)
+	lower, upper := amount.Bounds()
+	assert.Equal(t, float64(3), lower)
+	assert.Equal(t, float64(5), upper)
+}
+
+func TestAddition_Eval_Interval(t *testing.T) {
+	input := "1..2 kg + 3..4 kg"
+	kg := Units.TokenAt("kg", 6)
+	amount, err := AdditionExpr(NewInterval(1, 2, kg), NewInterval(3, 4, kg)).Eval(input)
+	assert.Nil(t, err)
+	lower, upper := amount.Bounds()
+	assert.InDelta(t, 4, lower, 0.01)
+	assert.InDelta(t, 6, upper, 0.01)
+}
+
+func TestSubtraction_Eval_Interval(t *testing.T) {
+	input := "3..4 kg - 1..2 kg"
+	kg := Units.TokenAt("kg", 6)
+	amount, err := SubtractionExpr(NewInterval(3, 4, kg), NewInterval(1, 2, kg)).Eval(input)
+	assert.Nil(t, err)
+	lower, upper := amount.Bounds()
+	assert.InDelta(t, 1, lower, 0.01)
+	assert.InDelta(t, 3, upper, 0.01)
+}
+
+func TestUnitConversion_Eval_Interval(t *testing.T) {
+	input := "1..2 stones in pounds"
+	stones := Units.TokenAt("stones", 6)
+	pounds := Units.TokenAt("pounds", 16)
+	amount, err := UnitConversionExpr(NewInterval(1, 2, stones), pounds).Eval(input)
+	assert.Nil(t, err)
+	assert.True(t, amount.Interval)
+	lower, upper := amount.Bounds()
+	assert.InDelta(t, 14, lower, 0.01)
+	assert.InDelta(t, 28, upper, 0.01)
+	assert.Equal(t, pounds, amount.Units)
+}
+
+func TestRadixConversion_Eval(t *testing.T) {
+	input := "255 bytes in hex"
+	bytes := Units.TokenAt("bytes", 5)
+	hex := Units.TokenAt("hex", 14)
+	amount, err := RadixConversionExpr(NewValue(255, bytes), hex).Eval(input)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(255), amount.Value)
+	assert.Equal(t, 16, amount.Radix)
+	assert.Equal(t, bytes, amount.Units)
+}
+
+func TestRadixConversion_Eval_NotData(t *testing.T) {
+	input := "2 kg in hex"
+	kg := Units.TokenAt("kg", 3)
+	hex := Units.TokenAt("hex", 9)
+	_, err := RadixConversionExpr(NewValue(2, kg), hex).Eval(input)
+	if assert.NotNil(t, err) {
+		_, ok := err.(*InvalidRadixConversion)
+		assert.True(t, ok, "expected invalid radix conversion error, got %s", err)
+	}
+}
+
+func TestRadixConversion_Eval_NotWholeNumber(t *testing.T) {
+	input := "1.5 KB in bin"
+	kb := Units.TokenAt("KB", 5)
+	bin := Units.TokenAt("bin", 11)
+	_, err := RadixConversionExpr(NewValue(1.5, kb), bin).Eval(input)
+	if assert.NotNil(t, err) {
+		_, ok := err.(*InvalidRadixConversion)
+		assert.True(t, ok, "expected invalid radix conversion error, got %s", err)
+	}
+}
+
+func TestUnitConversion_Eval_LocalizedUnits(t *testing.T) {
+	input := "2 Kilogramm in Pfund"
+	kg := Units.TokenAt("Kilogramm", 3)
+	pounds := Units.TokenAt("Pfund", 16)
+	amount, err := UnitConversionExpr(NewValue(2.0, kg), pounds).Eval(input)
+	assert.Nil(t, err)
+	assert.InDelta(t, 4.41, amount.Value, 0.01)
+	assert.Equal(t, pounds, amount.Units)
+}
+
+func TestUnitConversion_Eval_AmbiguousUnits(t *testing.T) {
+	input := "3 gal in liters"
+	gal := Units.TokenAt("gal", 3)
+	liters := Units.TokenAt("liters", 10)
+	_, err := UnitConversionExpr(NewValue(3.0, gal), liters).Eval(input)
+	if assert.NotNil(t, err) {
+		ambiguous, ok := err.(*AmbiguousUnits)
+		assert.True(t, ok, "expected ambiguous units error, got %s", err)
+		assert.Equal(t, "'gal' is ambiguous; it could be gallon (imperial), fluid gallon (us)", err.Error())
+		assert.Equal(t, []string{"gallon", "fluid gallon"}, ambiguous.Suggestions())
+	}
+}
+
+func TestUnitConversion_Eval_InRegion(t *testing.T) {
+	input := "3 gal in liters"
+	gal := Units.TokenAt("gal", 3)
+	liters := Units.TokenAt("liters", 10)
+	amount, err := UnitConversionExpr(NewValue(3.0, gal), liters).InRegion(registry.US).Eval(input)
+	assert.Nil(t, err)
+	assert.InDelta(t, 11.36, amount.Value, 0.01)
+}
+
+func TestUnitConversion_Eval_AmbiguousUnitsByQuantity(t *testing.T) {
+	input := "8 oz in g"
+	oz := Units.TokenAt("oz", 3)
+	grams := Units.TokenAt("g", 9)
+	amount, err := UnitConversionExpr(NewValue(8.0, oz), grams).Eval(input)
+	assert.Nil(t, err)
+	assert.InDelta(t, 226.80, amount.Value, 0.01)
+}
+
+func TestAddition_Eval_InRegion(t *testing.T) {
+	input := "2 t + 500 kg"
+	tonnes := Units.TokenAt("t", 3)
+	kg := Units.TokenAt("kg", 11)
+	amount, err := AdditionExpr(NewValue(2.0, tonnes), NewValue(500, kg)).InRegion(registry.Metric).Eval(input)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2.5, amount.Value, 0.01)
+	assert.Equal(t, tonnes, amount.Units)
+}
+
+func TestAddition_EvalContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	input := "2 kg + 3 kg"
+	kg := Units.TokenAt("kg", 3)
+	_, err := AdditionExpr(NewValue(2, kg), NewValue(3, kg)).EvalContext(ctx, input)
+	if assert.NotNil(t, err) {
+		_, ok := err.(*Cancelled)
+		assert.True(t, ok, "expected cancelled error, got %s", err)
+	}
+}
+
+func TestProduct_Eval(t *testing.T) {
+	m, ft := Units.TokenAt("m", 7), Units.TokenAt("ft", 17)
+	tests := map[string]struct {
+		left     Expression
+		operator Token
+		right    Expression
+		expected Amount
+	}{
+		"10..12 m * 3..4 ft": {
+			NewInterval(10, 12, m), Multiply.TokenAt("*", 10), NewInterval(3, 4, ft),
+			Amount{Value: 9.144, Upper: 14.6304, Units: Units.TokenAt("m^2", 7), Interval: true},
+		},
+		"10..12 m / 3..4 ft": {
+			NewInterval(10, 12, m), Divide.TokenAt("/", 10), NewInterval(3, 4, ft),
+			Amount{Value: 8.202099737532809, Upper: 13.123359580052492, Units: Units.TokenAt("", 7), Interval: true},
+		},
+		"10..12 m^2 / 2 ft": {
+			NewInterval(10, 12, Units.TokenAt("m^2", 7)), Divide.TokenAt("/", 10), NewValue(2, ft),
+			Amount{Value: 16.404199475065617, Upper: 19.68503937007874, Units: m, Interval: true},
+		},
+	}
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			amount, err := ProductExpr(test.left, test.operator, test.right).Eval(input)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected.Units, amount.Units)
+			assert.InDelta(t, test.expected.Value, amount.Value, 1e-9)
+			assert.InDelta(t, test.expected.Upper, amount.Upper, 1e-9)
+		})
+	}
+}
+
+func TestProduct_Eval_IncompatibleFactors(t *testing.T) {
+	input := "2 kg * 3 kg"
+	_, err := ProductExpr(NewValue(2, Units.TokenAt("kg", 3)), Multiply.TokenAt("*", 6), NewValue(3, Units.TokenAt("kg", 10))).Eval(input)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, CodeIncompatibleFactors, err.Code())
+		assert.Equal(t, "cannot multiply kg by kg", err.Error())
+		start, width := err.Position()
+		assert.Equal(t, []int{10, 2}, []int{start, width})
+	}
+}
+
+func TestProduct_Eval_DivisionByZero(t *testing.T) {
+	input := "2 m / -1..1 m"
+	_, err := ProductExpr(NewValue(2, Units.TokenAt("m", 3)), Divide.TokenAt("/", 5), NewInterval(-1, 1, Units.TokenAt("m", 13))).Eval(input)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "cannot divide 2.00 m by zero", err.Error())
+	}
+}
+
+func TestEvalTrace(t *testing.T) {
+	recorder := &trace.Recorder{}
+	ctx := trace.NewContext(context.Background(), recorder)
+	expr := UnitConversionExpr(
+		SubtractionExpr(NewValue(2, Units.Token("kg")), NewValue(500, Units.Token("g"))),
+		Units.Token("lbs"))
+	_, err := expr.EvalContext(ctx, "2 kg - 500 g in lbs")
+	assert.Nil(t, err)
+
+	kinds := []trace.Kind{}
+	for _, step := range recorder.Steps() {
+		kinds = append(kinds, step.Kind)
+	}
+	assert.Equal(t, []trace.Kind{trace.Units, trace.Conversion, trace.Subtraction, trace.Conversion}, kinds)
+	assert.Equal(t, 0.001, recorder.Steps()[1].Factor)
+}
+
+func TestEvalTraceNotLinear(t *testing.T) {
+	recorder := &trace.Recorder{}
+	ctx := trace.NewContext(context.Background(), recorder)
+	_, err := UnitConversionExpr(NewValue(10, Units.Token("C")), Units.Token("F")).EvalContext(ctx, "10 C in F")
+	assert.Nil(t, err)
+	if assert.Len(t, recorder.Steps(), 1) {
+		assert.Equal(t, 0.0, recorder.Steps()[0].Factor)
+	}
+}
+
+func TestRadixNames(t *testing.T) {
+	assert.Equal(t, []string{"bin", "binary", "hex", "hexadecimal", "oct", "octal"}, RadixNames())
+}
@@ -27,12 +27,22 @@ const (
 	Multiply
 	// Divide Division as in '/'/
 	Divide
-	// In A symbol for conversions; 23 lbs in kg.
+	// In A keyword or symbol for conversions; 23 lbs in kg, 23 lbs to kg or 23 lbs -> kg.
 	In
 	// Number A numeral Value like 23.
 	Number
 	// Units The units of measure like 'kg' or 'pounds'.
 	Units
+	// Range Separates the bounds of an interval; 3..5 kg.
+	Range
+	// LeftParen Opens a group as in '1 m + (2 ft + 3 in)'.
+	LeftParen
+	// RightParen Closes a group as in '1 m + (2 ft + 3 in)'.
+	RightParen
+	// Name The name of a variable like 'rent'.
+	Name
+	// Assign Assigns a value to a variable as in 'rent = 1200 USD'.
+	Assign
 )
 
 func (t TokenType) String() string {
@@ -55,11 +65,47 @@ func (t TokenType) String() string {
 		return "a number"
 	case Units:
 		return "a unit"
+	case Range:
+		return "'..'"
+	case LeftParen:
+		return "'('"
+	case RightParen:
+		return "')'"
+	case Name:
+		return "a variable"
+	case Assign:
+		return "'='"
 	default:
 		return "unknown"
 	}
 }
 
+// the stable names of each token type
+var tokenNames = map[TokenType]string{
+	Error:      "error",
+	EOF:        "eof",
+	Plus:       "plus",
+	Minus:      "minus",
+	Multiply:   "multiply",
+	Divide:     "divide",
+	In:         "in",
+	Number:     "number",
+	Units:      "units",
+	Range:      "range",
+	LeftParen:  "left-paren",
+	RightParen: "right-paren",
+	Name:       "name",
+	Assign:     "assign",
+}
+
+// Name returns a stable name for the token type, unlike String; like 'eof' or 'units'.
+func (t TokenType) Name() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // Token creates a new Token.
 func (t TokenType) Token(value string) Token {
 	return Token{TokenType: t, Value: value}
@@ -78,10 +124,12 @@ func (t Token) String() string {
 		return "EOF"
 	case Number:
 		return fmt.Sprintf("NUM[%s]", t.Value)
-	case Plus, Minus, Multiply, Divide:
+	case Plus, Minus, Multiply, Divide, Range, LeftParen, RightParen, Assign:
 		return fmt.Sprintf("SYM[%s]", t.Value)
 	case Units:
 		return fmt.Sprintf("UNI[%s]", t.Value)
+	case Name:
+		return fmt.Sprintf("VAR[%s]", t.Value)
 	default:
 		return fmt.Sprintf("TOK[%s]", t.Value)
 	}
@@ -0,0 +1,180 @@
+package calc
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/parser"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/internal/tokenizer"
+	"github.com/nickwallen/quick-calc/internal/types"
+	"unicode/utf8"
+)
+
+// Option configures how an expression is calculated.
+type Option func(*options)
+
+// the settings used to calculate an expression
+type options struct {
+	locale         locale.Locale           // governs how numbers are read and written
+	region         registry.Region         // the preferred units when units are ambiguous like 'gal'
+	maxInputLength int                     // the maximum number of characters in the input
+	maxTokens      int                     // the maximum number of tokens in the input
+	maxDepth       int                     // the maximum number of nested operations
+	maxDigits      int                     // the maximum number of significant digits in a number
+	precision      int                     // the number of decimal places in a result
+	vars           map[string]types.Amount // the value of each variable by name
+}
+
+// the limits that guard against expensive input, like input from an untrusted user; see WithDefaultLimits
+const (
+	DefaultMaxInputLength = 1000
+	DefaultMaxTokens      = 250
+	DefaultMaxDepth       = 100
+)
+
+// DefaultMaxDigits the number of significant digits that a number holds exactly; a limit for WithMaxDigits.
+const DefaultMaxDigits = 15
+
+// DefaultPrecision the number of decimal places in a result, unless set with WithPrecision.
+const DefaultPrecision = 2
+
+// MaxPrecision the most decimal places in a result; a float64 holds no more than 17 significant digits.
+const MaxPrecision = 17
+
+// newOptions returns the default settings with the given options applied.
+func newOptions(opts ...Option) *options {
+	o := &options{
+		locale:    locale.Default,
+		precision: DefaultPrecision,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithLocale sets the locale that governs how numbers are read and written.
+func WithLocale(loc Locale) Option {
+	return func(o *options) {
+		o.locale = loc
+	}
+}
+
+// WithDefaultLimits limits the length of the input, the number of tokens and the number of nested operations,
+// to guard against expensive input; like input from an untrusted user. There are no limits unless set.
+func WithDefaultLimits() Option {
+	return func(o *options) {
+		o.maxInputLength = DefaultMaxInputLength
+		o.maxTokens = DefaultMaxTokens
+		o.maxDepth = DefaultMaxDepth
+	}
+}
+
+// WithMaxInputLength limits the number of characters in the input; 0 if unlimited.
+func WithMaxInputLength(limit int) Option {
+	return func(o *options) {
+		o.maxInputLength = limit
+	}
+}
+
+// WithMaxTokens limits the number of tokens in the input; 0 if unlimited.
+func WithMaxTokens(limit int) Option {
+	return func(o *options) {
+		o.maxTokens = limit
+	}
+}
+
+// WithMaxDepth limits the number of nested operations, like the two in '1 kg + 2 kg + 3 kg'; 0 if unlimited.
+func WithMaxDepth(limit int) Option {
+	return func(o *options) {
+		o.maxDepth = limit
+	}
+}
+
+// WithMaxDigits limits the number of significant digits in a number, beyond which
+// a number cannot be calculated precisely; 0 if unlimited.
+func WithMaxDigits(limit int) Option {
+	return func(o *options) {
+		o.maxDigits = limit
+	}
+}
+
+// WithPrecision sets the number of decimal places in a result; like 4 for '2.2046 lbs'. The precision is
+// at most MaxPrecision.
+func WithPrecision(digits int) Option {
+	return func(o *options) {
+		if digits > MaxPrecision {
+			digits = MaxPrecision
+		}
+		if digits >= 0 {
+			o.precision = digits
+		}
+	}
+}
+
+// WithVariables sets the value of each variable that can be used in place of a value; like 'rent' in
+// 'rent / 3'. The value of an assignment like 'rent = 1200 USD' is kept by the caller; see CalculateAssignment.
+func WithVariables(vars map[string]Amount) Option {
+	return func(o *options) {
+		o.vars = vars
+	}
+}
+
+// variableNames returns the names of the variables.
+func (o *options) variableNames() []string {
+	names := make([]string, 0, len(o.vars))
+	for name := range o.vars {
+		names = append(names, name)
+	}
+	return names
+}
+
+// checkLength returns an error if the input is longer than the limit; see WithMaxInputLength.
+func (o *options) checkLength(input string) InputError {
+	if o.maxInputLength > 0 && utf8.RuneCountInString(input) > o.maxInputLength {
+		return types.ErrorInputTooLong(input, o.maxInputLength)
+	}
+	return nil
+}
+
+// lexer returns a lexer of the input with the locale and variables, unless the context is done; along with
+// any other options, like tokenizer.WithRecovery.
+func (o *options) lexer(ctx context.Context, input string, opts ...tokenizer.Option) *tokenizer.Lexer {
+	opts = append([]tokenizer.Option{
+		tokenizer.WithLocale(o.locale),
+		tokenizer.WithContext(ctx),
+		tokenizer.WithVariables(o.variableNames()),
+	}, opts...)
+	return tokenizer.NewLexer(input, opts...)
+}
+
+// parserOptions returns the options of a parser with the locale, region, variables and limits, unless the
+// context is done.
+func (o *options) parserOptions(ctx context.Context) []parser.Option {
+	return []parser.Option{
+		parser.WithLocale(o.locale),
+		parser.WithRegion(o.region),
+		parser.WithVariables(o.vars),
+		parser.WithContext(ctx),
+		parser.WithMaxTokens(o.maxTokens),
+		parser.WithMaxDepth(o.maxDepth),
+		parser.WithMaxDigits(o.maxDigits),
+	}
+}
+
+// FindLocale returns a locale by name, like 'en' or 'de'.
+func FindLocale(name string) (Locale, error) {
+	return locale.Find(name)
+}
+
+// WithRegion sets the region whose units are preferred when units are ambiguous; like 'us' for a short ton.
+func WithRegion(region Region) Option {
+	return func(o *options) {
+		o.region = region
+	}
+}
+
+// FindRegion returns a region by name, like 'us', 'uk' or 'metric'.
+func FindRegion(name string) (Region, error) {
+	return registry.FindRegion(name)
+}
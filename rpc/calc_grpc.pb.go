@@ -0,0 +1,302 @@
+// The calculator as a service, for clients that would rather call a typed RPC than parse JSON.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: calc.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Calculator_Evaluate_FullMethodName       = "/quickcalc.v1.Calculator/Evaluate"
+	Calculator_BatchEvaluate_FullMethodName  = "/quickcalc.v1.Calculator/BatchEvaluate"
+	Calculator_Convert_FullMethodName        = "/quickcalc.v1.Calculator/Convert"
+	Calculator_ListUnits_FullMethodName      = "/quickcalc.v1.Calculator/ListUnits"
+	Calculator_EvaluateStream_FullMethodName = "/quickcalc.v1.Calculator/EvaluateStream"
+)
+
+// CalculatorClient is the client API for Calculator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CalculatorClient interface {
+	// Evaluates an expression; an expression that is not valid is described by the diagnostic of its result.
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*Result, error)
+	// Evaluates a batch of expressions in order, that share the variables they assign.
+	BatchEvaluate(ctx context.Context, in *BatchEvaluateRequest, opts ...grpc.CallOption) (*BatchEvaluateResponse, error)
+	// Converts a value from one unit to another.
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Result, error)
+	// Lists the known units.
+	ListUnits(ctx context.Context, in *ListUnitsRequest, opts ...grpc.CallOption) (*ListUnitsResponse, error)
+	// Evaluates each expression sent in a session, with the variables assigned earlier in the session.
+	EvaluateStream(ctx context.Context, opts ...grpc.CallOption) (Calculator_EvaluateStreamClient, error)
+}
+
+type calculatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCalculatorClient(cc grpc.ClientConnInterface) CalculatorClient {
+	return &calculatorClient{cc}
+}
+
+func (c *calculatorClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, Calculator_Evaluate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) BatchEvaluate(ctx context.Context, in *BatchEvaluateRequest, opts ...grpc.CallOption) (*BatchEvaluateResponse, error) {
+	out := new(BatchEvaluateResponse)
+	err := c.cc.Invoke(ctx, Calculator_BatchEvaluate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, Calculator_Convert_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) ListUnits(ctx context.Context, in *ListUnitsRequest, opts ...grpc.CallOption) (*ListUnitsResponse, error) {
+	out := new(ListUnitsResponse)
+	err := c.cc.Invoke(ctx, Calculator_ListUnits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorClient) EvaluateStream(ctx context.Context, opts ...grpc.CallOption) (Calculator_EvaluateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Calculator_ServiceDesc.Streams[0], Calculator_EvaluateStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &calculatorEvaluateStreamClient{stream}
+	return x, nil
+}
+
+type Calculator_EvaluateStreamClient interface {
+	Send(*EvaluateRequest) error
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type calculatorEvaluateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *calculatorEvaluateStreamClient) Send(m *EvaluateRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *calculatorEvaluateStreamClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CalculatorServer is the server API for Calculator service.
+// All implementations must embed UnimplementedCalculatorServer
+// for forward compatibility
+type CalculatorServer interface {
+	// Evaluates an expression; an expression that is not valid is described by the diagnostic of its result.
+	Evaluate(context.Context, *EvaluateRequest) (*Result, error)
+	// Evaluates a batch of expressions in order, that share the variables they assign.
+	BatchEvaluate(context.Context, *BatchEvaluateRequest) (*BatchEvaluateResponse, error)
+	// Converts a value from one unit to another.
+	Convert(context.Context, *ConvertRequest) (*Result, error)
+	// Lists the known units.
+	ListUnits(context.Context, *ListUnitsRequest) (*ListUnitsResponse, error)
+	// Evaluates each expression sent in a session, with the variables assigned earlier in the session.
+	EvaluateStream(Calculator_EvaluateStreamServer) error
+	mustEmbedUnimplementedCalculatorServer()
+}
+
+// UnimplementedCalculatorServer must be embedded to have forward compatible implementations.
+type UnimplementedCalculatorServer struct {
+}
+
+func (UnimplementedCalculatorServer) Evaluate(context.Context, *EvaluateRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (UnimplementedCalculatorServer) BatchEvaluate(context.Context, *BatchEvaluateRequest) (*BatchEvaluateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchEvaluate not implemented")
+}
+func (UnimplementedCalculatorServer) Convert(context.Context, *ConvertRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedCalculatorServer) ListUnits(context.Context, *ListUnitsRequest) (*ListUnitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUnits not implemented")
+}
+func (UnimplementedCalculatorServer) EvaluateStream(Calculator_EvaluateStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method EvaluateStream not implemented")
+}
+func (UnimplementedCalculatorServer) mustEmbedUnimplementedCalculatorServer() {}
+
+// UnsafeCalculatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CalculatorServer will
+// result in compilation errors.
+type UnsafeCalculatorServer interface {
+	mustEmbedUnimplementedCalculatorServer()
+}
+
+func RegisterCalculatorServer(s grpc.ServiceRegistrar, srv CalculatorServer) {
+	s.RegisterService(&Calculator_ServiceDesc, srv)
+}
+
+func _Calculator_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Calculator_Evaluate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_BatchEvaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).BatchEvaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Calculator_BatchEvaluate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).BatchEvaluate(ctx, req.(*BatchEvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Calculator_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_ListUnits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUnitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServer).ListUnits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Calculator_ListUnits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServer).ListUnits(ctx, req.(*ListUnitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calculator_EvaluateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServer).EvaluateStream(&calculatorEvaluateStreamServer{stream})
+}
+
+type Calculator_EvaluateStreamServer interface {
+	Send(*Result) error
+	Recv() (*EvaluateRequest, error)
+	grpc.ServerStream
+}
+
+type calculatorEvaluateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *calculatorEvaluateStreamServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *calculatorEvaluateStreamServer) Recv() (*EvaluateRequest, error) {
+	m := new(EvaluateRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Calculator_ServiceDesc is the grpc.ServiceDesc for Calculator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Calculator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quickcalc.v1.Calculator",
+	HandlerType: (*CalculatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Evaluate",
+			Handler:    _Calculator_Evaluate_Handler,
+		},
+		{
+			MethodName: "BatchEvaluate",
+			Handler:    _Calculator_BatchEvaluate_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _Calculator_Convert_Handler,
+		},
+		{
+			MethodName: "ListUnits",
+			Handler:    _Calculator_ListUnits_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "EvaluateStream",
+			Handler:       _Calculator_EvaluateStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "calc.proto",
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// evaluator returns an evaluator with the settings of a request, or the defaults of the server; settings that are
+// not valid fail with InvalidArgument.
+func (s *server) evaluator(settings *Settings) (*service.Evaluator, error) {
+	var precision *int
+	if settings != nil && settings.Precision != nil {
+		digits := int(settings.GetPrecision())
+		precision = &digits
+	}
+	defaults := service.Defaults{Options: s.opts, Locale: s.locale, Precision: s.precision}
+	e, err := service.New(defaults, service.Settings{Locale: settings.GetLocale(), Precision: precision, Region: settings.GetRegion()})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return e, nil
+}
+
+// newResult returns the message that describes the result of an expression, or what is wrong with it.
+func newResult(r service.Result) *Result {
+	if r.Diagnostic != nil {
+		return &Result{Input: r.Input, Diagnostic: newDiagnostic(*r.Diagnostic)}
+	}
+	result := &Result{
+		Input:    r.Input,
+		Name:     r.Name,
+		Result:   r.Result,
+		Value:    r.Amount.Value,
+		Upper:    r.Amount.Upper,
+		Interval: r.Amount.Interval,
+		Units:    r.Amount.Units.Value,
+	}
+	if r.Unit != nil {
+		result.Unit, result.Symbol, result.Quantity = r.Unit.Name, r.Unit.Symbol, r.Unit.Quantity
+	}
+	return result
+}
+
+// newDiagnostic returns the message that describes a diagnostic.
+func newDiagnostic(d diagnostic.Diagnostic) *Diagnostic {
+	severity := Severity_SEVERITY_ERROR
+	if d.Severity == diagnostic.Warning {
+		severity = Severity_SEVERITY_WARNING
+	}
+	return &Diagnostic{
+		Code:        string(d.Code),
+		Name:        d.Name,
+		Severity:    severity,
+		Message:     d.Message,
+		Start:       int32(d.Start),
+		Width:       int32(d.Width),
+		Expected:    d.Expected,
+		Suggestions: d.Suggestions,
+	}
+}
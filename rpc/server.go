@@ -0,0 +1,162 @@
+// Package rpc serves calculations over gRPC, for clients that would rather call a typed RPC than parse JSON. The
+// service is described by calc.proto, from which the Go client and server stubs are generated with 'make proto'.
+//
+//	s := grpc.NewServer()
+//	rpc.RegisterCalculatorServer(s, rpc.NewServer())
+//
+// An expression that is not valid is described by the diagnostic of its result, with its code and position; see the
+// diagnostic package. A request that is not valid, like a batch that is too large, fails with a gRPC status.
+package rpc
+
+import (
+	"context"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+)
+
+// DefaultMaxBatch The default limit on the number of expressions in a batch; it can be disabled with a limit of 0.
+const DefaultMaxBatch = 100
+
+// server Serves calculations over gRPC.
+type server struct {
+	UnimplementedCalculatorServer
+	opts      []calc.Option // the options used to calculate every expression
+	locale    locale.Locale // governs how numbers are read and written, unless a request sets it
+	precision int           // the number of decimal places in a result, unless a request sets it
+	maxBatch  int           // the maximum number of expressions in a batch; 0 if unlimited
+}
+
+// Option configures the server.
+type Option func(*server)
+
+// WithOptions sets the options used to calculate every expression; like the limits of calc.WithMaxTokens,
+// which override those of calc.WithDefaultLimits.
+func WithOptions(opts ...calc.Option) Option {
+	return func(s *server) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithLocale sets the locale that governs how numbers are read and written, unless a request sets it.
+func WithLocale(loc calc.Locale) Option {
+	return func(s *server) {
+		s.locale = loc
+	}
+}
+
+// WithPrecision sets the number of decimal places in a result, unless a request sets it.
+func WithPrecision(digits int) Option {
+	return func(s *server) {
+		s.precision = digits
+	}
+}
+
+// WithMaxBatch limits the number of expressions in a batch; 0 if unlimited.
+func WithMaxBatch(limit int) Option {
+	return func(s *server) {
+		s.maxBatch = limit
+	}
+}
+
+// NewServer returns a server that calculates; register it with RegisterCalculatorServer.
+func NewServer(opts ...Option) CalculatorServer {
+	s := &server{
+		opts:      []calc.Option{calc.WithDefaultLimits()},
+		locale:    locale.Default,
+		precision: calc.DefaultPrecision,
+		maxBatch:  DefaultMaxBatch,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Evaluate evaluates an expression.
+func (s *server) Evaluate(ctx context.Context, req *EvaluateRequest) (*Result, error) {
+	if strings.TrimSpace(req.GetExpression()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "expected an expression")
+	}
+	e, err := s.evaluator(req.GetSettings())
+	if err != nil {
+		return nil, err
+	}
+	return newResult(e.Evaluate(ctx, req.GetExpression())), nil
+}
+
+// BatchEvaluate evaluates a batch of expressions in order, that share the variables they assign.
+func (s *server) BatchEvaluate(ctx context.Context, req *BatchEvaluateRequest) (*BatchEvaluateResponse, error) {
+	if s.maxBatch > 0 && len(req.GetExpressions()) > s.maxBatch {
+		return nil, status.Errorf(codes.ResourceExhausted, "the batch has more than %d expressions", s.maxBatch)
+	}
+	e, err := s.evaluator(req.GetSettings())
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(req.GetExpressions()))
+	for _, expr := range req.GetExpressions() {
+		results = append(results, newResult(e.Evaluate(ctx, expr)))
+	}
+	return &BatchEvaluateResponse{Results: results}, nil
+}
+
+// EvaluateStream evaluates each expression sent in a session, with the variables assigned earlier in the session.
+func (s *server) EvaluateStream(stream Calculator_EvaluateStreamServer) error {
+	var e *service.Evaluator
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			// the settings of the first request are used for the whole session
+			if e, err = s.evaluator(req.GetSettings()); err != nil {
+				return err
+			}
+		}
+		if err := stream.Send(newResult(e.Evaluate(stream.Context(), req.GetExpression()))); err != nil {
+			return err
+		}
+	}
+}
+
+// Convert converts a value from one unit to another.
+func (s *server) Convert(ctx context.Context, req *ConvertRequest) (*Result, error) {
+	from, to := strings.TrimSpace(req.GetFrom()), strings.TrimSpace(req.GetTo())
+	switch {
+	case from == "":
+		return nil, status.Error(codes.InvalidArgument, "expected the units to convert from")
+	case to == "":
+		return nil, status.Error(codes.InvalidArgument, "expected the units to convert to")
+	}
+	e, err := s.evaluator(req.GetSettings())
+	if err != nil {
+		return nil, err
+	}
+	// the value is read by the calculator in the locale of the request
+	return newResult(e.Convert(ctx, req.GetValue(), from, to)), nil
+}
+
+// ListUnits lists the known units; those of a quantity, or whose names contain a search term.
+func (s *server) ListUnits(_ context.Context, req *ListUnitsRequest) (*ListUnitsResponse, error) {
+	units := []*Unit{}
+	for _, unit := range service.Units(req.GetQuantity(), req.GetSearch()) {
+		units = append(units, &Unit{
+			Name:     unit.Name,
+			Symbol:   unit.Symbol,
+			Plural:   unit.Plural,
+			Quantity: unit.Quantity,
+			System:   unit.System,
+			Aliases:  unit.Aliases,
+		})
+	}
+	return &ListUnitsResponse{Units: units}, nil
+}
@@ -0,0 +1,219 @@
+package rpc_test
+
+import (
+	"context"
+	"github.com/nickwallen/quick-calc/rpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net"
+	"testing"
+)
+
+// starts a server on an in-process listener and returns a client connected to it
+func connect(t *testing.T, opts ...rpc.Option) rpc.CalculatorClient {
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	rpc.RegisterCalculatorServer(s, rpc.NewServer(opts...))
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	dial := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return rpc.NewCalculatorClient(conn)
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := map[string]struct {
+		req      *rpc.EvaluateRequest
+		expected *rpc.Result
+	}{
+		"value": {
+			&rpc.EvaluateRequest{Expression: "2 kg + 3 lbs in pounds"},
+			&rpc.Result{Input: "2 kg + 3 lbs in pounds", Result: "7.41 pounds", Value: 7.409245243697551, Units: "pounds", Unit: "pound", Symbol: "lb", Quantity: "mass"},
+		},
+		"interval": {
+			&rpc.EvaluateRequest{Expression: "3..5 kg", Settings: &rpc.Settings{Precision: proto.Int32(0)}},
+			&rpc.Result{Input: "3..5 kg", Result: "3..5 kg", Value: 3, Upper: 5, Interval: true, Units: "kg", Unit: "kilogram", Symbol: "kg", Quantity: "mass"},
+		},
+		"locale": {
+			&rpc.EvaluateRequest{Expression: "2,5 kg + 3 Pfund", Settings: &rpc.Settings{Locale: "de"}},
+			&rpc.Result{Input: "2,5 kg + 3 Pfund", Result: "3,86 kg", Value: 3.86077711, Units: "kg", Unit: "kilogram", Symbol: "kg", Quantity: "mass"},
+		},
+		"invalid": {
+			&rpc.EvaluateRequest{Expression: "2 kg + 3 punds"},
+			&rpc.Result{Input: "2 kg + 3 punds", Diagnostic: &rpc.Diagnostic{
+				Code: "QC1001", Name: "unknown-unit", Message: "'punds' is not a known measurement unit", Start: 10, Width: 5, Suggestions: []string{"pounds", "pints"},
+			}},
+		},
+		"translated": {
+			&rpc.EvaluateRequest{Expression: "2 metros + 3 kg", Settings: &rpc.Settings{Locale: "es"}},
+			&rpc.Result{Input: "2 metros + 3 kg", Diagnostic: &rpc.Diagnostic{
+				Code: "QC1003", Name: "incompatible-units", Message: "no se puede convertir de kg a metros", Start: 14, Width: 2,
+			}},
+		},
+	}
+	client := connect(t)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := client.Evaluate(context.Background(), test.req)
+			assert.Nil(t, err)
+			assert.True(t, proto.Equal(test.expected, actual), "expected %v, but got %v", test.expected, actual)
+		})
+	}
+}
+
+func TestEvaluateInvalidRequest(t *testing.T) {
+	tests := map[string]*rpc.EvaluateRequest{
+		"empty":     {},
+		"locale":    {Expression: "2 kg", Settings: &rpc.Settings{Locale: "xx"}},
+		"region":    {Expression: "2 kg", Settings: &rpc.Settings{Region: "mars"}},
+		"precision": {Expression: "2 kg", Settings: &rpc.Settings{Precision: proto.Int32(1000)}},
+		"negative":  {Expression: "2 kg", Settings: &rpc.Settings{Precision: proto.Int32(-1)}},
+	}
+	client := connect(t)
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.Evaluate(context.Background(), req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestBatchEvaluate(t *testing.T) {
+	client := connect(t)
+	req := &rpc.BatchEvaluateRequest{Expressions: []string{"rent = 1200 USD", "per person = rent / 3", "rent + 3 kg"}}
+	actual, err := client.BatchEvaluate(context.Background(), req)
+	assert.Nil(t, err)
+	if assert.Len(t, actual.Results, 3) {
+		assert.Equal(t, "rent", actual.Results[0].Name)
+		assert.Equal(t, "400.00 USD", actual.Results[1].Result)
+		assert.Equal(t, "currency", actual.Results[1].Quantity)
+		assert.Equal(t, "cannot convert from kg to USD", actual.Results[2].Diagnostic.GetMessage())
+	}
+
+	// each batch has its own variables
+	actual, err = client.BatchEvaluate(context.Background(), &rpc.BatchEvaluateRequest{Expressions: []string{"rent"}})
+	assert.Nil(t, err)
+	assert.NotNil(t, actual.Results[0].Diagnostic)
+}
+
+func TestBatchEvaluateTooLarge(t *testing.T) {
+	client := connect(t, rpc.WithMaxBatch(2))
+	_, err := client.BatchEvaluate(context.Background(), &rpc.BatchEvaluateRequest{Expressions: []string{"1 kg", "2 kg", "3 kg"}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestEvaluateStream(t *testing.T) {
+	client := connect(t, rpc.WithPrecision(1))
+	stream, err := client.EvaluateStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"width = 3 m", "3.0 m"},
+		{"height = 200 cm", "200.0 cm"},
+		{"width + height in cm", "500.0 cm"},
+		{"width + depth", ""},
+	}
+	for _, test := range tests {
+		assert.Nil(t, stream.Send(&rpc.EvaluateRequest{Expression: test.expr}))
+		actual, err := stream.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, actual.Result)
+	}
+	assert.Nil(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestEvaluateStreamInvalidSettings(t *testing.T) {
+	client := connect(t)
+	stream, err := client.EvaluateStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, stream.Send(&rpc.EvaluateRequest{Expression: "2 kg", Settings: &rpc.Settings{Locale: "xx"}}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestConvert(t *testing.T) {
+	tests := map[string]struct {
+		req      *rpc.ConvertRequest
+		expected *rpc.Result
+	}{
+		"value": {
+			&rpc.ConvertRequest{Value: 2, From: "kg", To: "lb"},
+			&rpc.Result{Input: "2 kg in lb", Result: "4.41 lb", Value: 4.409245243697551, Units: "lb", Unit: "pound", Symbol: "lb", Quantity: "mass"},
+		},
+		"locale": {
+			&rpc.ConvertRequest{Value: 2.5, From: "kg", To: "g", Settings: &rpc.Settings{Locale: "de"}},
+			&rpc.Result{Input: "2,5 kg in g", Result: "2.500,00 g", Value: 2500, Units: "g", Unit: "gram", Symbol: "g", Quantity: "mass"},
+		},
+		"unknown": {
+			&rpc.ConvertRequest{Value: 2, From: "kg", To: "punds"},
+			&rpc.Result{Input: "2 kg in punds", Diagnostic: &rpc.Diagnostic{
+				Code: "QC1001", Name: "unknown-unit", Message: "'punds' is not a known measurement unit", Start: 9, Width: 5, Suggestions: []string{"pounds", "pints"},
+			}},
+		},
+		"expression": {
+			&rpc.ConvertRequest{Value: 2, From: "kg + 3 kg", To: "lb"},
+			&rpc.Result{Input: "2 kg + 3 kg in lb", Diagnostic: &rpc.Diagnostic{
+				Code: "QC1001", Name: "unknown-unit", Message: "'kg + 3 kg' is not a known measurement unit", Start: 3, Width: 9,
+			}},
+		},
+		"incompatible": {
+			&rpc.ConvertRequest{Value: 2, From: "kg", To: "miles"},
+			&rpc.Result{Input: "2 kg in miles", Diagnostic: &rpc.Diagnostic{
+				Code: "QC1003", Name: "incompatible-units", Message: "cannot convert from kg to miles", Start: 3, Width: 2,
+			}},
+		},
+	}
+	client := connect(t)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := client.Convert(context.Background(), test.req)
+			assert.Nil(t, err)
+			assert.True(t, proto.Equal(test.expected, actual), "expected %v, but got %v", test.expected, actual)
+		})
+	}
+}
+
+func TestConvertInvalidRequest(t *testing.T) {
+	client := connect(t)
+	for _, req := range []*rpc.ConvertRequest{{Value: 2, To: "lb"}, {Value: 2, From: "kg"}} {
+		_, err := client.Convert(context.Background(), req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestListUnits(t *testing.T) {
+	client := connect(t)
+	actual, err := client.ListUnits(context.Background(), &rpc.ListUnitsRequest{Quantity: "mass", Search: "pound"})
+	assert.Nil(t, err)
+	if assert.NotEmpty(t, actual.Units) {
+		assert.Equal(t, "pound", actual.Units[0].Name)
+		for _, unit := range actual.Units {
+			assert.Equal(t, "mass", unit.Quantity)
+		}
+	}
+
+	actual, err = client.ListUnits(context.Background(), &rpc.ListUnitsRequest{Search: "googles"})
+	assert.Nil(t, err)
+	assert.Empty(t, actual.Units)
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"github.com/nickwallen/quick-calc/internal/service"
+)
+
+// evaluator returns an evaluator with the settings of a request, or the defaults of the server.
+func (s *server) evaluator(req EvalRequest) (*service.Evaluator, error) {
+	return service.New(s.defaults(), service.Settings{Locale: req.Locale, Precision: req.Precision, Region: req.Region})
+}
+
+// defaults returns the settings of the server, used unless a request sets them.
+func (s *server) defaults() service.Defaults {
+	return service.Defaults{Options: s.opts, Locale: s.locale, Precision: s.precision}
+}
+
+// newResult returns the result of an expression, or what is wrong with it, as it is written in a response.
+func newResult(r service.Result) Result {
+	if r.Diagnostic != nil {
+		return Result{Input: r.Input, Error: r.Diagnostic}
+	}
+	amt := r.Amount
+	result := Result{
+		Input:    r.Input,
+		Name:     r.Name,
+		Result:   r.Result,
+		Value:    &amt.Value,
+		Upper:    amt.Upper,
+		Interval: amt.Interval,
+		Units:    amt.Units.Value,
+	}
+	if r.Unit != nil {
+		result.Unit, result.Symbol, result.Quantity = r.Unit.Name, r.Unit.Symbol, r.Unit.Quantity
+	}
+	return result
+}
@@ -0,0 +1,262 @@
+// Package server serves calculations over HTTP as JSON, so that other tools can use the calculator as a service.
+//
+//	POST /v1/eval     evaluates an expression, or a batch of expressions that share variables
+//	GET  /v1/units    lists the known units and the quantity, or dimension, that each measures
+//	GET  /v1/convert  converts a value from one unit to another; like ?value=2&from=kg&to=lb
+//
+// An expression that is not valid is described by its diagnostic, with its code and position; see the diagnostic
+// package. A request that is not valid, like one that is too large, is described by an error with a name.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/diagnostic"
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/service"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// the default limits that guard against expensive requests; each can be disabled with a limit of 0
+const (
+	DefaultMaxBodySize = 1 << 20
+	DefaultMaxBatch    = 100
+)
+
+// server Serves calculations over HTTP.
+type server struct {
+	opts        []calc.Option // the options used to calculate every expression
+	locale      locale.Locale // governs how numbers are read and written, unless a request sets it
+	precision   int           // the number of decimal places in a result, unless a request sets it
+	maxBodySize int64         // the maximum number of bytes in the body of a request; 0 if unlimited
+	maxBatch    int           // the maximum number of expressions in a batch; 0 if unlimited
+	mux         *http.ServeMux
+}
+
+// Option configures the server.
+type Option func(*server)
+
+// WithOptions sets the options used to calculate every expression; like the limits of calc.WithMaxTokens,
+// which override those of calc.WithDefaultLimits.
+func WithOptions(opts ...calc.Option) Option {
+	return func(s *server) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithLocale sets the locale that governs how numbers are read and written, unless a request sets it.
+func WithLocale(loc calc.Locale) Option {
+	return func(s *server) {
+		s.locale = loc
+	}
+}
+
+// WithPrecision sets the number of decimal places in a result, unless a request sets it.
+func WithPrecision(digits int) Option {
+	return func(s *server) {
+		s.precision = digits
+	}
+}
+
+// WithMaxBodySize limits the number of bytes in the body of a request; 0 if unlimited.
+func WithMaxBodySize(limit int64) Option {
+	return func(s *server) {
+		s.maxBodySize = limit
+	}
+}
+
+// WithMaxBatch limits the number of expressions in a batch; 0 if unlimited.
+func WithMaxBatch(limit int) Option {
+	return func(s *server) {
+		s.maxBatch = limit
+	}
+}
+
+// New returns a handler that serves calculations.
+func New(opts ...Option) http.Handler {
+	s := &server{
+		opts:        []calc.Option{calc.WithDefaultLimits()},
+		locale:      locale.Default,
+		precision:   calc.DefaultPrecision,
+		maxBodySize: DefaultMaxBodySize,
+		maxBatch:    DefaultMaxBatch,
+		mux:         http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("/v1/eval", s.only(http.MethodPost, s.eval))
+	s.mux.HandleFunc("/v1/units", s.only(http.MethodGet, s.units))
+	s.mux.HandleFunc("/v1/convert", s.only(http.MethodGet, s.convert))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not-found", fmt.Sprintf("'%s' is not found", r.URL.Path))
+	})
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// only returns a handler that rejects requests that do not use a method.
+func (s *server) only(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, "method-not-allowed", fmt.Sprintf("expected %s, but got %s", method, r.Method))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// EvalRequest A request to evaluate an expression or, in order, each expression of a batch.
+type EvalRequest struct {
+	Expression  string   `json:"expression,omitempty"`  // an expression; like '2 kg in lb'
+	Expressions []string `json:"expressions,omitempty"` // a batch of expressions, that share the variables they assign
+	Precision   *int     `json:"precision,omitempty"`   // the number of decimal places in each result
+	Locale      string   `json:"locale,omitempty"`      // the locale of numbers and messages; like 'de'
+	Region      string   `json:"region,omitempty"`      // the region preferred for ambiguous units; like 'uk'
+}
+
+// Result The result of an expression, or what is wrong with it.
+type Result struct {
+	Input    string                 `json:"input"`              // the expression
+	Name     string                 `json:"name,omitempty"`     // the variable that is assigned the result, if any
+	Result   string                 `json:"result,omitempty"`   // the formatted result; like '2.00 kg'
+	Value    *float64               `json:"value,omitempty"`    // the value or, for an interval, the lower bound; nil if not valid
+	Upper    float64                `json:"upper,omitempty"`    // the upper bound of an interval
+	Interval bool                   `json:"interval,omitempty"` // true if the result is an interval
+	Units    string                 `json:"units,omitempty"`    // the units of the result as written; like 'kg'
+	Unit     string                 `json:"unit,omitempty"`     // the canonical name of the units; like 'kilogram'
+	Symbol   string                 `json:"symbol,omitempty"`   // the symbol of the units; like 'kg'
+	Quantity string                 `json:"quantity,omitempty"` // the quantity, or dimension, of the units; like 'mass'
+	Error    *diagnostic.Diagnostic `json:"error,omitempty"`    // what is wrong with the expression, if anything
+}
+
+// BatchResponse The results of a batch of expressions, in order.
+type BatchResponse struct {
+	Results []Result `json:"results"`
+}
+
+// UnitsResponse The units that are known.
+type UnitsResponse struct {
+	Units []calc.Unit `json:"units"`
+}
+
+// ErrorResponse Describes a request that is not valid.
+type ErrorResponse struct {
+	Error RequestError `json:"error"`
+}
+
+// RequestError What is wrong with a request; like a body that is too large.
+type RequestError struct {
+	Name    string `json:"name"`    // a readable name for the problem like 'body-too-large'
+	Message string `json:"message"` // describes the problem
+}
+
+// eval evaluates an expression or a batch of expressions.
+func (s *server) eval(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(s.limit(r.Body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unreadable-body", err.Error())
+		return
+	}
+	if s.maxBodySize > 0 && int64(len(body)) > s.maxBodySize {
+		writeError(w, http.StatusRequestEntityTooLarge, "body-too-large", fmt.Sprintf("the body has more than %d bytes", s.maxBodySize))
+		return
+	}
+	var req EvalRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-json", err.Error())
+		return
+	}
+	batch := req.Expressions != nil
+	switch {
+	case batch && req.Expression != "":
+		writeError(w, http.StatusBadRequest, "invalid-request", "expected either an expression or expressions, but got both")
+		return
+	case !batch && req.Expression == "":
+		writeError(w, http.StatusBadRequest, "invalid-request", "expected an expression or expressions")
+		return
+	case s.maxBatch > 0 && len(req.Expressions) > s.maxBatch:
+		writeError(w, http.StatusRequestEntityTooLarge, "batch-too-large", fmt.Sprintf("the batch has more than %d expressions", s.maxBatch))
+		return
+	}
+	e, err := s.evaluator(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-request", err.Error())
+		return
+	}
+	if !batch {
+		result := newResult(e.Evaluate(r.Context(), req.Expression))
+		status := http.StatusOK
+		if result.Error != nil {
+			status = http.StatusUnprocessableEntity
+		}
+		writeJSON(w, status, result)
+		return
+	}
+	results := make([]Result, 0, len(req.Expressions))
+	for _, expr := range req.Expressions {
+		results = append(results, newResult(e.Evaluate(r.Context(), expr)))
+	}
+	writeJSON(w, http.StatusOK, BatchResponse{Results: results})
+}
+
+// limit limits the bytes read from the body of a request to one more than the maximum, so that a body that is too
+// large can be told apart.
+func (s *server) limit(body io.Reader) io.Reader {
+	if s.maxBodySize <= 0 {
+		return body
+	}
+	return io.LimitReader(body, s.maxBodySize+1)
+}
+
+// units lists the known units; those of a quantity like ?quantity=mass, or whose names contain ?search=.
+func (s *server) units(w http.ResponseWriter, r *http.Request) {
+	units := service.Units(r.URL.Query().Get("quantity"), r.URL.Query().Get("search"))
+	writeJSON(w, http.StatusOK, UnitsResponse{Units: units})
+}
+
+// convert converts a value from one unit to another; like ?value=2&from=kg&to=lb.
+func (s *server) convert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for _, param := range []string{"value", "from", "to"} {
+		if strings.TrimSpace(query.Get(param)) == "" {
+			writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("expected the parameter '%s'", param))
+			return
+		}
+	}
+	from, to := strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to"))
+	number, err := strconv.ParseFloat(strings.TrimSpace(query.Get("value")), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-request", fmt.Sprintf("'%s' is not a number", query.Get("value")))
+		return
+	}
+	// a conversion has the settings of the server, which need no checks
+	e, _ := service.New(s.defaults(), service.Settings{})
+	result := newResult(e.Convert(r.Context(), number, from, to))
+	status := http.StatusOK
+	if result.Error != nil {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, result)
+}
+
+// writeJSON writes a response as JSON.
+func writeJSON(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes a response that describes a request that is not valid.
+func writeError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, ErrorResponse{Error: RequestError{Name: name, Message: message}})
+}
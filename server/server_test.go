@@ -0,0 +1,217 @@
+package server_test
+
+import (
+	"encoding/json"
+	calc "github.com/nickwallen/quick-calc"
+	"github.com/nickwallen/quick-calc/server"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sends a request to a server and returns the status and body of the response
+func send(handler http.Handler, method, target, body string) (int, string) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestEval(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		status   int
+		expected string
+	}{
+		"value": {
+			`{"expression":"2 kg + 3 lbs in pounds"}`,
+			http.StatusOK,
+			`{"input":"2 kg + 3 lbs in pounds","result":"7.41 pounds","value":7.409245243697551,"units":"pounds","unit":"pound","symbol":"lb","quantity":"mass"}`,
+		},
+		"zero": {
+			`{"expression":"2 feet - 2 feet"}`,
+			http.StatusOK,
+			`{"input":"2 feet - 2 feet","result":"0.00 feet","value":0,"units":"feet","unit":"foot","symbol":"ft","quantity":"length"}`,
+		},
+		"interval": {
+			`{"expression":"3..5 kg","precision":0}`,
+			http.StatusOK,
+			`{"input":"3..5 kg","result":"3..5 kg","value":3,"upper":5,"interval":true,"units":"kg","unit":"kilogram","symbol":"kg","quantity":"mass"}`,
+		},
+		"locale": {
+			`{"expression":"2,5 kg + 3 Pfund","locale":"de"}`,
+			http.StatusOK,
+			`{"input":"2,5 kg + 3 Pfund","result":"3,86 kg","value":3.86077711,"units":"kg","unit":"kilogram","symbol":"kg","quantity":"mass"}`,
+		},
+		"invalid": {
+			`{"expression":"2 kg + 3 punds"}`,
+			http.StatusUnprocessableEntity,
+			`{"input":"2 kg + 3 punds","error":{"code":"QC1001","name":"unknown-unit","severity":"error",` +
+				`"message":"'punds' is not a known measurement unit","start":10,"width":5,"suggestions":["pounds","pints"]}}`,
+		},
+		"translated": {
+			`{"expression":"2 metros + 3 kg","locale":"es"}`,
+			http.StatusUnprocessableEntity,
+			`{"input":"2 metros + 3 kg","error":{"code":"QC1003","name":"incompatible-units","severity":"error",` +
+				`"message":"no se puede convertir de kg a metros","start":14,"width":2}}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := send(server.New(), http.MethodPost, "/v1/eval", test.body)
+			assert.Equal(t, test.status, status)
+			assert.Equal(t, test.expected+"\n", body)
+		})
+	}
+}
+
+func TestEvalLimits(t *testing.T) {
+	// the default limits guard against expensive expressions, unless overridden
+	long := strings.Repeat("1 kg + ", 150) + "1 kg"
+	status, body := send(server.New(), http.MethodPost, "/v1/eval", `{"expression":"`+long+`"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, `"code":"QC4001"`)
+
+	status, body = send(server.New(server.WithOptions(calc.WithMaxInputLength(0), calc.WithMaxTokens(0), calc.WithMaxDepth(0))), http.MethodPost, "/v1/eval", `{"expression":"`+long+`"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"result":"151.00 kg"`)
+}
+
+func TestEvalBatch(t *testing.T) {
+	body := `{"expressions":["rent = 1200 USD","per person = rent / 3","rent + 3 kg"]}`
+	status, actual := send(server.New(), http.MethodPost, "/v1/eval", body)
+	assert.Equal(t, http.StatusOK, status)
+	var response server.BatchResponse
+	assert.Nil(t, json.Unmarshal([]byte(actual), &response))
+	if assert.Len(t, response.Results, 3) {
+		assert.Equal(t, "rent", response.Results[0].Name)
+		assert.Equal(t, "400.00 USD", response.Results[1].Result)
+		assert.Equal(t, "currency", response.Results[1].Quantity)
+		assert.Nil(t, response.Results[2].Value)
+		assert.Equal(t, "cannot convert from kg to USD", response.Results[2].Error.Message)
+	}
+}
+
+func TestEvalBadRequest(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		status   int
+		expected string
+	}{
+		"not json":   {`2 kg`, http.StatusBadRequest, "invalid-json"},
+		"empty":      {`{}`, http.StatusBadRequest, "invalid-request"},
+		"both":       {`{"expression":"2 kg","expressions":["3 kg"]}`, http.StatusBadRequest, "invalid-request"},
+		"locale":     {`{"expression":"2 kg","locale":"xx"}`, http.StatusBadRequest, "invalid-request"},
+		"region":     {`{"expression":"2 kg","region":"mars"}`, http.StatusBadRequest, "invalid-request"},
+		"precision":  {`{"expression":"2 kg","precision":1000}`, http.StatusBadRequest, "invalid-request"},
+		"negative":   {`{"expression":"2 kg","precision":-1}`, http.StatusBadRequest, "invalid-request"},
+		"body size":  {`{"expression":"` + strings.Repeat("1", 100) + ` kg"}`, http.StatusRequestEntityTooLarge, "body-too-large"},
+		"batch size": {`{"expressions":["1 kg","2 kg","3 kg"]}`, http.StatusRequestEntityTooLarge, "batch-too-large"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := server.New(server.WithMaxBodySize(64), server.WithMaxBatch(2))
+			status, body := send(handler, http.MethodPost, "/v1/eval", test.body)
+			assert.Equal(t, test.status, status)
+			var response server.ErrorResponse
+			assert.Nil(t, json.Unmarshal([]byte(body), &response))
+			assert.Equal(t, test.expected, response.Error.Name)
+			assert.NotEmpty(t, response.Error.Message)
+		})
+	}
+}
+
+func TestEvalMethod(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/eval", nil)
+	rec := httptest.NewRecorder()
+	server.New().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"error":{"name":"method-not-allowed","message":"expected POST, but got GET"}}`+"\n", rec.Body.String())
+}
+
+func TestNotFound(t *testing.T) {
+	status, body := send(server.New(), http.MethodGet, "/v2/eval", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, `{"error":{"name":"not-found","message":"'/v2/eval' is not found"}}`+"\n", body)
+}
+
+func TestUnits(t *testing.T) {
+	status, body := send(server.New(), http.MethodGet, "/v1/units?quantity=mass&search=pound", "")
+	assert.Equal(t, http.StatusOK, status)
+	var response server.UnitsResponse
+	assert.Nil(t, json.Unmarshal([]byte(body), &response))
+	if assert.NotEmpty(t, response.Units) {
+		assert.Equal(t, "pound", response.Units[0].Name)
+		for _, unit := range response.Units {
+			assert.Equal(t, "mass", unit.Quantity)
+		}
+	}
+
+	_, body = send(server.New(), http.MethodGet, "/v1/units?search=googles", "")
+	assert.Equal(t, `{"units":[]}`+"\n", body)
+}
+
+func TestConvert(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		status   int
+		expected string
+	}{
+		"value": {
+			"value=2&from=kg&to=lb",
+			http.StatusOK,
+			`{"input":"2 kg in lb","result":"4.41 lb","value":4.409245243697551,"units":"lb","unit":"pound","symbol":"lb","quantity":"mass"}`,
+		},
+		"names": {
+			"value=1.5e3&from=fluid+ounces&to=l",
+			http.StatusOK,
+			`{"input":"1500 fluid ounces in l","result":"42.62 l","value":42.61959375,"units":"l","unit":"liter","symbol":"l","quantity":"volume"}`,
+		},
+		"unknown": {
+			"value=2&from=kg&to=punds",
+			http.StatusUnprocessableEntity,
+			`{"input":"2 kg in punds","error":{"code":"QC1001","name":"unknown-unit","severity":"error",` +
+				`"message":"'punds' is not a known measurement unit","start":9,"width":5,"suggestions":["pounds","pints"]}}`,
+		},
+		"expression": {
+			"value=2&from=kg+%2B+3+kg&to=lb",
+			http.StatusUnprocessableEntity,
+			`{"input":"2 kg + 3 kg in lb","error":{"code":"QC1001","name":"unknown-unit","severity":"error",` +
+				`"message":"'kg + 3 kg' is not a known measurement unit","start":3,"width":9}}`,
+		},
+		"incompatible": {
+			"value=2&from=kg&to=miles",
+			http.StatusUnprocessableEntity,
+			`{"input":"2 kg in miles","error":{"code":"QC1003","name":"incompatible-units","severity":"error",` +
+				`"message":"cannot convert from kg to miles","start":3,"width":2}}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := send(server.New(), http.MethodGet, "/v1/convert?"+test.query, "")
+			assert.Equal(t, test.status, status)
+			assert.Equal(t, test.expected+"\n", body)
+		})
+	}
+}
+
+func TestConvertBadRequest(t *testing.T) {
+	tests := map[string]string{
+		"value=2&from=kg":        "expected the parameter 'to'",
+		"from=kg&to=lb":          "expected the parameter 'value'",
+		"value=two&from=kg&to=g": "'two' is not a number",
+	}
+	for query, expected := range tests {
+		t.Run(query, func(t *testing.T) {
+			status, body := send(server.New(), http.MethodGet, "/v1/convert?"+query, "")
+			assert.Equal(t, http.StatusBadRequest, status)
+			var response server.ErrorResponse
+			assert.Nil(t, json.Unmarshal([]byte(body), &response))
+			assert.Equal(t, expected, response.Error.Message)
+		})
+	}
+}
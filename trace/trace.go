@@ -0,0 +1,148 @@
+// Package trace describes the steps taken to evaluate an expression, like each conversion of units, so that
+// users can see why a calculation gives the result that it does.
+package trace
+
+import (
+	"context"
+	"fmt"
+)
+
+// Kind The kind of a step.
+type Kind int
+
+const (
+	// Units The units of an operation are chosen; those of the left operand.
+	Units Kind = iota
+	// Conversion A quantity is converted to other units.
+	Conversion
+	// Addition Two quantities are added.
+	Addition
+	// Subtraction One quantity is subtracted from another.
+	Subtraction
+	// Radix A quantity is displayed in a radix like hexadecimal.
+	Radix
+	// Multiplication A quantity is multiplied by a number, or by another quantity.
+	Multiplication
+	// Division A quantity is divided by a number, or by another quantity.
+	Division
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Units:
+		return "units"
+	case Conversion:
+		return "conversion"
+	case Addition:
+		return "addition"
+	case Subtraction:
+		return "subtraction"
+	case Radix:
+		return "radix"
+	case Multiplication:
+		return "multiplication"
+	case Division:
+		return "division"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the kind as text; like 'conversion'.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Quantity An amount of some units; like '2 stones' or '3..5 kg'.
+type Quantity struct {
+	Value    float64 `json:"value"`           // the value or, for an interval, the lower bound
+	Upper    float64 `json:"upper,omitempty"` // the upper bound of an interval
+	Interval bool    `json:"interval"`        // true if the quantity is an interval
+	Units    string  `json:"units"`           // the units of measure
+}
+
+func (q Quantity) String() string {
+	value := fmt.Sprintf("%.2f", q.Value)
+	if q.Interval {
+		value = fmt.Sprintf("%.2f..%.2f", q.Value, q.Upper)
+	}
+	if q.Units == "" {
+		// like the ratio of two lengths
+		return value
+	}
+	return fmt.Sprintf("%s %s", value, q.Units)
+}
+
+// Step A step taken to evaluate an expression.
+type Step struct {
+	Kind     Kind       `json:"kind"`             // the kind of step
+	Operands []Quantity `json:"operands"`         // the quantities acted on
+	Result   Quantity   `json:"result"`           // the quantity that results
+	Factor   float64    `json:"factor,omitempty"` // the factor of a conversion, 0 if it is not linear; or the number of a multiplication or division
+	Radix    int        `json:"radix,omitempty"`  // the radix that a quantity is displayed in
+}
+
+func (s Step) String() string {
+	switch s.Kind {
+	case Units:
+		return fmt.Sprintf("%s is used; the units of the left operand %s", s.Result.Units, s.Operands[0])
+	case Conversion:
+		if s.Factor == 0 {
+			return fmt.Sprintf("%s → %s", s.Operands[0], s.Result)
+		}
+		return fmt.Sprintf("%s → %s (× %.6g)", s.Operands[0], s.Result, s.Factor)
+	case Addition:
+		return fmt.Sprintf("%s + %s = %s", s.Operands[0], s.Operands[1], s.Result)
+	case Subtraction:
+		return fmt.Sprintf("%s - %s = %s", s.Operands[0], s.Operands[1], s.Result)
+	case Radix:
+		return fmt.Sprintf("%s is displayed in base %d", s.Result, s.Radix)
+	case Multiplication:
+		if len(s.Operands) > 1 {
+			return fmt.Sprintf("%s × %s = %s", s.Operands[0], s.Operands[1], s.Result)
+		}
+		return fmt.Sprintf("%s × %g = %s", s.Operands[0], s.Factor, s.Result)
+	case Division:
+		if len(s.Operands) > 1 {
+			return fmt.Sprintf("%s ÷ %s = %s", s.Operands[0], s.Operands[1], s.Result)
+		}
+		return fmt.Sprintf("%s ÷ %g = %s", s.Operands[0], s.Factor, s.Result)
+	default:
+		return s.Kind.String()
+	}
+}
+
+// Recorder Records the steps taken to evaluate an expression.
+type Recorder struct {
+	steps []Step
+}
+
+// Record records a step. Nothing is recorded by a nil recorder.
+func (r *Recorder) Record(step Step) {
+	if r == nil {
+		return
+	}
+	r.steps = append(r.steps, step)
+}
+
+// Steps returns the steps recorded, in order.
+func (r *Recorder) Steps() []Step {
+	if r == nil {
+		return nil
+	}
+	return r.steps
+}
+
+// the key of the recorder in a context
+type recorderKey struct{}
+
+// NewContext returns a context in which each step of an evaluation is recorded.
+func NewContext(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+// FromContext returns the recorder of a context; nil if the steps of an evaluation are not recorded.
+func FromContext(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
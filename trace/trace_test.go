@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var steps = map[string]Step{
+	"kg is used; the units of the left operand 2.00 kg": {
+		Kind:     Units,
+		Operands: []Quantity{{Value: 2, Units: "kg"}},
+		Result:   Quantity{Value: 2, Units: "kg"},
+	},
+	"3.00 lbs → 1.36 kg (× 0.453592)": {
+		Kind:     Conversion,
+		Operands: []Quantity{{Value: 3, Units: "lbs"}},
+		Result:   Quantity{Value: 1.360777, Units: "kg"},
+		Factor:   0.45359237,
+	},
+	"10.00 C → 50.00 F": {
+		Kind:     Conversion,
+		Operands: []Quantity{{Value: 10, Units: "C"}},
+		Result:   Quantity{Value: 50, Units: "F"},
+	},
+	"3.00..5.00 kg + 1.00 kg = 4.00..6.00 kg": {
+		Kind:     Addition,
+		Operands: []Quantity{{Value: 3, Upper: 5, Interval: true, Units: "kg"}, {Value: 1, Units: "kg"}},
+		Result:   Quantity{Value: 4, Upper: 6, Interval: true, Units: "kg"},
+	},
+	"3.00 kg - 1.00 kg = 2.00 kg": {
+		Kind:     Subtraction,
+		Operands: []Quantity{{Value: 3, Units: "kg"}, {Value: 1, Units: "kg"}},
+		Result:   Quantity{Value: 2, Units: "kg"},
+	},
+	"2.00 kg × 3 = 6.00 kg": {
+		Kind:     Multiplication,
+		Operands: []Quantity{{Value: 2, Units: "kg"}},
+		Result:   Quantity{Value: 6, Units: "kg"},
+		Factor:   3,
+	},
+	"1200.00 USD ÷ 3 = 400.00 USD": {
+		Kind:     Division,
+		Operands: []Quantity{{Value: 1200, Units: "USD"}},
+		Result:   Quantity{Value: 400, Units: "USD"},
+		Factor:   3,
+	},
+	"2.00 m × 3.00..4.00 m = 6.00..8.00 m^2": {
+		Kind:     Multiplication,
+		Operands: []Quantity{{Value: 2, Units: "m"}, {Value: 3, Upper: 4, Interval: true, Units: "m"}},
+		Result:   Quantity{Value: 6, Upper: 8, Interval: true, Units: "m^2"},
+	},
+	"6.00 m ÷ 3.00 m = 2.00": {
+		Kind:     Division,
+		Operands: []Quantity{{Value: 6, Units: "m"}, {Value: 3, Units: "m"}},
+		Result:   Quantity{Value: 2},
+	},
+	"255.00 bytes is displayed in base 16": {
+		Kind:     Radix,
+		Operands: []Quantity{{Value: 255, Units: "bytes"}},
+		Result:   Quantity{Value: 255, Units: "bytes"},
+		Radix:    16,
+	},
+}
+
+func TestStepString(t *testing.T) {
+	for expected, step := range steps {
+		t.Run(expected, func(t *testing.T) {
+			assert.Equal(t, expected, step.String())
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := &Recorder{}
+	ctx := NewContext(context.Background(), recorder)
+	FromContext(ctx).Record(Step{Kind: Addition})
+	assert.Equal(t, []Step{{Kind: Addition}}, recorder.Steps())
+}
+
+func TestRecorderNil(t *testing.T) {
+	recorder := FromContext(context.Background())
+	assert.Nil(t, recorder)
+	recorder.Record(Step{Kind: Addition})
+	assert.Nil(t, recorder.Steps())
+}
+
+func TestStepJSON(t *testing.T) {
+	step := Step{Kind: Conversion, Operands: []Quantity{{Value: 3, Units: "lbs"}}, Result: Quantity{Value: 48, Units: "oz"}, Factor: 16}
+	encoded, err := json.Marshal(step)
+	assert.Nil(t, err)
+	expected := `{"kind":"conversion","operands":[{"value":3,"interval":false,"units":"lbs"}],` +
+		`"result":{"value":48,"interval":false,"units":"oz"},"factor":16}`
+	assert.JSONEq(t, expected, string(encoded))
+}
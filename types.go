@@ -0,0 +1,19 @@
+package calc
+
+import (
+	"github.com/nickwallen/quick-calc/internal/locale"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"github.com/nickwallen/quick-calc/internal/types"
+)
+
+// the types used by the calculator; they are defined in internal packages, so are named here for clients
+type (
+	// Amount The value of an expression and its units; an interval also has an upper bound.
+	Amount = types.Amount
+	// InputError A problem with the input, like units that are not known; see the diagnostic package.
+	InputError = types.InputError
+	// Locale Governs how numbers are read and written, and the language of units and messages; see FindLocale.
+	Locale = locale.Locale
+	// Region The units that are preferred when units are ambiguous like 'gal'; see FindRegion.
+	Region = registry.Region
+)
@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"bufio"
+	"fmt"
+	u "github.com/bcicen/go-units"
+	"github.com/nickwallen/quick-calc/internal/registry"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Unit A unit of measure that can be used in an expression.
+type Unit struct {
+	Name     string   `json:"name"`              // the canonical name; like 'kilogram'
+	Symbol   string   `json:"symbol,omitempty"`  // the symbol; like 'kg'
+	Plural   string   `json:"plural"`            // the plural name; like 'kilograms'
+	Quantity string   `json:"quantity"`          // the quantity, or dimension, measured; like 'mass'
+	System   string   `json:"system,omitempty"`  // the system of measurement; like 'metric'
+	Aliases  []string `json:"aliases,omitempty"` // any other names for the unit
+}
+
+// newUnit returns the public description of a unit.
+func newUnit(unit u.Unit) Unit {
+	result := Unit{
+		Name:     unit.Name,
+		Symbol:   unit.Symbol,
+		Plural:   unit.PluralName(),
+		Quantity: unit.Quantity,
+		System:   unit.System(),
+	}
+	for _, name := range unit.Names() {
+		if name != result.Name && name != result.Symbol && name != result.Plural {
+			result.Aliases = append(result.Aliases, name)
+		}
+	}
+	return result
+}
+
+// Units returns all of the known units ordered by quantity, then by name.
+func Units() []Unit {
+	var units []Unit
+	for _, unit := range registry.All() {
+		units = append(units, newUnit(unit))
+	}
+	return units
+}
+
+// FindUnit finds a unit by its name, symbol or an alias; like 'kg' or 'pounds'. A unit can also be
+// found by its name in the language of the locale of the options; like 'Pfund' in German.
+func FindUnit(name string, opts ...Option) (Unit, error) {
+	unit, err := registry.FindIn(name, newOptions(opts...).locale)
+	if err != nil {
+		return Unit{}, err
+	}
+	return newUnit(unit), nil
+}
+
+// DefineUnit defines a unit that is equal to an amount of a known unit; like a 'smoot' equal to 1.7018 meters.
+// Units should be defined before any expressions are calculated.
+func DefineUnit(name, symbol string, amount float64, base string) (Unit, error) {
+	unit, err := registry.Define(name, symbol, amount, base)
+	if err != nil {
+		return Unit{}, err
+	}
+	return newUnit(unit), nil
+}
+
+// LoadUnits defines each unit read from a unit-definition file. Each line defines one unit with an optional
+// symbol, like 'smoot (smt) = 1.7018 m'. Blank lines and comments that start with '#' are ignored.
+func LoadUnits(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if err := loadUnit(text); err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// loadUnit defines the unit of a single line of a unit-definition file; like 'smoot (smt) = 1.7018 m'.
+func loadUnit(line string) error {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected a definition like 'smoot (smt) = 1.7018 m'")
+	}
+	name, symbol := strings.TrimSpace(parts[0]), ""
+	if open := strings.Index(name, "("); open >= 0 && strings.HasSuffix(name, ")") {
+		symbol = strings.TrimSpace(name[open+1 : len(name)-1])
+		name = strings.TrimSpace(name[:open])
+	}
+	fields := strings.Fields(parts[1])
+	if len(fields) < 2 {
+		return fmt.Errorf("expected an amount and units like '1.7018 m'")
+	}
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a number", fields[0])
+	}
+	_, err = registry.Define(name, symbol, amount, strings.Join(fields[1:], " "))
+	return err
+}
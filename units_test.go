@@ -0,0 +1,70 @@
+package calc_test
+
+import (
+	"github.com/nickwallen/quick-calc"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	units := calc.Units()
+	assert.NotEmpty(t, units)
+	for i := 1; i < len(units); i++ {
+		assert.True(t, units[i-1].Quantity <= units[i].Quantity)
+	}
+}
+
+func TestFindUnit(t *testing.T) {
+	unit, err := calc.FindUnit("kg")
+	assert.Nil(t, err)
+	assert.Equal(t, "kilogram", unit.Name)
+	assert.Equal(t, "kg", unit.Symbol)
+	assert.Equal(t, "kilograms", unit.Plural)
+	assert.Equal(t, "mass", unit.Quantity)
+
+	_, err = calc.FindUnit("blarg")
+	assert.NotNil(t, err)
+}
+
+func TestDefineUnit(t *testing.T) {
+	unit, err := calc.DefineUnit("cubit", "cbt", 0.4572, "m")
+	assert.Nil(t, err)
+	assert.Equal(t, "length", unit.Quantity)
+
+	actual, err := calc.Calculate("10 cubits in m")
+	assert.Nil(t, err)
+	assert.Equal(t, "4.57 m", actual)
+
+	_, err = calc.DefineUnit("cubit", "", 1, "m")
+	assert.NotNil(t, err)
+}
+
+func TestLoadUnits(t *testing.T) {
+	definitions := `
+# units of length
+smoot (smt) = 1.7018 m
+stroll = 3 miles # on foot
+`
+	assert.Nil(t, calc.LoadUnits(strings.NewReader(definitions)))
+	actual, err := calc.Calculate("2 smt + 1 stroll in km")
+	assert.Nil(t, err)
+	assert.Equal(t, "4.83 km", actual)
+}
+
+func TestLoadUnitsErrors(t *testing.T) {
+	tests := map[string]string{
+		"span":               "line 1: expected a definition like 'smoot (smt) = 1.7018 m'",
+		"span = 9":           "line 1: expected an amount and units like '1.7018 m'",
+		"\n\nspan = nine in": "line 3: 'nine' is not a number",
+		"span = 9 blargs":    "line 1: 'blargs' is not a known unit",
+	}
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			err := calc.LoadUnits(strings.NewReader(input))
+			if assert.NotNil(t, err) {
+				assert.Equal(t, expected, err.Error())
+			}
+		})
+	}
+}